@@ -11,6 +11,7 @@ import (
 	"artion-api-graphql/internal/repository"
 	"artion-api-graphql/internal/svc"
 	"flag"
+	"github.com/ethereum/go-ethereum/common"
 	"log"
 	"net/http"
 	"os"
@@ -25,6 +26,11 @@ type apiServer struct {
 	log          logger.Logger
 	srv          *http.Server
 	isVersionReq bool
+
+	// holders snapshot export request
+	snapshotContract string
+	snapshotBlock    uint64
+	snapshotOut      string
 }
 
 // init initializes the API server
@@ -32,6 +38,11 @@ func (app *apiServer) init() {
 	// make sure to capture version request and rescan depth
 	flag.BoolVar(&app.isVersionReq, "v", false, "get the application version")
 
+	// holders snapshot export; the server is not started if requested
+	flag.StringVar(&app.snapshotContract, "snapshot", "", "export holders of the collection contract as CSV and exit")
+	flag.Uint64Var(&app.snapshotBlock, "block", 0, "block number of the holders snapshot")
+	flag.StringVar(&app.snapshotOut, "out", "", "path of the holders snapshot CSV file; standard output is used if empty")
+
 	// get the configuration including parsing the calling flags
 	var err error
 	app.cfg, err = config.Load()
@@ -64,6 +75,12 @@ func (app *apiServer) run() {
 		return
 	}
 
+	// export holders snapshot and exit if requested
+	if app.snapshotContract != "" {
+		app.exportHoldersSnapshot()
+		return
+	}
+
 	// start the services
 	svc.Mgr()
 
@@ -121,14 +138,66 @@ func (app *apiServer) setupHandlers(mux *http.ServeMux) {
 	mux.Handle("/graphi", handlers.GraphiHandler(app.log))
 
 	// handle images
-	mux.Handle("/images/token/", handlers.ImageHandler(app.log, handlers.TokenImageResolver))
-	mux.Handle("/images/avatar/", handlers.ImageHandler(app.log, handlers.UserAvatarResolver))
-	mux.Handle("/images/collection/", handlers.ImageHandler(app.log, handlers.CollectionImageResolver))
+	mux.Handle("/images/token/", handlers.ImageHandler(app.cfg, app.log, handlers.TokenImageResolver))
+	mux.Handle("/images/avatar/", handlers.ImageHandler(app.cfg, app.log, handlers.UserAvatarResolver))
+	mux.Handle("/images/collection/", handlers.ImageHandler(app.cfg, app.log, handlers.CollectionImageResolver))
+
+	// handle video previews
+	mux.Handle("/previews/token/", handlers.PreviewHandler(app.cfg, app.log, handlers.TokenPreviewResolver))
+
+	// handle original media
+	mux.Handle("/media/token/", handlers.MediaHandler(app.cfg, app.log, handlers.TokenImageResolver))
+	mux.Handle("/media/avatar/", handlers.MediaHandler(app.cfg, app.log, handlers.UserAvatarResolver))
+	mux.Handle("/media/collection/", handlers.MediaHandler(app.cfg, app.log, handlers.CollectionImageResolver))
+
+	// handle holders snapshot export
+	mux.Handle("/export/holders", handlers.AuthHandler(handlers.HoldersSnapshotHandler(app.log)))
 
 	// handle image upload
-	mux.Handle("/upload-image/user-avatar", handlers.AuthHandler(handlers.UploadImageHandler(app.log, handlers.StoreUserAvatar)))
-	mux.Handle("/upload-image/user-banner", handlers.AuthHandler(handlers.UploadImageHandler(app.log, handlers.StoreUserBanner)))
-	mux.Handle("/upload-image/token", handlers.AuthHandler(handlers.UploadImageHandler(app.log, handlers.StoreToken)))
+	mux.Handle("/upload-image/user-avatar", handlers.AuthHandler(handlers.UploadImageHandler(app.cfg.Upload.Avatar, app.log, handlers.StoreUserAvatar)))
+	mux.Handle("/upload-image/user-banner", handlers.AuthHandler(handlers.UploadImageHandler(app.cfg.Upload.Banner, app.log, handlers.StoreUserBanner)))
+	mux.Handle("/upload-image/token", handlers.AuthHandler(handlers.UploadImageHandler(app.cfg.Upload.Token, app.log, handlers.StoreToken)))
+
+	// handle resumable upload of large token media
+	mux.Handle("/upload-session/token", handlers.AuthHandler(handlers.UploadSessionHandler("/upload-session/token", app.cfg.Upload.Token, app.cfg.Upload.ChunkSize, app.log, handlers.StoreToken)))
+	mux.Handle("/upload-session/token/", handlers.AuthHandler(handlers.UploadSessionHandler("/upload-session/token", app.cfg.Upload.Token, app.cfg.Upload.ChunkSize, app.log, handlers.StoreToken)))
+}
+
+// exportHoldersSnapshot writes holders of the requested collection at the requested block as CSV.
+func (app *apiServer) exportHoldersSnapshot() {
+	defer repository.Close()
+
+	if !common.IsHexAddress(app.snapshotContract) {
+		app.log.Errorf("invalid snapshot contract address %s", app.snapshotContract)
+		return
+	}
+	adr := common.HexToAddress(app.snapshotContract)
+
+	snap, err := repository.R().HoldersSnapshot(&adr, app.snapshotBlock, 0)
+	if err != nil {
+		app.log.Errorf("holders snapshot failed; %s", err.Error())
+		return
+	}
+
+	out := os.Stdout
+	if app.snapshotOut != "" {
+		out, err = os.Create(app.snapshotOut)
+		if err != nil {
+			app.log.Errorf("can not create snapshot file; %s", err.Error())
+			return
+		}
+		defer func() {
+			if err := out.Close(); err != nil {
+				app.log.Errorf("can not close snapshot file; %s", err.Error())
+			}
+		}()
+	}
+
+	if err := snap.WriteCSV(out); err != nil {
+		app.log.Errorf("can not write holders snapshot; %s", err.Error())
+		return
+	}
+	app.log.Noticef("%d holders of %s at block #%d exported", len(snap.Holders), adr.String(), app.snapshotBlock)
 }
 
 // observeSignals setups terminate signals observation.
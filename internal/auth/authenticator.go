@@ -24,6 +24,10 @@ func SetConfig(c *config.Config) {
 	instance = &Authenticator{
 		bearerSecret: hexutil.MustDecode(c.Auth.BearerSecret),
 		nonceSecret:  hexutil.MustDecode(c.Auth.NonceSecret),
+		admins:       make(map[common.Address]bool, len(c.Auth.Admins)),
+	}
+	for _, adr := range c.Auth.Admins {
+		instance.admins[adr] = true
 	}
 }
 
@@ -31,6 +35,7 @@ func SetConfig(c *config.Config) {
 type Authenticator struct {
 	bearerSecret []byte
 	nonceSecret  []byte
+	admins       map[common.Address]bool
 }
 
 // GenerateChallenge provides message to be signed using Metamask.
@@ -57,6 +62,11 @@ func (a Authenticator) VerifyBearer(bearer string) (*common.Address, error) {
 	return verifyBearer(bearer, a.bearerSecret)
 }
 
+// IsAdmin checks if the given user is an operator of the API server.
+func (a Authenticator) IsAdmin(user common.Address) bool {
+	return a.admins[user]
+}
+
 // verifySignedChallenge verifies user response to authentication challenge
 func (a Authenticator) verifySignedChallenge(challenge string, address common.Address, signatureHex string) error {
 	err := a.verifyChallengeContent(challenge)
@@ -3,6 +3,7 @@ package config
 
 import (
 	"crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/common"
 	"time"
 )
 
@@ -23,6 +24,18 @@ type Config struct {
 	// IPFS represents the node structure
 	Ipfs Ipfs `mapstructure:"ipfs"`
 
+	// Pinning configures the provider storing uploaded tokens and images on IPFS
+	Pinning Pinning `mapstructure:"pinning"`
+
+	// Upload configures limits of the content uploaded by users
+	Upload Upload `mapstructure:"upload"`
+
+	// Downloader configures limits of remote NFT metadata and images downloads
+	Downloader Downloader `mapstructure:"downloader"`
+
+	// Media configures HTTP caching of served images and media
+	Media Media `mapstructure:"media"`
+
 	// Mongo database configuration
 	Db Database `mapstructure:"db"`
 
@@ -35,6 +48,9 @@ type Config struct {
 	// Auth configuration
 	Auth Auth `mapstructure:"auth"`
 
+	// Contracts provides configuration of observed marketplace contracts
+	Contracts Contracts `mapstructure:"contracts"`
+
 	// RngOracle provides configuration for random feed oracle handling
 	RngOracle RandomFeedOracle `mapstructure:"rng"`
 
@@ -42,6 +58,12 @@ type Config struct {
 	Notifications NotificationProviders `mapstructure:"notification"`
 }
 
+// Contracts represents configuration of the observed marketplace contracts.
+type Contracts struct {
+	// AuctionV1 lists addresses of Auction contracts deployed with the first version of the Auction ABI
+	AuctionV1 []common.Address `mapstructure:"auction_v1"`
+}
+
 // Server represents the GraphQL server configuration
 type Server struct {
 	BindAddress     string   `mapstructure:"bind"`
@@ -77,6 +99,143 @@ type Ipfs struct {
 
 	// GatewayBearer represents API key (JWT) to be used for Gateway auth
 	GatewayBearer string `mapstructure:"gateway_bearer"`
+
+	// Gateways is the ordered list of public HTTP gateways used as a fallback of the Gateway
+	Gateways []string `mapstructure:"gateways"`
+
+	// HedgeDelay is the time after which the next gateway is asked if the previous one did not respond yet
+	HedgeDelay time.Duration `mapstructure:"hedge_delay"`
+
+	// ArweaveGateway is the HTTP gateway used to resolve ar:// URIs
+	ArweaveGateway string `mapstructure:"arweave_gateway"`
+}
+
+// Pinning represents the configuration of the provider pinning uploaded content on IPFS.
+type Pinning struct {
+	// Provider selects the pinning provider - "pinata", "kubo" or "fs"
+	Provider string `mapstructure:"provider"`
+
+	// PinataUrl is the base URL of the Pinata API
+	PinataUrl string `mapstructure:"pinata_url"`
+
+	// PinataJwt is the API key (JWT) of the Pinata account
+	PinataJwt string `mapstructure:"pinata_jwt"`
+
+	// KuboUrl is the HTTP API address of the Kubo (go-ipfs) node pinning the content
+	KuboUrl string `mapstructure:"kubo_url"`
+
+	// FsPath is the directory keeping the content if the filesystem stand-in is used (development only);
+	// files are stored as {FsPath}/ipfs/{CID}, so the directory can be served as an IPFS gateway
+	FsPath string `mapstructure:"fs_path"`
+
+	// Timeout is the max time of a single pin request
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Upload represents limits of the content uploaded by users per upload endpoint.
+type Upload struct {
+	Avatar UploadLimits `mapstructure:"avatar"`
+	Banner UploadLimits `mapstructure:"banner"`
+	Token  UploadLimits `mapstructure:"token"`
+
+	// SessionDir is the directory keeping partial resumable uploads; system temp directory is used if empty
+	SessionDir string `mapstructure:"session_dir"`
+
+	// SessionExpiry is the time after the last received chunk the partial upload is discarded
+	SessionExpiry time.Duration `mapstructure:"session_expiry"`
+
+	// ChunkSize is the max size of a single chunk of a resumable upload in bytes
+	ChunkSize int64 `mapstructure:"chunk_size"`
+
+	// MaxSessions is the max number of unfinished resumable uploads of a user; zero means no limit
+	MaxSessions int `mapstructure:"max_sessions"`
+
+	// MaxSessionBytes is the max total declared size of unfinished resumable uploads of a user in bytes; zero means no limit
+	MaxSessionBytes int64 `mapstructure:"max_session_bytes"`
+
+	// DraftExpiry is the time after the upload the token content not minted is unpinned; zero keeps it forever
+	DraftExpiry time.Duration `mapstructure:"draft_expiry"`
+}
+
+// UploadLimits represents limits of the content uploaded to an upload endpoint.
+type UploadLimits struct {
+	// MaxSize is the max size of the uploaded file in bytes
+	MaxSize int64 `mapstructure:"max_size"`
+
+	// MaxWidth and MaxHeight are the max dimensions of uploaded images and videos in pixels
+	MaxWidth  int `mapstructure:"max_width"`
+	MaxHeight int `mapstructure:"max_height"`
+
+	// MaxDuration is the max duration of uploaded videos and audio
+	MaxDuration time.Duration `mapstructure:"max_duration"`
+
+	// Media lists the kinds of media accepted by the endpoint - "image", "video" and "audio"
+	Media []string `mapstructure:"media"`
+}
+
+// Downloader represents the remote content downloader configuration.
+type Downloader struct {
+	// Workers is the number of NFT metadata workers running in parallel
+	Workers int `mapstructure:"workers"`
+
+	// HostConcurrency is the max number of concurrent requests to a single host
+	HostConcurrency int `mapstructure:"host_concurrency"`
+
+	// HostRate is the max number of requests per second started to a single host
+	HostRate float64 `mapstructure:"host_rate"`
+
+	// BreakerThreshold is the number of subsequent failures of a host opening its circuit breaker
+	BreakerThreshold int `mapstructure:"breaker_threshold"`
+
+	// BreakerCooldown is the time a failing host is backed off for
+	BreakerCooldown time.Duration `mapstructure:"breaker_cooldown"`
+
+	// ConnectTimeout is the max time to establish a connection to a remote host
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+
+	// ReadTimeout is the max time of a remote request including reading the response body
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+
+	// MaxJsonSize is the max size of a downloaded JSON metadata document in bytes
+	MaxJsonSize int64 `mapstructure:"max_json_size"`
+
+	// MaxMediaSize is the max size of a downloaded image or video in bytes
+	MaxMediaSize int64 `mapstructure:"max_media_size"`
+
+	// MaxRedirects is the max number of redirects followed on a remote request
+	MaxRedirects int `mapstructure:"max_redirects"`
+
+	// AllowHosts is the list of host names and CIDR ranges allowed to be downloaded from
+	// even if they are private, loopback or link-local destinations
+	AllowHosts []string `mapstructure:"allow_hosts"`
+
+	// UserAgent is the User-Agent header sent with remote requests
+	UserAgent string `mapstructure:"user_agent"`
+
+	// RefreshInterval is the min time between on-demand metadata refreshes of a token by a user
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+
+	// CollectionRefreshInterval is the min time between on-demand metadata refreshes of a collection by a user
+	CollectionRefreshInterval time.Duration `mapstructure:"collection_refresh_interval"`
+
+	// CollectionRefreshLimit is the max number of tokens refreshed by an on-demand collection refresh
+	CollectionRefreshLimit int64 `mapstructure:"collection_refresh_limit"`
+}
+
+// Media represents the configuration of served images and media.
+type Media struct {
+	// ThumbnailMaxAge is the time clients and proxies may cache image thumbnails for
+	ThumbnailMaxAge time.Duration `mapstructure:"thumbnail_max_age"`
+
+	// OriginalMaxAge is the time clients and proxies may cache original media for
+	OriginalMaxAge time.Duration `mapstructure:"original_max_age"`
+
+	// OriginalMaxSize is the max size of original media kept in the derived media store in bytes;
+	// bigger originals are downloaded for each request
+	OriginalMaxSize int64 `mapstructure:"original_max_size"`
+
+	// OriginalExpiry is the time original media are kept in the derived media store for
+	OriginalExpiry time.Duration `mapstructure:"original_expiry"`
 }
 
 // Database represents the database access configuration.
@@ -93,8 +252,9 @@ type Cache struct {
 
 // Auth represents the authentication configuration.
 type Auth struct {
-	BearerSecret string `mapstructure:"bearer_secret"`
-	NonceSecret  string `mapstructure:"nonce_secret"`
+	BearerSecret string           `mapstructure:"bearer_secret"`
+	NonceSecret  string           `mapstructure:"nonce_secret"`
+	Admins       []common.Address `mapstructure:"admins"`
 }
 
 // RandomFeedOracle configures random oracle feed.
@@ -40,6 +40,118 @@ const (
 	// defSkipHttpGateways tells whether to skip known HTTP-to-IPFS gateways
 	defSkipHttpGateways = true
 
+	// defIpfsHedgeDelay holds the default time after which the next IPFS gateway is asked
+	defIpfsHedgeDelay = 3 * time.Second
+
+	// defIpfsArweaveGateway holds the default gateway of the Arweave content
+	defIpfsArweaveGateway = "https://arweave.net"
+
+	// defPinningProvider holds the default IPFS pinning provider
+	defPinningProvider = "pinata"
+
+	// defPinningPinataUrl holds the default base URL of the Pinata API
+	defPinningPinataUrl = "https://api.pinata.cloud"
+
+	// defPinningKuboUrl holds the default HTTP API address of the Kubo node
+	defPinningKuboUrl = "localhost:5001"
+
+	// defPinningFsPath holds the default directory of the filesystem pinning stand-in
+	defPinningFsPath = "pins"
+
+	// defPinningTimeout holds the default max time of a single pin request
+	defPinningTimeout = 30 * time.Second
+
+	// defUploadAvatarMaxSize holds the default max size of uploaded user avatars
+	defUploadAvatarMaxSize = 5 * 1024 * 1024
+
+	// defUploadAvatarMaxDimension holds the default max width and height of uploaded user avatars
+	defUploadAvatarMaxDimension = 2048
+
+	// defUploadBannerMaxSize holds the default max size of uploaded user banners
+	defUploadBannerMaxSize = 10 * 1024 * 1024
+
+	// defUploadBannerMaxDimension holds the default max width and height of uploaded user banners
+	defUploadBannerMaxDimension = 4096
+
+	// defUploadTokenMaxSize holds the default max size of uploaded token media
+	defUploadTokenMaxSize = 100 * 1024 * 1024
+
+	// defUploadTokenMaxDimension holds the default max width and height of uploaded token images and videos
+	defUploadTokenMaxDimension = 8192
+
+	// defUploadTokenMaxDuration holds the default max duration of uploaded token videos and audio
+	defUploadTokenMaxDuration = 10 * time.Minute
+
+	// defUploadSessionExpiry holds the default time a partial resumable upload is kept after the last chunk
+	defUploadSessionExpiry = 24 * time.Hour
+
+	// defUploadChunkSize holds the default max size of a single chunk of a resumable upload
+	defUploadChunkSize = 8 * 1024 * 1024
+
+	// defUploadMaxSessions holds the default max number of unfinished resumable uploads of a user
+	defUploadMaxSessions = 4
+
+	// defUploadMaxSessionBytes holds the default max total size of unfinished resumable uploads of a user
+	defUploadMaxSessionBytes = 256 * 1024 * 1024
+
+	// defUploadDraftExpiry holds the default time the uploaded token content is kept pinned waiting for the mint
+	defUploadDraftExpiry = 7 * 24 * time.Hour
+
+	// defDownloaderWorkers holds the default number of NFT metadata workers
+	defDownloaderWorkers = 8
+
+	// defDownloaderHostConcurrency holds the default max number of concurrent requests to a host
+	defDownloaderHostConcurrency = 4
+
+	// defDownloaderHostRate holds the default max number of requests per second to a host
+	defDownloaderHostRate = 10.0
+
+	// defDownloaderBreakerThreshold holds the default number of failures opening a host circuit breaker
+	defDownloaderBreakerThreshold = 5
+
+	// defDownloaderBreakerCooldown holds the default time a failing host is backed off for
+	defDownloaderBreakerCooldown = 2 * time.Minute
+
+	// defDownloaderConnectTimeout holds the default max time to connect to a remote host
+	defDownloaderConnectTimeout = 10 * time.Second
+
+	// defDownloaderReadTimeout holds the default max time of a remote request
+	defDownloaderReadTimeout = 60 * time.Second
+
+	// defDownloaderMaxJsonSize holds the default max size of a metadata JSON document (2 MB)
+	defDownloaderMaxJsonSize = 2 << 20
+
+	// defDownloaderMaxMediaSize holds the default max size of an image or video (100 MB)
+	defDownloaderMaxMediaSize = 100 << 20
+
+	// defDownloaderMaxRedirects holds the default max number of redirects followed
+	defDownloaderMaxRedirects = 5
+
+	// defDownloaderUserAgent holds the default User-Agent of remote requests
+	defDownloaderUserAgent = "ArtionAPI/1.0 (+https://artion.io)"
+
+	// defDownloaderRefreshInterval holds the default min time between on-demand refreshes of a token
+	defDownloaderRefreshInterval = 5 * time.Minute
+
+	// defDownloaderCollectionRefreshInterval holds the default min time between on-demand refreshes of a collection
+	defDownloaderCollectionRefreshInterval = time.Hour
+
+	// defDownloaderCollectionRefreshLimit holds the default max number of tokens of an on-demand collection refresh;
+	// it's kept well below the capacity of the priority queue, so refreshes of single tokens are not starved
+	defDownloaderCollectionRefreshLimit = 250
+
+	// defMediaThumbnailMaxAge holds the default time image thumbnails may be cached for
+	defMediaThumbnailMaxAge = 24 * time.Hour
+
+	// defMediaOriginalMaxAge holds the default time original media may be cached for
+	defMediaOriginalMaxAge = 7 * 24 * time.Hour
+
+	// defMediaOriginalMaxSize holds the default max size of original media kept in the derived media store (20 MB)
+	defMediaOriginalMaxSize = 20 << 20
+
+	// defMediaOriginalExpiry holds the default time original media are kept in the derived media store
+	defMediaOriginalExpiry = 7 * 24 * time.Hour
+
 	// defMongoUrl holds default MongoDB connection string for local database
 	defMongoUrl = "mongodb://localhost:27017"
 
@@ -81,6 +193,49 @@ func applyDefaults(cfg *viper.Viper) {
 	cfg.SetDefault(keyLachesisUrl, defLachesisUrl)
 	cfg.SetDefault(keyIpfsUrl, defIpfsUrl)
 	cfg.SetDefault(keySkipHttpGateways, defSkipHttpGateways)
+	cfg.SetDefault(keyIpfsHedgeDelay, defIpfsHedgeDelay)
+	cfg.SetDefault(keyIpfsArweaveGateway, defIpfsArweaveGateway)
+	cfg.SetDefault(keyPinningProvider, defPinningProvider)
+	cfg.SetDefault(keyPinningPinataUrl, defPinningPinataUrl)
+	cfg.SetDefault(keyPinningKuboUrl, defPinningKuboUrl)
+	cfg.SetDefault(keyPinningFsPath, defPinningFsPath)
+	cfg.SetDefault(keyPinningTimeout, defPinningTimeout)
+	cfg.SetDefault(keyUploadAvatarMaxSize, defUploadAvatarMaxSize)
+	cfg.SetDefault(keyUploadAvatarMaxWidth, defUploadAvatarMaxDimension)
+	cfg.SetDefault(keyUploadAvatarMaxHeight, defUploadAvatarMaxDimension)
+	cfg.SetDefault(keyUploadAvatarMedia, []string{"image"})
+	cfg.SetDefault(keyUploadBannerMaxSize, defUploadBannerMaxSize)
+	cfg.SetDefault(keyUploadBannerMaxWidth, defUploadBannerMaxDimension)
+	cfg.SetDefault(keyUploadBannerMaxHeight, defUploadBannerMaxDimension)
+	cfg.SetDefault(keyUploadBannerMedia, []string{"image"})
+	cfg.SetDefault(keyUploadTokenMaxSize, defUploadTokenMaxSize)
+	cfg.SetDefault(keyUploadTokenMaxWidth, defUploadTokenMaxDimension)
+	cfg.SetDefault(keyUploadTokenMaxHeight, defUploadTokenMaxDimension)
+	cfg.SetDefault(keyUploadTokenMaxDuration, defUploadTokenMaxDuration)
+	cfg.SetDefault(keyUploadTokenMedia, []string{"image", "video", "audio"})
+	cfg.SetDefault(keyUploadSessionExpiry, defUploadSessionExpiry)
+	cfg.SetDefault(keyUploadChunkSize, defUploadChunkSize)
+	cfg.SetDefault(keyUploadMaxSessions, defUploadMaxSessions)
+	cfg.SetDefault(keyUploadMaxSessionBytes, defUploadMaxSessionBytes)
+	cfg.SetDefault(keyUploadDraftExpiry, defUploadDraftExpiry)
+	cfg.SetDefault(keyDownloaderWorkers, defDownloaderWorkers)
+	cfg.SetDefault(keyDownloaderHostConcurrency, defDownloaderHostConcurrency)
+	cfg.SetDefault(keyDownloaderHostRate, defDownloaderHostRate)
+	cfg.SetDefault(keyDownloaderBreakerThreshold, defDownloaderBreakerThreshold)
+	cfg.SetDefault(keyDownloaderBreakerCooldown, defDownloaderBreakerCooldown)
+	cfg.SetDefault(keyDownloaderConnectTimeout, defDownloaderConnectTimeout)
+	cfg.SetDefault(keyDownloaderReadTimeout, defDownloaderReadTimeout)
+	cfg.SetDefault(keyDownloaderMaxJsonSize, defDownloaderMaxJsonSize)
+	cfg.SetDefault(keyDownloaderMaxMediaSize, defDownloaderMaxMediaSize)
+	cfg.SetDefault(keyDownloaderMaxRedirects, defDownloaderMaxRedirects)
+	cfg.SetDefault(keyDownloaderUserAgent, defDownloaderUserAgent)
+	cfg.SetDefault(keyDownloaderRefreshInterval, defDownloaderRefreshInterval)
+	cfg.SetDefault(keyDownloaderCollectionRefreshInterval, defDownloaderCollectionRefreshInterval)
+	cfg.SetDefault(keyDownloaderCollectionRefreshLimit, defDownloaderCollectionRefreshLimit)
+	cfg.SetDefault(keyMediaThumbnailMaxAge, defMediaThumbnailMaxAge)
+	cfg.SetDefault(keyMediaOriginalMaxAge, defMediaOriginalMaxAge)
+	cfg.SetDefault(keyMediaOriginalMaxSize, defMediaOriginalMaxSize)
+	cfg.SetDefault(keyMediaOriginalExpiry, defMediaOriginalExpiry)
 	cfg.SetDefault(keyMongoUrl, defMongoUrl)
 	cfg.SetDefault(keyMongoDatabase, defMongoDatabase)
 	cfg.SetDefault(keySharedMongoUrl, defSharedMongoUrl)
@@ -101,7 +256,11 @@ func applyDefaults(cfg *viper.Viper) {
 	// cors
 	cfg.SetDefault(keyCorsAllowOrigins, defCorsAllowOrigins)
 
+	// contracts
+	cfg.SetDefault(keyContractsAuctionV1, []string{})
+
 	// auth
 	cfg.SetDefault(keyAuthBearerSecret, defAuthBearerSecret)
 	cfg.SetDefault(keyAuthNonceSecret, defAuthNonceSecret)
+	cfg.SetDefault(keyAuthAdmins, []string{})
 }
@@ -31,6 +31,58 @@ const (
 	// IPFS node connection related options
 	keyIpfsUrl = "ipfs.url"
 	keySkipHttpGateways = "ipfs.skip_http_gateways"
+	keyIpfsHedgeDelay     = "ipfs.hedge_delay"
+	keyIpfsArweaveGateway = "ipfs.arweave_gateway"
+
+	// IPFS pinning related options
+	keyPinningProvider  = "pinning.provider"
+	keyPinningPinataUrl = "pinning.pinata_url"
+	keyPinningKuboUrl   = "pinning.kubo_url"
+	keyPinningFsPath    = "pinning.fs_path"
+	keyPinningTimeout   = "pinning.timeout"
+
+	// content upload related options
+	keyUploadAvatarMaxSize    = "upload.avatar.max_size"
+	keyUploadAvatarMaxWidth   = "upload.avatar.max_width"
+	keyUploadAvatarMaxHeight  = "upload.avatar.max_height"
+	keyUploadAvatarMedia      = "upload.avatar.media"
+	keyUploadBannerMaxSize    = "upload.banner.max_size"
+	keyUploadBannerMaxWidth   = "upload.banner.max_width"
+	keyUploadBannerMaxHeight  = "upload.banner.max_height"
+	keyUploadBannerMedia      = "upload.banner.media"
+	keyUploadTokenMaxSize     = "upload.token.max_size"
+	keyUploadTokenMaxWidth    = "upload.token.max_width"
+	keyUploadTokenMaxHeight   = "upload.token.max_height"
+	keyUploadTokenMaxDuration = "upload.token.max_duration"
+	keyUploadTokenMedia       = "upload.token.media"
+	keyUploadSessionDir       = "upload.session_dir"
+	keyUploadSessionExpiry    = "upload.session_expiry"
+	keyUploadChunkSize        = "upload.chunk_size"
+	keyUploadMaxSessions      = "upload.max_sessions"
+	keyUploadMaxSessionBytes  = "upload.max_session_bytes"
+	keyUploadDraftExpiry      = "upload.draft_expiry"
+
+	// remote content downloader related options
+	keyDownloaderWorkers          = "downloader.workers"
+	keyDownloaderHostConcurrency  = "downloader.host_concurrency"
+	keyDownloaderHostRate         = "downloader.host_rate"
+	keyDownloaderBreakerThreshold = "downloader.breaker_threshold"
+	keyDownloaderBreakerCooldown  = "downloader.breaker_cooldown"
+	keyDownloaderConnectTimeout   = "downloader.connect_timeout"
+	keyDownloaderReadTimeout      = "downloader.read_timeout"
+	keyDownloaderMaxJsonSize      = "downloader.max_json_size"
+	keyDownloaderMaxMediaSize     = "downloader.max_media_size"
+	keyDownloaderMaxRedirects     = "downloader.max_redirects"
+	keyDownloaderUserAgent        = "downloader.user_agent"
+	keyDownloaderRefreshInterval  = "downloader.refresh_interval"
+	keyDownloaderCollectionRefreshInterval = "downloader.collection_refresh_interval"
+	keyDownloaderCollectionRefreshLimit    = "downloader.collection_refresh_limit"
+
+	// served media related options
+	keyMediaThumbnailMaxAge = "media.thumbnail_max_age"
+	keyMediaOriginalMaxAge  = "media.original_max_age"
+	keyMediaOriginalMaxSize = "media.original_max_size"
+	keyMediaOriginalExpiry  = "media.original_expiry"
 
 	// off-chain database related options
 	keyMongoUrl      = "db.url"
@@ -43,7 +95,11 @@ const (
 	keyCacheEvictionTime = "cache.eviction"
 	keyCacheMaxSize      = "cache.size"
 
+	// observed contracts related options
+	keyContractsAuctionV1 = "contracts.auction_v1"
+
 	// authentication related options
 	keyAuthBearerSecret = "auth.bearer_secret"
 	keyAuthNonceSecret = "auth.nonce_secret"
+	keyAuthAdmins = "auth.admins"
 )
@@ -87,6 +87,14 @@ func ActivityTypeToString(t types.ActivityType) string {
 		return "AUCTION_RESOLVED"
 	case types.EvtAuctionUpdated:
 		return "AUCTION_UPDATED"
+	case types.EvtTokenMinted:
+		return "MINT"
+	case types.EvtTokenTransferred:
+		return "TRANSFER"
+	case types.EvtTokenBurned:
+		return "BURN"
+	case types.EvtMetadataChanged:
+		return "METADATA_CHANGED"
 	}
 	return "UNKNOWN"
 }
@@ -119,6 +127,14 @@ func ActivityTypeFromString(t string) types.ActivityType {
 		return types.EvtAuctionResolved
 	case "AUCTION_UPDATED":
 		return types.EvtAuctionUpdated
+	case "MINT":
+		return types.EvtTokenMinted
+	case "TRANSFER":
+		return types.EvtTokenTransferred
+	case "BURN":
+		return types.EvtTokenBurned
+	case "METADATA_CHANGED":
+		return types.EvtMetadataChanged
 	}
 	return types.EvtUnknown
 }
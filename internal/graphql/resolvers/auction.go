@@ -14,7 +14,7 @@ type Auction types.Auction
 
 // MinBidAmount is the mount required to be placed as a bid to outbid the current highest bidder.
 func (au *Auction) MinBidAmount() (hexutil.Big, error) {
-	val, err := repository.R().AuctionGetMinBid(&au.Contract, (*big.Int)(&au.TokenId))
+	val, err := repository.R().AuctionGetMinBid(&au.AuctionHall, &au.Contract, (*big.Int)(&au.TokenId))
 	if err != nil {
 		return hexutil.Big{}, err
 	}
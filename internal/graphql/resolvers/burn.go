@@ -0,0 +1,58 @@
+package resolvers
+
+import (
+	"artion-api-graphql/internal/types"
+	"artion-api-graphql/internal/types/sorting"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+)
+
+type Burn types.NFTBurn
+
+func (b Burn) Token() (*Token, error) {
+	return NewToken(&b.Contract, &b.TokenId)
+}
+
+func (b Burn) OwnerUser() (User, error) {
+	return User{
+		Address: b.Owner,
+	}, nil
+}
+
+type BurnEdge struct {
+	Node *Burn
+}
+
+func (edge BurnEdge) Cursor() (types.Cursor, error) {
+	return sorting.BurnSortingNone.GetCursor((*types.NFTBurn)(edge.Node))
+}
+
+type BurnConnection struct {
+	Edges      []BurnEdge
+	TotalCount hexutil.Big
+	PageInfo   PageInfo
+}
+
+func NewBurnConnection(list *types.NFTBurnList) (con *BurnConnection, err error) {
+	con = new(BurnConnection)
+	con.TotalCount = (hexutil.Big)(*big.NewInt(list.TotalCount))
+	con.Edges = make([]BurnEdge, len(list.Collection))
+	for i := 0; i < len(list.Collection); i++ {
+		con.Edges[i].Node = (*Burn)(list.Collection[i])
+	}
+	con.PageInfo.HasNextPage = list.HasNext
+	con.PageInfo.HasPreviousPage = list.HasPrev
+	if len(list.Collection) > 0 {
+		startCur, err := con.Edges[0].Cursor()
+		if err != nil {
+			return nil, err
+		}
+		endCur, err := con.Edges[len(con.Edges)-1].Cursor()
+		if err != nil {
+			return nil, err
+		}
+		con.PageInfo.StartCursor = &startCur
+		con.PageInfo.EndCursor = &endCur
+	}
+	return con, err
+}
@@ -100,6 +100,27 @@ func (t Collection) Image() (string, error) {
 	return t.dbCollection.Image, nil
 }
 
+func (t Collection) Traits() ([]*types.TraitValueCount, error) {
+	return repository.R().CollectionTraits(&t.Contract)
+}
+
+func (t Collection) HoldersCount() (int32, error) {
+	count, err := repository.R().CollectionHoldersCount(&t.Contract)
+	return int32(count), err
+}
+
+func (t Collection) Burns(args struct{ PaginationInput }) (con *BurnConnection, err error) {
+	cursor, count, backward, err := args.ToRepositoryInput()
+	if err != nil {
+		return nil, err
+	}
+	list, err := repository.R().ListBurns(&t.Contract, nil, nil, cursor, count, backward)
+	if err != nil {
+		return nil, err
+	}
+	return NewBurnConnection(list)
+}
+
 func (rs *RootResolver) Collection(args struct {
 	Contract common.Address
 }) (*Collection, error) {
@@ -19,6 +19,17 @@ func (e Event) Offer() (*Offer, error) {
 	return (*Offer)(e.Event.Offer), nil
 }
 
+func (e Event) Token() *Token {
+	return (*Token)(e.Event.Token)
+}
+
+func (e Event) Message() *string {
+	if e.Event.Message == "" {
+		return nil
+	}
+	return &e.Event.Message
+}
+
 func (rs *RootResolver) WatchUserEvents(ctx context.Context, args struct {
 	User common.Address
 }) <-chan Event {
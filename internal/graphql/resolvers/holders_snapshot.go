@@ -0,0 +1,62 @@
+package resolvers
+
+import (
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// HoldersSnapshot represents holders of tokens of a collection at the given block.
+type HoldersSnapshot struct {
+	snap *types.HoldersSnapshot
+}
+
+// HolderBalance represents amount of tokens of a collection held by an account.
+type HolderBalance types.HolderBalance
+
+func (rs *RootResolver) HoldersSnapshot(ctx context.Context, args struct {
+	Contract common.Address
+	Block    hexutil.Uint64
+}) (*HoldersSnapshot, error) {
+	if _, err := getAdminOrErr(ctx); err != nil {
+		return nil, err
+	}
+
+	snap, err := repository.R().HoldersSnapshot(&args.Contract, uint64(args.Block), types.HoldersSnapshotMaxBalances)
+	if err != nil {
+		return nil, err
+	}
+	return &HoldersSnapshot{snap: snap}, nil
+}
+
+func (hs *HoldersSnapshot) Contract() common.Address {
+	return hs.snap.Contract
+}
+
+func (hs *HoldersSnapshot) Block() hexutil.Uint64 {
+	return hexutil.Uint64(hs.snap.Block)
+}
+
+func (hs *HoldersSnapshot) TotalCount() int32 {
+	return int32(len(hs.snap.Holders))
+}
+
+func (hs *HoldersSnapshot) Holders() []*HolderBalance {
+	list := make([]*HolderBalance, len(hs.snap.Holders))
+	for i, hb := range hs.snap.Holders {
+		list[i] = (*HolderBalance)(hb)
+	}
+	return list
+}
+
+func (hb *HolderBalance) OwnerUser() (User, error) {
+	return User{
+		Address: hb.Owner,
+	}, nil
+}
+
+func (hb *HolderBalance) Balance() hexutil.Big {
+	return hb.Qty
+}
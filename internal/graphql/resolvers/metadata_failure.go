@@ -0,0 +1,101 @@
+package resolvers
+
+import (
+	"artion-api-graphql/internal/auth"
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxMetadataFailureTokens is the max number of failing tokens provided for a failure group.
+const maxMetadataFailureTokens = 100
+
+// TokenMetadataError represents the last failure of the token metadata update.
+type TokenMetadataError types.TokenMetaError
+
+// MetadataFailureGroup represents tokens failing on the metadata update for the same reason on the same host.
+type MetadataFailureGroup types.MetadataFailureGroup
+
+// getAdminOrErr provides the identity of the logged user, if the user is an admin of the API server.
+func getAdminOrErr(ctx context.Context) (*common.Address, error) {
+	identity, err := auth.GetIdentityOrErr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.GetAuthenticator().IsAdmin(*identity) {
+		return nil, fmt.Errorf("not authorized - not an admin")
+	}
+	return identity, nil
+}
+
+// MetadataError resolves the last failure of the token metadata update.
+// The error message may reveal internals of the server, it's provided to admins only.
+func (t *Token) MetadataError(ctx context.Context) *TokenMetadataError {
+	if t.MetaError == nil {
+		return nil
+	}
+
+	me := TokenMetadataError(*t.MetaError)
+	if _, err := getAdminOrErr(ctx); err != nil {
+		me.Message = ""
+	}
+	return &me
+}
+
+// MetadataFailures resolves the list of tokens failing on the metadata update grouped by the reason and host.
+func (rs *RootResolver) MetadataFailures(ctx context.Context) ([]*MetadataFailureGroup, error) {
+	if _, err := getAdminOrErr(ctx); err != nil {
+		return nil, err
+	}
+
+	list, err := repository.R().MetadataFailureGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*MetadataFailureGroup, len(list))
+	for i, fg := range list {
+		out[i] = (*MetadataFailureGroup)(fg)
+	}
+	return out, nil
+}
+
+// RetryFailedMetadata schedules immediate metadata update of tokens failing
+// with the given reason on the given host.
+func (rs *RootResolver) RetryFailedMetadata(ctx context.Context, args struct {
+	Category *string
+	Host     *string
+}) (int32, error) {
+	admin, err := getAdminOrErr(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := repository.R().RetryFailedMetadata(args.Category, args.Host)
+	if err != nil {
+		return 0, err
+	}
+	log.Noticef("%d tokens with failed metadata rescheduled by %s", count, admin.String())
+	return int32(count), nil
+}
+
+// Tokens resolves failing tokens of the group, the most recent failures go first.
+func (fg *MetadataFailureGroup) Tokens(args struct{ First int32 }) ([]*Token, error) {
+	limit := int64(args.First)
+	if limit < 1 || limit > maxMetadataFailureTokens {
+		return nil, fmt.Errorf("invalid number of tokens requested, %d allowed", maxMetadataFailureTokens)
+	}
+
+	list, err := repository.R().MetadataFailingTokens(fg.Category, fg.Host, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*Token, len(list))
+	for i, tok := range list {
+		out[i] = (*Token)(tok)
+	}
+	return out, nil
+}
@@ -22,6 +22,8 @@ func tokenSortingFromString(s *string) (sorting.TokenSorting, error) {
 		return sorting.TokenSortingPrice, nil
 	case "LAST_TRADE_AMOUNT":
 		return sorting.TokenSortingLastTradeAmount, nil
+	case "RARITY":
+		return sorting.TokenSortingRarity, nil
 	}
 	panic("Unknown TokenSorting")
 }
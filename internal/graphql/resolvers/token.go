@@ -94,6 +94,66 @@ func (t *Token) ImageMimetype() *string {
 	return &mimetype
 }
 
+// Preview generates REST path providing the preview clip of the token video from this Artion API.
+func (t *Token) Preview() *string {
+	if (*types.Token)(t).PreviewSource() == "" {
+		return nil
+	}
+	uri := fmt.Sprintf("/previews/token/%s/%s", t.Contract.String(), t.TokenId.String())
+	return &uri
+}
+
+// PreviewMimetype resolves MIME type of the token preview clip.
+func (t *Token) PreviewMimetype() *string {
+	if (*types.Token)(t).PreviewSource() == "" {
+		return nil
+	}
+	mimetype := types.PreviewVariant.Format.Mimetype()
+	return &mimetype
+}
+
+// Animation resolves URL of the token rich media.
+func (t *Token) Animation() *string {
+	if t.AnimationURI == "" {
+		return nil
+	}
+	return &t.AnimationURI
+}
+
+// AnimationMimetype resolves MIME type of the token rich media.
+func (t *Token) AnimationMimetype() *string {
+	if t.AnimationURI == "" || t.AnimationType == "" {
+		return nil
+	}
+	return &t.AnimationType
+}
+
+// AnimationKind resolves the kind of the token rich media.
+func (t *Token) AnimationKind() string {
+	return t.AnimationMedia.String()
+}
+
+// ExternalUrl resolves URL of the token on the creator site.
+func (t *Token) ExternalUrl() *string {
+	if t.ExternalLink == "" {
+		return nil
+	}
+	return &t.ExternalLink
+}
+
+// BackgroundColor resolves the background color of the token.
+func (t *Token) BackgroundColor() *string {
+	if t.BgColor == "" {
+		return nil
+	}
+	return &t.BgColor
+}
+
+// OnChainMetadata checks if the token metadata are stored on chain.
+func (t *Token) OnChainMetadata() bool {
+	return (*types.Token)(t).HasOnChainMetadata()
+}
+
 // HasListing checks if the given token has any active listing right now.
 func (t *Token) HasListing() bool {
 	if nil == t.HasListingSince {
@@ -120,8 +180,9 @@ func (t *Token) HasAuction() bool {
 }
 
 // ImageThumb generates REST path providing the token image thumbnail from this Artion API.
+// The path is provided only if the thumbnail has been pre-generated already.
 func (t *Token) ImageThumb() *string {
-	if t.ImageURI == "" {
+	if t.ImageURI == "" || t.ThumbStatus != types.ThumbnailReady {
 		return nil
 	}
 	uri := fmt.Sprintf("/images/token/%s/%s", t.Contract.String(), t.TokenId.String())
@@ -164,6 +225,34 @@ func (t *Token) Ownerships(args struct{ PaginationInput }) (con *OwnershipConnec
 	return NewOwnershipConnection(list)
 }
 
+func (t *Token) TotalSupply() hexutil.Big {
+	return t.Supply
+}
+
+func (t *Token) HoldersCount() int32 {
+	return int32(t.Holders)
+}
+
+func (t *Token) IsFungible() bool {
+	return t.Supply.ToInt().Cmp(big.NewInt(1)) > 0
+}
+
+func (t *Token) IsBurned() bool {
+	return t.Burned != nil
+}
+
+func (t *Token) Burns(args struct{ PaginationInput }) (con *BurnConnection, err error) {
+	cursor, count, backward, err := args.ToRepositoryInput()
+	if err != nil {
+		return nil, err
+	}
+	list, err := repository.R().ListBurns(&t.Contract, &t.TokenId, nil, cursor, count, backward)
+	if err != nil {
+		return nil, err
+	}
+	return NewBurnConnection(list)
+}
+
 func (t *Token) Listings(args struct{ PaginationInput }) (con *ListingConnection, err error) {
 	cursor, count, backward, err := args.ToRepositoryInput()
 	if err != nil {
@@ -210,7 +299,7 @@ func (t Token) Activities(args struct{
 }
 
 func (t *Token) Auction() (auction *Auction, err error) {
-	a, err := repository.R().GetAuction(&t.Contract, (*big.Int)(&t.TokenId))
+	a, err := repository.R().LatestAuction(&t.Contract, (*big.Int)(&t.TokenId))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,57 @@
+package resolvers
+
+import (
+	"artion-api-graphql/internal/auth"
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"bytes"
+	"context"
+	"time"
+)
+
+// TokenDraft represents token data uploaded by a user and not minted yet.
+type TokenDraft types.TokenDraft
+
+// Drafts resolves the token drafts of the user; drafts are available only for the user itself.
+func (user User) Drafts(ctx context.Context) ([]*TokenDraft, error) {
+	logged, _ := auth.GetIdentityOrNil(ctx)
+	if logged == nil || !bytes.Equal(logged.Bytes(), user.Address.Bytes()) {
+		return []*TokenDraft{}, nil
+	}
+
+	list, err := repository.R().TokenDrafts(user.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*TokenDraft, len(list))
+	for i, td := range list {
+		out[i] = (*TokenDraft)(td)
+	}
+	return out, nil
+}
+
+// Image resolves the image URL of the token draft.
+func (td *TokenDraft) Image() *string {
+	if td.ImageURI == "" {
+		return nil
+	}
+	return &td.ImageURI
+}
+
+// Animation resolves the rich media URL of the token draft.
+func (td *TokenDraft) Animation() *string {
+	if td.AnimationURI == "" {
+		return nil
+	}
+	return &td.AnimationURI
+}
+
+// Expires resolves the time the draft content is unpinned unless the token is minted.
+func (td *TokenDraft) Expires() *types.Time {
+	if cfg.Upload.DraftExpiry <= 0 {
+		return nil
+	}
+	ex := types.Time(time.Time(td.Created).Add(cfg.Upload.DraftExpiry))
+	return &ex
+}
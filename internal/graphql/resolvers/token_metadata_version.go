@@ -0,0 +1,50 @@
+package resolvers
+
+import (
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"strings"
+)
+
+// TokenMetadataVersion represents a version of the token metadata.
+type TokenMetadataVersion types.TokenMetadataVersion
+
+// MetadataHistory resolves the versions of the token metadata, the latest version goes first.
+func (t *Token) MetadataHistory() ([]*TokenMetadataVersion, error) {
+	list, err := repository.R().TokenMetadataHistory(&t.Contract, &t.TokenId)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*TokenMetadataVersion, len(list))
+	for i, mv := range list {
+		out[i] = (*TokenMetadataVersion)(mv)
+	}
+	return out, nil
+}
+
+// ContentHash resolves the hash of the metadata document.
+func (mv *TokenMetadataVersion) ContentHash() string {
+	return strings.TrimPrefix(mv.Hash.Hex(), "0x")
+}
+
+// Image resolves the image URL of the token in this version.
+func (mv *TokenMetadataVersion) Image() *string {
+	if mv.ImageURI == "" {
+		return nil
+	}
+	return &mv.ImageURI
+}
+
+// Animation resolves the rich media URL of the token in this version.
+func (mv *TokenMetadataVersion) Animation() *string {
+	if mv.AnimationURI == "" {
+		return nil
+	}
+	return &mv.AnimationURI
+}
+
+// Json resolves the metadata JSON document.
+func (mv *TokenMetadataVersion) Json() string {
+	return string(mv.Data)
+}
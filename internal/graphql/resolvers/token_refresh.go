@@ -0,0 +1,95 @@
+package resolvers
+
+import (
+	"artion-api-graphql/internal/auth"
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/svc"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// RefreshTokenMetadata schedules an immediate refresh of the token metadata.
+// Only owners and the creator of the token can request the refresh;
+// the outcome is published to the user events subscription.
+func (rs *RootResolver) RefreshTokenMetadata(ctx context.Context, args struct {
+	Contract common.Address
+	TokenId  hexutil.Big
+}) (bool, error) {
+	identity, err := auth.GetIdentityOrErr(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	tok, err := repository.R().Token(&args.Contract, &args.TokenId)
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return false, fmt.Errorf("token not found")
+	}
+
+	if tok.CreatedBy != *identity {
+		isOwner, err := repository.R().IsOwnerOf(args.Contract, args.TokenId, *identity)
+		if err != nil {
+			return false, err
+		}
+		if !isOwner {
+			return false, fmt.Errorf("not authorized - not owner or creator of the token")
+		}
+	}
+
+	if err := svc.Mgr().RefreshTokenMetadata(*identity, args.Contract, args.TokenId); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RefreshCollectionMetadata schedules an immediate refresh of metadata of tokens of the collection.
+// The owner of the collection and creators of its tokens can request the refresh;
+// the number of tokens scheduled is returned.
+func (rs *RootResolver) RefreshCollectionMetadata(ctx context.Context, args struct {
+	Contract common.Address
+}) (int32, error) {
+	identity, err := auth.GetIdentityOrErr(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	lc, err := repository.R().GetLegacyCollection(args.Contract)
+	if err != nil {
+		return 0, err
+	}
+
+	var owner *common.Address
+	if lc != nil {
+		owner = &lc.Owner
+	}
+	err = authorizeCollectionRefresh(*identity, owner, func() (bool, error) {
+		return repository.R().IsCollectionTokenCreator(&args.Contract, identity)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return svc.Mgr().RefreshCollectionMetadata(*identity, args.Contract)
+}
+
+// authorizeCollectionRefresh checks if the user can refresh the collection of the given owner;
+// the owner is nil for collections not registered with the marketplace. The owner is allowed
+// to refresh the collection, so are creators of its tokens.
+func authorizeCollectionRefresh(user common.Address, owner *common.Address, isCreator func() (bool, error)) error {
+	if owner != nil && *owner == user {
+		return nil
+	}
+
+	ok, err := isCreator()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("not authorized - not owner of the collection or creator of its tokens")
+	}
+	return nil
+}
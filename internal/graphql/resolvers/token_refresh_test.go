@@ -0,0 +1,33 @@
+package resolvers
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/onsi/gomega"
+	"testing"
+)
+
+func TestAuthorizeCollectionRefresh(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	owner := common.HexToAddress("0x61af4d29f672e27a097291f72fc571304bc93521")
+	user := common.HexToAddress("0x9a6f9a0c8ad8ec5bc1b8b4e0fe0a0a0f7bc11d5e")
+	creator := func(ok bool) func() (bool, error) {
+		return func() (bool, error) { return ok, nil }
+	}
+
+	// the owner of the collection
+	g.Expect(authorizeCollectionRefresh(owner, &owner, creator(false))).To(gomega.BeNil())
+
+	// creators of tokens of registered and not registered collections
+	g.Expect(authorizeCollectionRefresh(user, &owner, creator(true))).To(gomega.BeNil())
+	g.Expect(authorizeCollectionRefresh(user, nil, creator(true))).To(gomega.BeNil())
+
+	// anybody else
+	g.Expect(authorizeCollectionRefresh(user, &owner, creator(false))).ToNot(gomega.BeNil())
+	g.Expect(authorizeCollectionRefresh(user, nil, creator(false))).ToNot(gomega.BeNil())
+
+	// failed check does not authorize
+	failed := errors.New("failed")
+	g.Expect(authorizeCollectionRefresh(user, nil, func() (bool, error) { return true, failed })).To(gomega.Equal(failed))
+}
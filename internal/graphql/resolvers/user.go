@@ -127,6 +127,18 @@ func (user User) Ownerships(args struct{ PaginationInput }) (con *OwnershipConne
 	return NewOwnershipConnection(list)
 }
 
+func (user User) Burns(args struct{ PaginationInput }) (con *BurnConnection, err error) {
+	cursor, count, backward, err := args.ToRepositoryInput()
+	if err != nil {
+		return nil, err
+	}
+	list, err := repository.R().ListBurns(nil, nil, &user.Address, cursor, count, backward)
+	if err != nil {
+		return nil, err
+	}
+	return NewBurnConnection(list)
+}
+
 func (user User) TokenLikes(args struct{ PaginationInput }) (con *TokenLikeConnection, err error) {
 	cursor, count, backward, err := args.ToRepositoryInput()
 	if err != nil {
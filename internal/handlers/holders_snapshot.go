@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"artion-api-graphql/internal/auth"
+	"artion-api-graphql/internal/logger"
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"net/http"
+	"strconv"
+)
+
+// HoldersSnapshotHandler builds a HTTP handler function exporting holders of a collection
+// at the given block as CSV; i.e. /export/holders?contract=0x...&block=123
+// The export is available to API server operators only and is limited in size,
+// big collections are exported from the command line (see the -snapshot flag).
+func HoldersSnapshotHandler(log logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("Panic in HoldersSnapshotHandler handler; %s", r)
+				w.WriteHeader(500)
+				_, _ = w.Write([]byte("Request handling failed"))
+			}
+		}()
+
+		identity, err := auth.GetIdentityOrErr(req.Context())
+		if err != nil {
+			w.WriteHeader(401)
+			_, _ = w.Write([]byte("Unauthorized"))
+			return
+		}
+		if !auth.GetAuthenticator().IsAdmin(*identity) {
+			w.WriteHeader(403)
+			_, _ = w.Write([]byte("Forbidden"))
+			return
+		}
+
+		contract := req.URL.Query().Get("contract")
+		if !common.IsHexAddress(contract) {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte("Invalid contract address"))
+			return
+		}
+
+		block, err := strconv.ParseUint(req.URL.Query().Get("block"), 10, 64)
+		if err != nil {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte("Invalid block number"))
+			return
+		}
+
+		adr := common.HexToAddress(contract)
+		snap, err := repository.R().HoldersSnapshot(&adr, block, types.HoldersSnapshotMaxBalances)
+		if err != nil {
+			if errors.Is(err, types.ErrHoldersSnapshotTooLarge) {
+				w.WriteHeader(413)
+				_, _ = w.Write([]byte("Too many holders, use the command line export"))
+				return
+			}
+			log.Errorf("holders snapshot failed; %s", err)
+			w.WriteHeader(500)
+			_, _ = w.Write([]byte("Obtaining holders failed"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"holders_%s_%d.csv\"", adr.String(), block))
+		w.WriteHeader(200)
+		if err := snap.WriteCSV(w); err != nil {
+			log.Errorf("writing holders snapshot response failed; %s", err)
+		}
+	})
+}
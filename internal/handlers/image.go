@@ -1,18 +1,36 @@
 package handlers
 
 import (
+	"artion-api-graphql/internal/config"
 	"artion-api-graphql/internal/logger"
 	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"net"
 	"net/http"
 	"strings"
 )
 
+var (
+	// errInvalidImagePath is returned by image resolvers if the image path can not be parsed.
+	errInvalidImagePath = errors.New("invalid image path")
+
+	// errImageNotFound is returned by image resolvers if the requested image does not exist.
+	errImageNotFound = errors.New("image not found")
+
+	// errImageLookup is returned by image resolvers if the image source could not be looked up locally.
+	errImageLookup = errors.New("image lookup failed")
+)
+
 // ImageHandler builds a HTTP handler function for Token images.
-func ImageHandler(log logger.Logger, resolver func(path string) (string, error)) http.Handler {
+// The size and the output format of the thumbnail can be requested
+// by "size" (64, 256, 500, 1000) and "format" (jpeg, png, gif, webp) query parameters.
+// SVG images are served sanitized; they are rasterized only if a format is requested.
+func ImageHandler(cfg *config.Config, log logger.Logger, resolver func(path string) (string, error)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		defer func() {
 			if r := recover(); r != nil {
@@ -25,80 +43,106 @@ func ImageHandler(log logger.Logger, resolver func(path string) (string, error))
 			}
 		}()
 
+		variant, err := types.ParseThumbnailVariant(req.URL.Query().Get("size"), req.URL.Query().Get("format"))
+		if err != nil {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte("Invalid thumbnail variant: " + err.Error()))
+			return
+		}
+
 		uri, err := resolver(req.URL.Path)
 		if err != nil {
-			log.Errorf("token image request handling failed; %s", err)
-			w.WriteHeader(500)
-			_, _ = w.Write([]byte("Request handling failed: " + err.Error()))
+			imageError(log, w, req, err)
 			return
 		}
 
-		image, err := repository.R().GetImageThumbnail(uri)
+		image, err := repository.R().GetImageThumbnailVariant(uri, variant)
 		if err != nil {
-			log.Errorf("unable to get image; %s", err)
-			w.WriteHeader(500)
-			_, _ = w.Write([]byte("Obtaining image failed: " + err.Error()))
+			imageError(log, w, req, err)
 			return
 		}
 		if image == nil || len(image.Data) == 0 {
-			w.WriteHeader(404)
-			_, _ = w.Write([]byte("No image available"))
+			imageError(log, w, req, errImageNotFound)
 			return
 		}
 
-		if image.Type.Mimetype() == "" {
-			w.Header()["Content-Type"] = nil
-		} else {
-			w.Header().Add("Content-Type", image.Type.Mimetype())
-		}
-		w.WriteHeader(200)
-		_, err = w.Write(image.Data)
-		if err != nil {
-			log.Errorf("writing image response failed; %s", err)
-		}
+		serveMedia(w, req, image, cfg.Media.ThumbnailMaxAge)
 	})
 }
 
 // TokenImageResolver resolves /token/{nft}/{tokenId} to token image URI
+// The image URI stored with the token is used; the metadata is consulted
+// only for tokens not refreshed yet, and it comes from the persistent metadata cache.
 func TokenImageResolver(path string) (imageUri string, err error) {
 	pathParts := strings.Split(path, "/")
 	if len(pathParts) != 5 {
-		return "", errors.New("invalid amount of slash delimiters in URL")
+		return "", fmt.Errorf("%w; invalid amount of slash delimiters", errInvalidImagePath)
 	}
 	tokenAddress := common.HexToAddress(pathParts[3])
 	tokenId, err := hexutil.DecodeBig(pathParts[4])
 	if err != nil {
-		return "", fmt.Errorf("unable to hex-decode tokenId; %s", err)
+		return "", fmt.Errorf("%w; unable to hex-decode tokenId; %s", errInvalidImagePath, err)
 	}
 
 	tok, err := repository.R().Token(&tokenAddress, (*hexutil.Big)(tokenId))
 	if err != nil {
-		return "", fmt.Errorf("unable to get token in db; %s", err)
+		return "", fmt.Errorf("%w; unable to get token in db; %s", errImageLookup, err)
+	}
+	if tok == nil {
+		return "", fmt.Errorf("%w; unknown token", errImageNotFound)
+	}
+	if tok.ImageURI != "" {
+		return tok.ImageURI, nil
+	}
+	if tok.Uri == "" {
+		return "", fmt.Errorf("%w; token has no metadata", errImageNotFound)
 	}
 
-	jsonMetadata, err := repository.R().GetTokenJsonMetadata(tok.Uri)
+	jsonMetadata, err := repository.R().CachedTokenJsonMetadata(tok.Uri)
 	if err != nil {
-		return "", fmt.Errorf("unable to get token json metadata; %s", err)
+		return "", fmt.Errorf("unable to get token json metadata; %w", err)
 	}
 	if jsonMetadata.Image == nil || *jsonMetadata.Image == "" {
-		return "", fmt.Errorf("token has no image; %s", err)
+		return "", fmt.Errorf("%w; token has no image", errImageNotFound)
 	}
 	return *jsonMetadata.Image, nil
 }
 
+// TokenPreviewResolver resolves /token/{nft}/{tokenId} to URI of the video the token preview is made from
+func TokenPreviewResolver(path string) (videoUri string, err error) {
+	pathParts := strings.Split(path, "/")
+	if len(pathParts) != 5 {
+		return "", fmt.Errorf("%w; invalid amount of slash delimiters", errInvalidImagePath)
+	}
+	tokenAddress := common.HexToAddress(pathParts[3])
+	tokenId, err := hexutil.DecodeBig(pathParts[4])
+	if err != nil {
+		return "", fmt.Errorf("%w; unable to hex-decode tokenId; %s", errInvalidImagePath, err)
+	}
+
+	tok, err := repository.R().Token(&tokenAddress, (*hexutil.Big)(tokenId))
+	if err != nil {
+		return "", fmt.Errorf("%w; unable to get token in db; %s", errImageLookup, err)
+	}
+	if tok == nil || tok.PreviewSource() == "" {
+		return "", fmt.Errorf("%w; token has no video", errImageNotFound)
+	}
+	return tok.PreviewSource(), nil
+}
+
 // UserAvatarResolver resolves /avatar/{address} to user avatar URI
 func UserAvatarResolver(path string) (imageUri string, err error) {
 	pathParts := strings.Split(path, "/")
 	if len(pathParts) != 5 {
-		return "", errors.New("invalid amount of slash delimiters in URL")
+		return "", fmt.Errorf("%w; invalid amount of slash delimiters", errInvalidImagePath)
 	}
 	userAddress := common.HexToAddress(pathParts[3])
 	user, err := repository.R().GetUser(userAddress)
 	if err != nil {
-		return "", fmt.Errorf("unable to find user in db; %s", err)
+		return "", fmt.Errorf("%w; unable to find user in db; %s", errImageLookup, err)
 	}
 	if user == nil || user.Avatar == nil || *user.Avatar == "" {
-		return "", fmt.Errorf("user has no avatar; %s", err)
+		return "", fmt.Errorf("%w; user has no avatar", errImageNotFound)
 	}
 	return "/ipfs/" + *user.Avatar, nil
 }
@@ -107,15 +151,47 @@ func UserAvatarResolver(path string) (imageUri string, err error) {
 func CollectionImageResolver(path string) (imageUri string, err error) {
 	pathParts := strings.Split(path, "/")
 	if len(pathParts) != 5 {
-		return "", errors.New("invalid amount of slash delimiters in URL")
+		return "", fmt.Errorf("%w; invalid amount of slash delimiters", errInvalidImagePath)
 	}
 	collectionAddress := common.HexToAddress(pathParts[3])
 	collection, err := repository.R().GetLegacyCollection(collectionAddress)
 	if err != nil {
-		return "", fmt.Errorf("unable to find collection in db; %s", err)
+		return "", fmt.Errorf("%w; unable to find collection in db; %s", errImageLookup, err)
 	}
 	if collection == nil || collection.Image == "" {
-		return "", fmt.Errorf("collection has no image; %s", err)
+		return "", fmt.Errorf("%w; collection has no image", errImageNotFound)
 	}
 	return "/ipfs/" + collection.Image, nil
 }
+
+// imageError responds to the image request failed with the given error.
+// The error details are logged only, the client gets the status code.
+func imageError(log logger.Logger, w http.ResponseWriter, req *http.Request, err error) {
+	status := imageErrorStatus(err)
+	if status >= 500 {
+		log.Errorf("image request %s failed; %s", req.URL.Path, err.Error())
+	} else {
+		log.Debugf("image request %s refused; %s", req.URL.Path, err.Error())
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(http.StatusText(status)))
+}
+
+// imageErrorStatus decides the HTTP status code of the given image request error.
+// Failures of remote image sources (IPFS, HTTP) are reported as gateway errors.
+func imageErrorStatus(err error) int {
+	var ne net.Error
+	switch {
+	case errors.Is(err, errInvalidImagePath):
+		return http.StatusBadRequest
+	case errors.Is(err, errImageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errImageLookup):
+		return http.StatusInternalServerError
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne) && ne.Timeout():
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
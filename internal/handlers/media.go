@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"artion-api-graphql/internal/config"
+	"artion-api-graphql/internal/logger"
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// MediaHandler builds a HTTP handler function for original (full resolution) images and videos.
+// The same resolvers as for the image thumbnails are used. Originals are kept in the derived media store,
+// so subsequent (range) requests do not download them again.
+func MediaHandler(cfg *config.Config, log logger.Logger, resolver func(path string) (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("Panic in MediaHandler handler; %s", r)
+				w.WriteHeader(500)
+				_, err := w.Write([]byte("Request handling failed"))
+				if err != nil {
+					log.Errorf("could not write response; %s", err.Error())
+				}
+			}
+		}()
+
+		uri, err := resolver(req.URL.Path)
+		if err != nil {
+			imageError(log, w, req, err)
+			return
+		}
+
+		media, err := repository.R().GetOriginalMedia(uri)
+		if err != nil {
+			imageError(log, w, req, err)
+			return
+		}
+		if media == nil || len(media.Data) == 0 {
+			imageError(log, w, req, errImageNotFound)
+			return
+		}
+
+		serveMedia(w, req, media, cfg.Media.OriginalMaxAge)
+	})
+}
+
+// PreviewHandler builds a HTTP handler function for short preview clips of videos.
+func PreviewHandler(cfg *config.Config, log logger.Logger, resolver func(path string) (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("Panic in PreviewHandler handler; %s", r)
+				w.WriteHeader(500)
+				_, err := w.Write([]byte("Request handling failed"))
+				if err != nil {
+					log.Errorf("could not write response; %s", err.Error())
+				}
+			}
+		}()
+
+		uri, err := resolver(req.URL.Path)
+		if err != nil {
+			imageError(log, w, req, err)
+			return
+		}
+
+		clip, err := repository.R().GetImageThumbnailVariant(uri, types.PreviewVariant)
+		if err != nil {
+			imageError(log, w, req, err)
+			return
+		}
+		if clip == nil || len(clip.Data) == 0 {
+			imageError(log, w, req, errImageNotFound)
+			return
+		}
+
+		serveMedia(w, req, clip, cfg.Media.ThumbnailMaxAge)
+	})
+}
+
+// serveMedia writes the given image or video into the response.
+// The content is identified by the hash of the data (ETag) kept with stored media, so conditional requests
+// can be answered by 304; range requests are served as partial content.
+func serveMedia(w http.ResponseWriter, req *http.Request, media *types.Image, maxAge time.Duration) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%s"`, media.ContentTag()))
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(maxAge.Seconds())))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// SVG images are sanitized; this makes sure nothing active slips through if opened directly
+	if media.Type == types.ImageTypeSvg {
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src data:")
+	}
+	if media.Type.Mimetype() == "" {
+		// do not let the server guess the type
+		w.Header()["Content-Type"] = nil
+	} else {
+		w.Header().Set("Content-Type", media.Type.Mimetype())
+	}
+
+	// conditional requests and ranges are handled by the standard library
+	http.ServeContent(w, req, "", media.Modified, bytes.NewReader(media.Data))
+}
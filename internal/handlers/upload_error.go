@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"artion-api-graphql/internal/logger"
+	"artion-api-graphql/internal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// codes of upload errors reported to clients
+const (
+	uploadErrUnauthorized     = "UNAUTHORIZED"
+	uploadErrInvalidRequest   = "INVALID_REQUEST"
+	uploadErrTooLarge         = "TOO_LARGE"
+	uploadErrUnsupportedMedia = "UNSUPPORTED_MEDIA"
+	uploadErrInvalidMedia     = "INVALID_MEDIA"
+	uploadErrLimitExceeded    = "MEDIA_LIMIT_EXCEEDED"
+	uploadErrInvalidMetadata  = "INVALID_METADATA"
+	uploadErrQuotaExceeded    = "QUOTA_EXCEEDED"
+	uploadErrFailed           = "UPLOAD_FAILED"
+)
+
+// uploadError represents a failed upload reported to the client as a structured JSON response.
+type uploadError struct {
+	status  int
+	Code    string                     `json:"code"`
+	Message string                     `json:"message"`
+	Fields  []types.MetadataFieldError `json:"fields,omitempty"`
+}
+
+// newUploadError creates a new upload error of the given HTTP status and error code.
+func newUploadError(status int, code string, format string, args ...interface{}) *uploadError {
+	return &uploadError{
+		status:  status,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Error returns the text of the error.
+func (e *uploadError) Error() string {
+	return e.Message
+}
+
+// writeUploadError responds to the upload request failed with the given error.
+// Errors not caused by the client are reported as generic upload failures.
+func writeUploadError(log logger.Logger, w http.ResponseWriter, err error) {
+	var ue *uploadError
+	if !errors.As(err, &ue) {
+		ue = newUploadError(http.StatusInternalServerError, uploadErrFailed, "%s", err.Error())
+	}
+
+	if ue.status >= 500 {
+		log.Errorf("upload failed; %s", err.Error())
+	} else {
+		log.Infof("upload refused; %s", err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(ue.status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error *uploadError `json:"error"`
+	}{Error: ue})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"artion-api-graphql/internal/auth"
+	"artion-api-graphql/internal/config"
 	"artion-api-graphql/internal/logger"
 	"artion-api-graphql/internal/repository"
 	"artion-api-graphql/internal/types"
@@ -10,99 +11,184 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
-type uploadProcessor func(identity common.Address, image types.Image, req *http.Request) (string, error)
+const (
+	// uploadMemoryLimit is the max size of the multipart form kept in memory, the rest goes to temporary files.
+	uploadMemoryLimit = 10 * 1024 * 1024
 
-// UploadImageHandler builds a HTTP handler function for images (tokens, user avatars) upload.
-func UploadImageHandler(log logger.Logger, process uploadProcessor) http.Handler {
+	// uploadFormOverhead is the allowance for the multipart form fields (metadata JSON, boundaries)
+	// on top of the uploaded files.
+	uploadFormOverhead = 1024 * 1024
+)
+
+// uploadedMedia represents a media file received by an upload endpoint and validated against its limits.
+type uploadedMedia struct {
+	data     []byte
+	mimetype string
+	info     types.MediaInfo
+
+	// preview is the optional still image representing video and audio media
+	preview *uploadedMedia
+}
+
+// kind provides the kind of the uploaded media.
+func (um *uploadedMedia) kind() types.MediaKind {
+	return types.MediaKindFromMimetype(um.mimetype)
+}
+
+// image provides the uploaded media as an image.
+func (um *uploadedMedia) image() types.Image {
+	return types.Image{
+		Data: um.data,
+		Type: types.ImageTypeFromMimetype(um.mimetype),
+	}
+}
+
+type uploadProcessor func(identity common.Address, media *uploadedMedia, req *http.Request) (string, error)
+
+// UploadImageHandler builds a HTTP handler function for images and media (tokens, user avatars) upload.
+// The uploaded media is validated against the given limits before it's processed.
+func UploadImageHandler(limits config.UploadLimits, log logger.Logger, process uploadProcessor) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		defer func() {
 			if r := recover(); r != nil {
 				log.Errorf("Panic in UploadImageHandler handler; %v", r)
-				w.WriteHeader(500)
-				fmt.Fprintf(w, "Request handling failed; %v", r)
+				writeUploadError(log, w, fmt.Errorf("request handling failed; %v", r))
 			}
 		}()
 
-		statusCode, response := processImageUpload(req, process, log)
-		w.WriteHeader(statusCode)
-		w.Write([]byte(response))
+		response, err := processImageUpload(w, req, limits, process)
+		if err != nil {
+			writeUploadError(log, w, err)
+			return
+		}
+		log.Info("Uploaded OK")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(response))
 	})
 }
 
-func processImageUpload(req *http.Request, process uploadProcessor, log logger.Logger) (statusCode int, response string) {
+func processImageUpload(w http.ResponseWriter, req *http.Request, limits config.UploadLimits, process uploadProcessor) (string, error) {
 	identity, err := auth.GetIdentityOrErr(req.Context())
 	if err != nil {
-		return 401, "Unauthorized"
+		return "", newUploadError(http.StatusUnauthorized, uploadErrUnauthorized, "Unauthorized")
 	}
 
-	err = req.ParseMultipartForm(10 * 1024 * 1024) // max 10MB
+	// the media and its preview image may be sent
+	req.Body = http.MaxBytesReader(w, req.Body, 2*limits.MaxSize+uploadFormOverhead)
+	if err := req.ParseMultipartForm(uploadMemoryLimit); err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			return "", newUploadError(http.StatusRequestEntityTooLarge, uploadErrTooLarge, "upload exceeds %d bytes", limits.MaxSize)
+		}
+		return "", newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "unable to parse multipart/form-data; %s", err)
+	}
+
+	media, err := readUploadedMedia(req, "file", limits)
 	if err != nil {
-		return 500, "Unable to parse multipart/form-data"
+		return "", err
+	}
+	if media == nil {
+		return "", newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "multipart/form-data file \"file\" missing")
 	}
 
-	file, _, err := req.FormFile("file")
-	defer file.Close()
+	// the preview can only be a still image
+	previewLimits := limits
+	previewLimits.Media = []string{"image"}
+	if media.preview, err = readUploadedMedia(req, "preview", previewLimits); err != nil {
+		return "", err
+	}
+
+	return process(*identity, media, req)
+}
+
+// readUploadedMedia reads the uploaded file of the given form field and validates it against the limits.
+// Nil is returned if the file has not been sent.
+func readUploadedMedia(req *http.Request, field string, limits config.UploadLimits) (*uploadedMedia, error) {
+	file, header, err := req.FormFile(field)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	}
 	if err != nil {
-		return 500, "Unable to parse multipart/form-data file \"file\""
+		return nil, newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "unable to read multipart/form-data file %q; %s", field, err)
+	}
+	defer file.Close()
+
+	if header.Size > limits.MaxSize {
+		return nil, newUploadError(http.StatusRequestEntityTooLarge, uploadErrTooLarge, "file %q exceeds %d bytes", field, limits.MaxSize)
 	}
 
 	buf := new(bytes.Buffer)
 	if _, err := io.Copy(buf, file); err != nil {
-		return 500, "Unable to read uploaded file"
+		return nil, newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "unable to read uploaded file %q; %s", field, err)
 	}
-	content := buf.Bytes()
+	return validateUploadedMedia(field, buf.Bytes(), limits)
+}
 
-	contentType := http.DetectContentType(content)
-	log.Infof("Content type from DetectContentType: %s", contentType)
-	imgType := types.ImageTypeFromMimetype(contentType)
-	if imgType == types.ImageTypeUnknown {
-		return 500, "Unrecognized image type " + contentType
+// validateUploadedMedia validates the content of the uploaded file against the limits.
+func validateUploadedMedia(field string, data []byte, limits config.UploadLimits) (*uploadedMedia, error) {
+	media := uploadedMedia{data: data, mimetype: detectUploadMimetype(data)}
+	if !isMediaAllowed(media.mimetype, limits.Media) {
+		return nil, newUploadError(http.StatusUnsupportedMediaType, uploadErrUnsupportedMedia, "file %q of type %s is not accepted", field, media.mimetype)
 	}
 
-	image := types.Image{
-		Data: content,
-		Type: imgType,
+	info, err := repository.R().ProbeMedia(media.data, media.mimetype)
+	if err != nil {
+		return nil, newUploadError(http.StatusUnprocessableEntity, uploadErrInvalidMedia, "file %q is not a valid %s; %s", field, media.mimetype, err)
+	}
+	if err := checkMediaLimits(info, limits); err != nil {
+		return nil, newUploadError(http.StatusUnprocessableEntity, uploadErrLimitExceeded, "file %q %s", field, err)
+	}
+	if err := repository.R().VerifyMedia(media.data, media.mimetype); err != nil {
+		return nil, newUploadError(http.StatusUnprocessableEntity, uploadErrInvalidMedia, "file %q is not a valid %s; %s", field, media.mimetype, err)
 	}
 
-	response, err = process(*identity, image, req)
-	if err != nil {
-		log.Errorf("upload failed; %s", err)
-		return 500, err.Error()
+	media.info = *info
+	return &media, nil
+}
+
+// checkMediaLimits checks the media dimensions and duration against the limits.
+func checkMediaLimits(info *types.MediaInfo, limits config.UploadLimits) error {
+	if (limits.MaxWidth > 0 && info.Width > limits.MaxWidth) || (limits.MaxHeight > 0 && info.Height > limits.MaxHeight) {
+		return fmt.Errorf("dimensions %dx%d exceed %dx%d", info.Width, info.Height, limits.MaxWidth, limits.MaxHeight)
 	}
-	log.Info("Uploaded OK")
-	return 200, response
+	if limits.MaxDuration > 0 && info.Duration > limits.MaxDuration {
+		return fmt.Errorf("duration %s exceeds %s", info.Duration.Round(time.Second), limits.MaxDuration)
+	}
+	return nil
 }
 
-func StoreUserAvatar(identity common.Address, image types.Image, req *http.Request) (string, error) {
-	err := repository.R().UploadUserAvatar(identity, image)
+func StoreUserAvatar(identity common.Address, media *uploadedMedia, req *http.Request) (string, error) {
+	err := repository.R().UploadUserAvatar(identity, media.image())
 	if err != nil {
 		return "", fmt.Errorf("user avatar upload failed; %s", err)
 	}
 	return "OK", nil
 }
 
-func StoreUserBanner(identity common.Address, image types.Image, req *http.Request) (string, error) {
-	err := repository.R().UploadUserBanner(identity, image)
+func StoreUserBanner(identity common.Address, media *uploadedMedia, req *http.Request) (string, error) {
+	err := repository.R().UploadUserBanner(identity, media.image())
 	if err != nil {
 		return "", fmt.Errorf("user banner upload failed; %s", err)
 	}
 	return "OK", nil
 }
 
-func StoreToken(identity common.Address, image types.Image, req *http.Request) (string, error) {
+func StoreToken(identity common.Address, media *uploadedMedia, req *http.Request) (string, error) {
 	metadataJson := req.FormValue("metadata")
 	if metadataJson == "" {
-		return "", fmt.Errorf("no token metadata sent")
+		return "", newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "no token metadata sent")
+	}
+	if fields := types.ValidateJsonMetadata([]byte(metadataJson)); len(fields) > 0 {
+		ue := newUploadError(http.StatusUnprocessableEntity, uploadErrInvalidMetadata, "token metadata do not match the metadata schema")
+		ue.Fields = fields
+		return "", ue
 	}
 	metadata, err := types.DecodeJsonMetadata([]byte(metadataJson))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse json metadata; %s", err)
-	}
-	if metadata.Name == "" {
-		return "", fmt.Errorf("token name not defined in json metadata")
+		return "", newUploadError(http.StatusUnprocessableEntity, uploadErrInvalidMetadata, "failed to parse json metadata; %s", err)
 	}
 
 	// override author address
@@ -111,9 +197,34 @@ func StoreToken(identity common.Address, image types.Image, req *http.Request) (
 	// override createdAt
 	metadata.Properties.CreatedAt = time.Now().UTC().Format(time.RFC3339)
 
-	uri, err := repository.R().UploadTokenData(*metadata, image)
+	image, animation, err := tokenMedia(media)
+	if err != nil {
+		return "", err
+	}
+
+	uri, err := repository.R().UploadTokenData(identity, *metadata, image, animation)
 	if err != nil {
 		return "", fmt.Errorf("token data upload failed; %s", err)
 	}
 	return uri, nil
 }
+
+// tokenMedia decides the image and the rich media (animation) of the token from the uploaded media.
+// Video and audio are the token rich media and the preview is its image; MP4 video without a preview
+// is used as the image directly.
+func tokenMedia(media *uploadedMedia) (image types.Image, animation []byte, err error) {
+	if media.kind() == types.MediaKindImage {
+		if media.preview != nil {
+			return types.Image{}, nil, newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "preview is only accepted for video and audio")
+		}
+		return media.image(), nil, nil
+	}
+
+	if media.preview != nil {
+		return media.preview.image(), media.data, nil
+	}
+	if media.image().Type != types.ImageTypeUnknown {
+		return media.image(), nil, nil
+	}
+	return types.Image{}, nil, newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "preview image is required for %s", media.mimetype)
+}
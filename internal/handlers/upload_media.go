@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"artion-api-graphql/internal/types"
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// uploadMimetypes lists the mimetypes of media accepted by upload endpoints;
+// the endpoint limits decide which kinds of them are accepted.
+var uploadMimetypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+	"video/mp4":  true,
+	"video/webm": true,
+	"audio/mpeg": true,
+	"audio/wav":  true,
+	"audio/ogg":  true,
+	"audio/flac": true,
+}
+
+// detectUploadMimetype detects the mimetype of the uploaded media from its content.
+// The content sniffing of the standard library is extended by audio formats it does not know.
+func detectUploadMimetype(data []byte) string {
+	mimetype := strings.Split(http.DetectContentType(data), ";")[0]
+	switch {
+	case mimetype == "audio/wave":
+		return "audio/wav"
+	case mimetype == "application/ogg":
+		return "audio/ogg"
+	case mimetype != "application/octet-stream":
+		return mimetype
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case len(data) > 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return "audio/mpeg" // MPEG audio frame sync without ID3 tag
+	}
+	return mimetype
+}
+
+// isMediaAllowed checks if the media of the mimetype is one of the accepted kinds of media.
+func isMediaAllowed(mimetype string, kinds []string) bool {
+	if !uploadMimetypes[mimetype] {
+		return false
+	}
+
+	kind := types.MediaKindFromMimetype(mimetype).String()
+	for _, k := range kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"artion-api-graphql/internal/auth"
+	"artion-api-graphql/internal/config"
+	"artion-api-graphql/internal/logger"
+	"artion-api-graphql/internal/repository"
+	"artion-api-graphql/internal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// uploadOffsetHeader is the HTTP header carrying the offset of the uploaded chunk
+// and the number of bytes received so far in responses.
+const uploadOffsetHeader = "Upload-Offset"
+
+// UploadSessionHandler builds a HTTP handler function for resumable chunked uploads of large media files.
+// The handler is mounted on the prefix path and serves:
+//
+//	POST   {prefix}             starts a new upload of {"size": <bytes>}
+//	GET    {prefix}/{id}        provides the upload status
+//	PATCH  {prefix}/{id}        appends the chunk sent in the body at the Upload-Offset header
+//	POST   {prefix}/{id}/finish validates and processes the complete upload like a regular upload
+//	DELETE {prefix}/{id}        aborts the upload
+func UploadSessionHandler(prefix string, limits config.UploadLimits, chunkSize int64, log logger.Logger, process uploadProcessor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("Panic in UploadSessionHandler handler; %v", r)
+				writeUploadError(log, w, fmt.Errorf("request handling failed; %v", r))
+			}
+		}()
+
+		identity, err := auth.GetIdentityOrErr(req.Context())
+		if err != nil {
+			writeUploadError(log, w, newUploadError(http.StatusUnauthorized, uploadErrUnauthorized, "Unauthorized"))
+			return
+		}
+
+		path := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, prefix), "/"), "/")
+		switch {
+		case path[0] == "" && req.Method == http.MethodPost:
+			err = createUploadSession(w, req, *identity, limits)
+		case len(path) == 1 && req.Method == http.MethodGet:
+			err = uploadSessionStatus(w, *identity, path[0])
+		case len(path) == 1 && req.Method == http.MethodPatch:
+			err = appendUploadChunk(w, req, *identity, path[0], chunkSize)
+		case len(path) == 1 && req.Method == http.MethodDelete:
+			err = abortUploadSession(w, *identity, path[0])
+		case len(path) == 2 && path[1] == "finish" && req.Method == http.MethodPost:
+			err = finishUploadSession(w, req, *identity, path[0], limits, log, process)
+		default:
+			err = newUploadError(http.StatusNotFound, uploadErrInvalidRequest, "unknown upload request %s %s", req.Method, req.URL.Path)
+		}
+
+		if err != nil {
+			writeUploadError(log, w, err)
+		}
+	})
+}
+
+// createUploadSession starts a new resumable upload.
+func createUploadSession(w http.ResponseWriter, req *http.Request, identity common.Address, limits config.UploadLimits) error {
+	var body struct {
+		Size int64 `json:"size"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1024)).Decode(&body); err != nil {
+		return newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "invalid upload request; %s", err)
+	}
+	if body.Size <= 0 {
+		return newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "upload size must be positive")
+	}
+	if body.Size > limits.MaxSize {
+		return newUploadError(http.StatusRequestEntityTooLarge, uploadErrTooLarge, "upload exceeds %d bytes", limits.MaxSize)
+	}
+
+	us, err := repository.R().CreateUploadSession(identity, body.Size)
+	switch {
+	case errors.Is(err, repository.ErrUploadTooManySessions):
+		return newUploadError(http.StatusTooManyRequests, uploadErrQuotaExceeded, "too many unfinished uploads, finish or abort some of them first")
+	case errors.Is(err, repository.ErrUploadQuotaExceeded):
+		return newUploadError(http.StatusRequestEntityTooLarge, uploadErrQuotaExceeded, "unfinished uploads would exceed the total size allowed")
+	case err != nil:
+		return fmt.Errorf("can not start upload; %s", err)
+	}
+	writeUploadSession(w, http.StatusCreated, us)
+	return nil
+}
+
+// uploadSessionStatus responds with the current state of the upload so the client can resume it.
+func uploadSessionStatus(w http.ResponseWriter, identity common.Address, id string) error {
+	us, err := ownUploadSession(identity, id)
+	if err != nil {
+		return err
+	}
+	writeUploadSession(w, http.StatusOK, us)
+	return nil
+}
+
+// appendUploadChunk adds the chunk sent in the request body to the upload.
+func appendUploadChunk(w http.ResponseWriter, req *http.Request, identity common.Address, id string, chunkSize int64) error {
+	if _, err := ownUploadSession(identity, id); err != nil {
+		return err
+	}
+
+	offset, err := strconv.ParseInt(req.Header.Get(uploadOffsetHeader), 10, 64)
+	if err != nil {
+		return newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "invalid %s header", uploadOffsetHeader)
+	}
+	if req.ContentLength > chunkSize {
+		return newUploadError(http.StatusRequestEntityTooLarge, uploadErrTooLarge, "chunk exceeds %d bytes", chunkSize)
+	}
+
+	us, err := repository.R().AppendUploadChunk(id, offset, http.MaxBytesReader(w, req.Body, chunkSize))
+	switch {
+	case err == nil:
+		writeUploadSession(w, http.StatusOK, us)
+		return nil
+	case errors.Is(err, repository.ErrUploadSessionNotFound):
+		return newUploadError(http.StatusNotFound, uploadErrInvalidRequest, "upload %s not found", id)
+	case errors.Is(err, repository.ErrUploadSessionBusy):
+		return newUploadError(http.StatusConflict, uploadErrInvalidRequest, "another chunk of upload %s is being received", id)
+	case errors.Is(err, repository.ErrUploadOffsetMismatch):
+		w.Header().Set(uploadOffsetHeader, strconv.FormatInt(us.Offset, 10))
+		return newUploadError(http.StatusConflict, uploadErrInvalidRequest, "chunk offset %d does not match the upload offset %d", offset, us.Offset)
+	case errors.Is(err, repository.ErrUploadSizeExceeded):
+		w.Header().Set(uploadOffsetHeader, strconv.FormatInt(us.Offset, 10))
+		return newUploadError(http.StatusRequestEntityTooLarge, uploadErrTooLarge, "chunk exceeds the declared upload size %d", us.Size)
+	case us != nil:
+		// the received part of the chunk is kept, the client resumes from the reported offset
+		w.Header().Set(uploadOffsetHeader, strconv.FormatInt(us.Offset, 10))
+		return newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "chunk not received completely; %s", err)
+	}
+	return fmt.Errorf("can not store upload chunk; %s", err)
+}
+
+// abortUploadSession drops the upload and the data received.
+func abortUploadSession(w http.ResponseWriter, identity common.Address, id string) error {
+	if _, err := ownUploadSession(identity, id); err != nil {
+		return err
+	}
+	if err := repository.R().RemoveUploadSession(id); err != nil {
+		if errors.Is(err, repository.ErrUploadSessionBusy) {
+			return newUploadError(http.StatusConflict, uploadErrInvalidRequest, "a chunk of upload %s is being received", id)
+		}
+		return fmt.Errorf("can not remove upload %s; %s", id, err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// finishUploadSession validates the complete upload and processes it along with the form sent
+// the same way as a regular upload. The upload is removed once processed or if the media is refused.
+func finishUploadSession(w http.ResponseWriter, req *http.Request, identity common.Address, id string, limits config.UploadLimits, log logger.Logger, process uploadProcessor) error {
+	us, err := ownUploadSession(identity, id)
+	if err != nil {
+		return err
+	}
+	if !us.IsComplete() {
+		return newUploadError(http.StatusConflict, uploadErrInvalidRequest, "upload %s is not complete, %d of %d bytes received", id, us.Offset, us.Size)
+	}
+
+	// the form carries metadata and the optional preview image
+	req.Body = http.MaxBytesReader(w, req.Body, limits.MaxSize+uploadFormOverhead)
+	if err := req.ParseMultipartForm(uploadMemoryLimit); err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			return newUploadError(http.StatusRequestEntityTooLarge, uploadErrTooLarge, "upload exceeds %d bytes", limits.MaxSize)
+		}
+		return newUploadError(http.StatusBadRequest, uploadErrInvalidRequest, "unable to parse multipart/form-data; %s", err)
+	}
+
+	data, err := repository.R().UploadSessionData(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUploadSessionBusy) {
+			return newUploadError(http.StatusConflict, uploadErrInvalidRequest, "a chunk of upload %s is being received", id)
+		}
+		return fmt.Errorf("can not read upload %s; %s", id, err)
+	}
+
+	media, err := validateUploadedMedia("file", data, limits)
+	if err != nil {
+		removeUploadSession(log, id)
+		return err
+	}
+
+	previewLimits := limits
+	previewLimits.Media = []string{"image"}
+	if media.preview, err = readUploadedMedia(req, "preview", previewLimits); err != nil {
+		return err
+	}
+
+	response, err := process(identity, media, req)
+	if err != nil {
+		return err
+	}
+	removeUploadSession(log, id)
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(response))
+	return nil
+}
+
+// ownUploadSession loads the upload session making sure it belongs to the given user.
+// Uploads of other users are reported as not found.
+func ownUploadSession(identity common.Address, id string) (*types.UploadSession, error) {
+	us, err := repository.R().UploadSession(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUploadSessionNotFound) {
+			return nil, newUploadError(http.StatusNotFound, uploadErrInvalidRequest, "upload %s not found", id)
+		}
+		return nil, fmt.Errorf("can not load upload %s; %s", id, err)
+	}
+	if us.Owner != identity {
+		return nil, newUploadError(http.StatusNotFound, uploadErrInvalidRequest, "upload %s not found", id)
+	}
+	return us, nil
+}
+
+// removeUploadSession drops the upload which is no longer needed.
+func removeUploadSession(log logger.Logger, id string) {
+	if err := repository.R().RemoveUploadSession(id); err != nil {
+		log.Errorf("can not remove upload %s; %s", id, err)
+	}
+}
+
+// writeUploadSession responds with the upload session state.
+func writeUploadSession(w http.ResponseWriter, status int, us *types.UploadSession) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(us.Offset, 10))
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(us)
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"artion-api-graphql/internal/types"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/disintegration/imaging"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+	"image"
+	"image/draw"
+	"image/gif"
+)
+
+const (
+	// animatedThumbnailMaxFrames is the max number of frames of an animation we keep animated;
+	// longer animations get a static thumbnail.
+	animatedThumbnailMaxFrames = 500
+
+	// videoPreviewDuration is the duration of video preview clips in seconds.
+	videoPreviewDuration = 4
+
+	// videoPreviewFps is the frame rate of video preview clips.
+	videoPreviewFps = 15
+)
+
+// isAnimatedFormat checks if the thumbnail output format can keep animations.
+func isAnimatedFormat(format types.ImageType) bool {
+	return format == types.ImageTypeUnknown || format == types.ImageTypeGif || format == types.ImageTypeWebp
+}
+
+// createAnimatedGifThumbnail resizes all the frames of an animated GIF.
+// The flag is false if the GIF is not animated and a static thumbnail should be used instead.
+func createAnimatedGifThumbnail(input types.Image, v types.ThumbnailVariant) (output types.Image, ok bool, err error) {
+	src, err := gif.DecodeAll(bytes.NewReader(input.Data))
+	if err != nil || len(src.Image) < 2 || len(src.Image) > animatedThumbnailMaxFrames {
+		return types.Image{}, false, nil
+	}
+
+	// frames may cover only a part of the logical screen, so they are composed on a canvas
+	bounds := image.Rect(0, 0, src.Config.Width, src.Config.Height)
+	if bounds.Empty() {
+		bounds = src.Image[0].Bounds()
+	}
+	canvas := image.NewNRGBA(bounds)
+
+	out := gif.GIF{
+		Image:     make([]*image.Paletted, 0, len(src.Image)),
+		Delay:     src.Delay,
+		LoopCount: src.LoopCount,
+	}
+	for i, frame := range src.Image {
+		var disposal byte
+		if i < len(src.Disposal) {
+			disposal = src.Disposal[i]
+		}
+
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = imaging.Clone(canvas)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		small := imaging.Fit(canvas, v.Size, v.Size, imaging.Linear)
+		pf := image.NewPaletted(small.Bounds(), frame.Palette)
+		draw.FloydSteinberg.Draw(pf, small.Bounds(), small, small.Bounds().Min)
+		out.Image = append(out.Image, pf)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	var writer bytes.Buffer
+	if err := gif.EncodeAll(&writer, &out); err != nil {
+		return types.Image{}, true, fmt.Errorf("gif encoding failed; %s", err)
+	}
+
+	thumb := types.Image{
+		Data: writer.Bytes(),
+		Type: types.ImageTypeGif,
+	}
+	if v.Format == types.ImageTypeWebp {
+		thumb, err = encodeAnimatedWebp(thumb)
+	}
+	return thumb, true, err
+}
+
+// encodeAnimatedWebp converts the given animated GIF into animated WebP using ffmpeg.
+func encodeAnimatedWebp(input types.Image) (output types.Image, err error) {
+	writer := bytes.NewBuffer(nil)
+	err = ffmpeg.
+		Input("pipe:", ffmpeg.KwArgs{"f": "gif"}).
+		Output("pipe:", ffmpeg.KwArgs{"format": "webp", "vcodec": "libwebp", "quality": 75, "loop": 0}).
+		WithInput(bytes.NewReader(input.Data)).
+		WithOutput(writer).
+		Run()
+	if err != nil {
+		return types.Image{}, fmt.Errorf("animated webp encoding failed; %s", err)
+	}
+	return types.Image{
+		Data: writer.Bytes(),
+		Type: types.ImageTypeWebp,
+	}, nil
+}
+
+// createAnimatedWebpThumbnail resizes the animated WebP image using ffmpeg, the animation is kept.
+// Animations already fitting the requested size are served as-is, if WebP output is acceptable.
+func createAnimatedWebpThumbnail(input types.Image, v types.ThumbnailVariant) (output types.Image, err error) {
+	if v.Format != types.ImageTypeGif {
+		if w, h, ok := webpCanvasSize(input.Data); ok && w <= v.Size && h <= v.Size {
+			return types.Image{Data: input.Data, Type: types.ImageTypeWebp}, nil
+		}
+	}
+
+	kw := ffmpeg.KwArgs{
+		"vf":     fmt.Sprintf("scale=w=%d:h=%d:force_original_aspect_ratio=decrease", v.Size, v.Size),
+		"format": "webp",
+		"vcodec": "libwebp",
+		"loop":   0,
+	}
+	outType := types.ImageTypeWebp
+	if v.Format == types.ImageTypeGif {
+		kw = ffmpeg.KwArgs{"vf": kw["vf"], "format": "gif", "loop": 0}
+		outType = types.ImageTypeGif
+	}
+
+	writer := bytes.NewBuffer(nil)
+	err = ffmpeg.
+		Input("pipe:", ffmpeg.KwArgs{"f": "webp_pipe"}).
+		Output("pipe:", kw).
+		WithInput(bytes.NewReader(input.Data)).
+		WithOutput(writer).
+		Run()
+	if err != nil || writer.Len() == 0 {
+		return types.Image{}, fmt.Errorf("animated webp resizing failed; %v", err)
+	}
+	return types.Image{
+		Data: writer.Bytes(),
+		Type: outType,
+	}, nil
+}
+
+// webpFirstFrame extracts the first frame of the animated WebP image as a still WebP image,
+// which can be decoded by the WebP decoder of the standard library.
+func webpFirstFrame(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("invalid webp image")
+	}
+
+	for pos := 12; pos+8 <= len(data); {
+		fourcc := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		if size < 0 || pos+8+size > len(data) {
+			break
+		}
+		if fourcc == "ANMF" && size >= 16 {
+			return webpStill(data[pos+8:pos+8+size], webpDimension(data[pos+14:]), webpDimension(data[pos+17:]))
+		}
+		pos += 8 + size + size&1
+	}
+	return nil, fmt.Errorf("webp animation frame not found")
+}
+
+// webpStill builds a still WebP image of the given size from the animation frame payload.
+func webpStill(frame []byte, width int, height int) ([]byte, error) {
+	var alpha, bitstream []byte
+	for pos := 16; pos+8 <= len(frame); {
+		size := int(binary.LittleEndian.Uint32(frame[pos+4 : pos+8]))
+		if size < 0 || pos+8+size > len(frame) {
+			break
+		}
+		chunk := frame[pos : pos+8+size]
+		switch string(frame[pos : pos+4]) {
+		case "ALPH":
+			alpha = chunk
+		case "VP8 ", "VP8L":
+			bitstream = chunk
+		}
+		pos += 8 + size + size&1
+	}
+	if bitstream == nil {
+		return nil, fmt.Errorf("webp frame bitstream not found")
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	if alpha != nil {
+		// lossy frames keep transparency in a separate chunk announced by the extended header
+		vp8x := make([]byte, 18)
+		copy(vp8x, "VP8X")
+		binary.LittleEndian.PutUint32(vp8x[4:], 10)
+		vp8x[8] = 0x10
+		putWebpDimension(vp8x[12:], width)
+		putWebpDimension(vp8x[15:], height)
+		body.Write(vp8x)
+		writeWebpChunk(&body, alpha)
+	}
+	writeWebpChunk(&body, bitstream)
+
+	out := make([]byte, 8, 8+body.Len())
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(body.Len()))
+	return append(out, body.Bytes()...), nil
+}
+
+// writeWebpChunk writes the chunk padded to even size.
+func writeWebpChunk(buf *bytes.Buffer, chunk []byte) {
+	buf.Write(chunk)
+	if len(chunk)&1 != 0 {
+		buf.WriteByte(0)
+	}
+}
+
+// webpDimension decodes the 24 bit "size minus one" dimension of WebP headers.
+func webpDimension(b []byte) int {
+	return (int(b[0]) | int(b[1])<<8 | int(b[2])<<16) + 1
+}
+
+// putWebpDimension encodes the 24 bit "size minus one" dimension of WebP headers.
+func putWebpDimension(b []byte, v int) {
+	v--
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// webpCanvasSize provides the canvas dimensions from the extended header (VP8X) of the WebP image.
+func webpCanvasSize(data []byte) (width int, height int, ok bool) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" || string(data[12:16]) != "VP8X" {
+		return 0, 0, false
+	}
+	return webpDimension(data[24:]), webpDimension(data[27:]), true
+}
+
+// isAnimatedWebp checks the animation flag in the extended header (VP8X) of the WebP image.
+func isAnimatedWebp(data []byte) bool {
+	if len(data) < 21 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" || string(data[12:16]) != "VP8X" {
+		return false
+	}
+	return binary.LittleEndian.Uint32(data[16:20]) >= 10 && data[20]&0x02 != 0
+}
+
+// createVideoPreview creates a short, small and low-bitrate preview clip of the video.
+// The clip has no audio track and it's fragmented, so it can be written into a pipe.
+func createVideoPreview(input types.Image, v types.ThumbnailVariant) (output types.Image, err error) {
+	writer := bytes.NewBuffer(nil)
+	err = ffmpeg.
+		Input("pipe:").
+		Output("pipe:", ffmpeg.KwArgs{
+			"t":        videoPreviewDuration,
+			"an":       "",
+			"vf":       fmt.Sprintf("fps=%d,scale=w=%d:h=%d:force_original_aspect_ratio=decrease,scale=trunc(iw/2)*2:trunc(ih/2)*2", videoPreviewFps, v.Size, v.Size),
+			"vcodec":   "libx264",
+			"preset":   "veryfast",
+			"crf":      32,
+			"pix_fmt":  "yuv420p",
+			"movflags": "frag_keyframe+empty_moov",
+			"format":   "mp4",
+		}).
+		WithInput(bytes.NewReader(input.Data)).
+		WithOutput(writer).
+		Run()
+	if err != nil {
+		return types.Image{}, fmt.Errorf("video preview failed; %s", err)
+	}
+	return types.Image{
+		Data: writer.Bytes(),
+		Type: types.ImageTypeMp4,
+	}, nil
+}
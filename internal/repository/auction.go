@@ -7,9 +7,14 @@ import (
 	"math/big"
 )
 
-// GetAuction provides the auction stored in the database, if available.
-func (p *Proxy) GetAuction(contract *common.Address, tokenID *big.Int) (*types.Auction, error) {
-	return p.db.GetAuction(contract, tokenID)
+// GetAuction provides the auction of the given auction contract stored in the database, if available.
+func (p *Proxy) GetAuction(auctionHall *common.Address, contract *common.Address, tokenID *big.Int) (*types.Auction, error) {
+	return p.db.GetAuction(auctionHall, contract, tokenID)
+}
+
+// LatestAuction provides the most recent auction of the token on any of the auction contracts, if available.
+func (p *Proxy) LatestAuction(contract *common.Address, tokenID *big.Int) (*types.Auction, error) {
+	return p.db.LatestAuction(contract, tokenID)
 }
 
 // StoreAuction adds the provided auction into the database.
@@ -28,16 +33,22 @@ func (p *Proxy) SetAuctionBidder(contract *common.Address, tokenID *big.Int, bid
 }
 
 // AuctionGetMinBid provides a minimal bid amount required to participate in the auction.
-func (p *Proxy) AuctionGetMinBid(contract *common.Address, tokenID *big.Int) (*big.Int, error) {
+// The auction address identifies the Auction contract version the auction runs on.
+func (p *Proxy) AuctionGetMinBid(auction *common.Address, contract *common.Address, tokenID *big.Int) (*big.Int, error) {
 	// get the highest bid
-	hb, err := p.rpc.AuctionHighestBidAmount(contract, tokenID)
+	hb, err := p.rpc.AuctionHighestBidAmount(auction, contract, tokenID)
 	if err != nil {
 		return nil, err
 	}
 
 	// for zero highest bid, we use min. bid instead
 	if 0 == new(big.Int).Cmp(hb) {
-		return p.rpc.AuctionMinimalBidAmount(contract, tokenID), nil
+		return p.rpc.AuctionMinimalBidAmount(auction, contract, tokenID), nil
 	}
-	return new(big.Int).Add(hb, p.rpc.AuctionMinBidIncrement()), nil
+	return new(big.Int).Add(hb, p.rpc.AuctionMinBidIncrement(auction)), nil
+}
+
+// CloseAuctions closes all the open auctions of the given NFT token.
+func (p *Proxy) CloseAuctions(contract *common.Address, tokenID *big.Int, ts *types.Time) error {
+	return p.db.CloseAuctions(contract, tokenID, ts)
 }
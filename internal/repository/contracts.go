@@ -32,6 +32,27 @@ func (p *Proxy) ObservedContractAddressByType(t string) *common.Address {
 	return adr
 }
 
+// ObservedContractsByType provides list of all observed contracts of the given type.
+// The most recently deployed contract is the last one on the list.
+func (p *Proxy) ObservedContractsByType(t string) []*types.ObservedContract {
+	list, err := p.db.ObservedContractsByType(t)
+	if err != nil {
+		log.Errorf("contracts lookup failed for %s, %s", t, err.Error())
+		return nil
+	}
+	return list
+}
+
+// ObservedContractAddressesByType provides addresses of all observed contracts of the given type.
+func (p *Proxy) ObservedContractAddressesByType(t string) []common.Address {
+	list := p.ObservedContractsByType(t)
+	out := make([]common.Address, len(list))
+	for i, oc := range list {
+		out[i] = oc.Address
+	}
+	return out
+}
+
 // NFTContractsTypeMap provides a map of observed contract addresses to corresponding
 // contract type for ERC721 and ERC1155 contracts including their factory.
 // In case of a factory contract, we need the deployed NFT type for processing.
@@ -43,3 +64,42 @@ func (p *Proxy) NFTContractsTypeMap() map[common.Address]string {
 func (p *Proxy) MinObservedBlockNumber(def uint64) uint64 {
 	return p.db.MinObservedBlockNumber(def)
 }
+
+// setAuctionVersion decides the ABI version of the Auction contract from the configuration
+// and keeps it with the observed contract.
+func (p *Proxy) setAuctionVersion(oc *types.ObservedContract) {
+	ver := types.AuctionVersionV2
+	for _, adr := range cfg.Contracts.AuctionV1 {
+		if adr == oc.Address {
+			ver = types.AuctionVersionV1
+			break
+		}
+	}
+	if oc.Version == ver {
+		return
+	}
+
+	oc.Version = ver
+	if err := p.db.SetObservedContractVersion(oc.Address, ver); err != nil {
+		log.Errorf("version of auction contract %s not stored; %s", oc.Address.String(), err.Error())
+	}
+}
+
+// migrateContractRecords assigns listings, offers and auctions recorded without the address
+// of the Marketplace/Auction contract to the first deployed contract of the type.
+func (p *Proxy) migrateContractRecords() {
+	if list := p.ObservedContractsByType(types.ContractTypeMarketplace); len(list) > 0 {
+		if err := p.db.MigrateListingsMarketplace(list[0].Address); err != nil {
+			log.Panicf("listings migration failed; %s", err.Error())
+		}
+		if err := p.db.MigrateOffersMarketplace(list[0].Address); err != nil {
+			log.Panicf("offers migration failed; %s", err.Error())
+		}
+	}
+
+	if list := p.ObservedContractsByType(types.ContractTypeAuction); len(list) > 0 {
+		if err := p.db.MigrateAuctionsHall(list[0].Address); err != nil {
+			log.Panicf("auctions migration failed; %s", err.Error())
+		}
+	}
+}
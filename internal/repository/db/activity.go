@@ -9,6 +9,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
 )
 
 const (
@@ -21,6 +23,14 @@ const (
 	fiActivityFrom = "from"
 	fiActivityTo   = "to"
 	fiActivityType = "type"
+	fiActivityOrdinal = "index"
+	fiActivitySeq = "seq"
+
+	// coActivitySeqMigrationTimeout represents the timeout applied to the activity sequence migration.
+	coActivitySeqMigrationTimeout = 30 * time.Minute
+
+	// keyActivitySeqMigrated represents the system state record of the finished activity sequence migration.
+	keyActivitySeqMigrated = "activitySeqMigrated"
 )
 
 func (db *MongoDbBridge) StoreActivity(activity *types.Activity) error {
@@ -110,3 +120,80 @@ func (db *MongoDbBridge) listActivities(filter bson.D, cursor types.Cursor, coun
 	}
 	return &list, nil
 }
+
+// MigrateActivitySequence adds the sequence to activities stored before it has been introduced.
+// Metadata changes used to occupy the top of the log index range of their block; they are moved
+// to the metadata change ordinal index with the old position kept in the sequence.
+// The migration runs once; its completion is kept in the system state.
+func (db *MongoDbBridge) MigrateActivitySequence() error {
+	state := db.client.Database(db.dbName).Collection(coSystemStateCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), coActivitySeqMigrationTimeout)
+	defer cancel()
+
+	done, err := state.CountDocuments(ctx, bson.D{{Key: fieldId, Value: keyActivitySeqMigrated}})
+	if err != nil {
+		log.Errorf("can not check activity sequence state; %s", err.Error())
+		return err
+	}
+	if done > 0 {
+		return nil
+	}
+
+	col := db.client.Database(db.dbName).Collection(coActivities)
+	cur, err := col.Find(ctx, bson.D{
+		{Key: fiActivityType, Value: types.EvtMetadataChanged},
+		{Key: fiActivitySeq, Value: bson.D{{Key: "$exists", Value: false}}},
+	})
+	if err != nil {
+		log.Errorf("can not load metadata changes to migrate; %s", err.Error())
+		return err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	var count int
+	for cur.Next(ctx) {
+		var row struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Index int64              `bson:"index"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode metadata change; %s", err.Error())
+			return err
+		}
+
+		if _, err := col.UpdateOne(ctx, bson.D{{Key: fieldId, Value: row.ID}}, bson.D{{Key: "$set", Value: bson.D{
+			{Key: fiActivityOrdinal, Value: types.MetadataChangeOrdinalIndex(uint64(row.Index >> 12))},
+			{Key: fiActivitySeq, Value: row.Index&0xff + 1},
+		}}}); err != nil {
+			log.Errorf("can not migrate metadata change %s; %s", row.ID.Hex(), err.Error())
+			return err
+		}
+		count++
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate metadata changes to migrate; %s", err.Error())
+		return err
+	}
+
+	if _, err := col.UpdateMany(ctx,
+		bson.D{{Key: fiActivitySeq, Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: fiActivitySeq, Value: int64(0)}}}},
+	); err != nil {
+		log.Errorf("can not set activity sequence; %s", err.Error())
+		return err
+	}
+
+	if _, err := state.UpdateOne(ctx, bson.D{{Key: fieldId, Value: keyActivitySeqMigrated}}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: "time", Value: time.Now().UTC()}, {Key: "count", Value: count}}},
+	}, options.Update().SetUpsert(true)); err != nil {
+		log.Errorf("can not store activity sequence state; %s", err.Error())
+		return err
+	}
+	log.Noticef("activity sequence migrated, %d metadata changes moved", count)
+	return nil
+}
@@ -24,6 +24,9 @@ const (
 	// fiAuctionTokenId represents the name of the DB column storing NFT token ID.
 	fiAuctionTokenId = "token"
 
+	// fiAuctionCreated represents the name of the DB column storing the time the auction has been created.
+	fiAuctionCreated = "created"
+
 	// fiAuctionOwner represents the name of the DB column storing token owner.
 	fiAuctionOwner = "owner"
 
@@ -43,12 +46,12 @@ const (
 	fiAuctionLatestBidder = "last_bidder"
 )
 
-// GetAuction provides the auction stored in the database, if available.
-func (db *MongoDbBridge) GetAuction(contract *common.Address, tokenID *big.Int) (*types.Auction, error) {
+// GetAuction provides the auction of the given auction contract stored in the database, if available.
+func (db *MongoDbBridge) GetAuction(auctionHall *common.Address, contract *common.Address, tokenID *big.Int) (*types.Auction, error) {
 	// get the collection
 	col := db.client.Database(db.dbName).Collection(coAuctions)
 
-	sr := col.FindOne(context.Background(), bson.D{{Key: fieldId, Value: types.AuctionID(contract, tokenID)}})
+	sr := col.FindOne(context.Background(), bson.D{{Key: fieldId, Value: types.AuctionID(auctionHall, contract, tokenID)}})
 	if sr.Err() != nil {
 		if sr.Err() == mongo.ErrNoDocuments {
 			log.Debugf("auction for %s/%s not found",
@@ -71,6 +74,33 @@ func (db *MongoDbBridge) GetAuction(contract *common.Address, tokenID *big.Int)
 	return &row, nil
 }
 
+// LatestAuction provides the most recent auction of the token on any of the auction contracts, if available.
+func (db *MongoDbBridge) LatestAuction(contract *common.Address, tokenID *big.Int) (*types.Auction, error) {
+	col := db.client.Database(db.dbName).Collection(coAuctions)
+
+	sr := col.FindOne(context.Background(), bson.D{
+		{Key: fiAuctionContract, Value: *contract},
+		{Key: fiAuctionTokenId, Value: hexutil.Big(*tokenID)},
+	}, options.FindOne().SetSort(bson.D{{Key: fiAuctionCreated, Value: -1}}))
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		log.Errorf("failed to lookup auction for %s/%s; %s",
+			contract.String(), (*hexutil.Big)(tokenID).String(), sr.Err().Error())
+		return nil, sr.Err()
+	}
+
+	var row types.Auction
+	if err := sr.Decode(&row); err != nil {
+		log.Errorf("could not decode auction for %s/%s; %s",
+			contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+		return nil, err
+	}
+	return &row, nil
+}
+
 // StoreAuction adds the provided auction into the database.
 func (db *MongoDbBridge) StoreAuction(au *types.Auction) error {
 	if au == nil {
@@ -99,6 +129,28 @@ func (db *MongoDbBridge) StoreAuction(au *types.Auction) error {
 	return nil
 }
 
+// CloseAuctions closes all the open auctions of the given NFT token.
+func (db *MongoDbBridge) CloseAuctions(contract *common.Address, tokenID *big.Int, ts *types.Time) error {
+	col := db.client.Database(db.dbName).Collection(coAuctions)
+
+	rs, err := col.UpdateMany(context.Background(), bson.D{
+		{Key: fiAuctionContract, Value: *contract},
+		{Key: fiAuctionTokenId, Value: hexutil.Big(*tokenID)},
+		{Key: fiAuctionClosed, Value: bson.D{{Key: "$type", Value: 10}}},
+	}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: fiAuctionClosed, Value: *ts}}},
+	})
+	if err != nil {
+		log.Errorf("could not close auctions of %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+		return err
+	}
+
+	if rs.ModifiedCount > 0 {
+		log.Infof("%d auctions of %s/%s closed", rs.ModifiedCount, contract.String(), (*hexutil.Big)(tokenID).String())
+	}
+	return nil
+}
+
 // OpenAuctionTimeCheck provides the active auction date/time of given range.
 func (db *MongoDbBridge) OpenAuctionTimeCheck(contract *common.Address, tokenID *big.Int, operator string, field string) *types.Time {
 	var row struct {
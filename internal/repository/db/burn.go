@@ -3,15 +3,34 @@ package db
 
 import (
 	"artion-api-graphql/internal/types"
+	"artion-api-graphql/internal/types/sorting"
 	"context"
 	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
 	// coTokenBurns is the name of the collection keeping token burns.
 	coTokenBurns = "burns"
+
+	// fiBurnContract is the name of the DB column of the NFT contract address.
+	fiBurnContract = "contract"
+
+	// fiBurnTokenId is the name of the DB column of the token ID.
+	fiBurnTokenId = "token"
+
+	// fiBurnOwner is the name of the DB column of the address burning the token.
+	fiBurnOwner = "owner"
+
+	// fiBurnTime is the name of the DB column of the time of the burn.
+	fiBurnTime = "burned"
+
+	// fiBurnOrdinal is the name of the DB column of the ordinal index of the burn event.
+	fiBurnOrdinal = "index"
 )
 
 // StoreBurn updates NFT burn in the persistent storage.
@@ -47,6 +66,88 @@ func (db *MongoDbBridge) StoreBurn(bu *types.NFTBurn) error {
 	}
 	if rs.UpsertedCount > 0 {
 		log.Infof("token %s / #%s burn by %s stored", bu.Contract.String(), bu.TokenId.String(), bu.Owner.String())
+		db.dropLegacyBurn(ctx, bu)
 	}
 	return nil
 }
+
+// dropLegacyBurn removes the record of the same burn stored before the ordinal index
+// became a part of the burn identifier, so a rescan does not duplicate it.
+func (db *MongoDbBridge) dropLegacyBurn(ctx context.Context, bu *types.NFTBurn) {
+	col := db.client.Database(db.dbName).Collection(coTokenBurns)
+	rs, err := col.DeleteMany(ctx, bson.D{
+		{Key: fiBurnContract, Value: bu.Contract.String()},
+		{Key: fiBurnTokenId, Value: bu.TokenId.String()},
+		{Key: fiBurnOwner, Value: bu.Owner.String()},
+		{Key: fiBurnTime, Value: bu.Burned},
+		{Key: fiBurnOrdinal, Value: bson.D{{Key: "$exists", Value: false}}},
+	})
+	if err != nil {
+		log.Errorf("can not remove legacy burn of %s / #%s; %s", bu.Contract.String(), bu.TokenId.String(), err.Error())
+		return
+	}
+	if rs.DeletedCount > 0 {
+		log.Noticef("legacy burn of %s / #%s by %s replaced", bu.Contract.String(), bu.TokenId.String(), bu.Owner.String())
+	}
+}
+
+// ListBurns lists NFT burns records; the list can be filtered by NFT contract, token and burning owner.
+func (db *MongoDbBridge) ListBurns(contract *common.Address, tokenId *hexutil.Big, owner *common.Address, cursor types.Cursor, count int, backward bool) (out *types.NFTBurnList, err error) {
+	filter := bson.D{}
+	if contract != nil {
+		filter = append(filter, primitive.E{Key: fiBurnContract, Value: contract.String()})
+	}
+	if tokenId != nil {
+		filter = append(filter, primitive.E{Key: fiBurnTokenId, Value: tokenId.String()})
+	}
+	if owner != nil {
+		filter = append(filter, primitive.E{Key: fiBurnOwner, Value: owner.String()})
+	}
+	return db.listBurns(filter, cursor, count, backward)
+}
+
+func (db *MongoDbBridge) listBurns(filter bson.D, cursor types.Cursor, count int, backward bool) (out *types.NFTBurnList, err error) {
+	var list types.NFTBurnList
+	col := db.client.Database(db.dbName).Collection(coTokenBurns)
+	ctx := context.Background()
+
+	list.TotalCount, err = db.getTotalCount(col, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ld, err := db.findPaginated(col, filter, cursor, count, sorting.BurnSortingNone, !backward)
+	if err != nil {
+		log.Errorf("error loading burns list; %s", err.Error())
+		return nil, err
+	}
+
+	// close the cursor as we leave
+	defer func() {
+		err = ld.Close(ctx)
+		if err != nil {
+			log.Errorf("error closing burns list cursor; %s", err.Error())
+		}
+	}()
+
+	for ld.Next(ctx) {
+		if len(list.Collection) < count {
+			var row types.NFTBurn
+			if err = ld.Decode(&row); err != nil {
+				log.Errorf("can not decode the burn in list; %s", err.Error())
+				return nil, err
+			}
+			list.Collection = append(list.Collection, &row)
+		} else {
+			list.HasNext = true
+		}
+	}
+
+	if cursor != "" {
+		list.HasPrev = true
+	}
+	if backward {
+		list.Reverse()
+	}
+	return &list, nil
+}
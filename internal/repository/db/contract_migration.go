@@ -0,0 +1,135 @@
+package db
+
+import (
+	"artion-api-graphql/internal/types"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+const (
+	// fiListingMarketplace is the name of the DB column storing the address of the Marketplace of a listing.
+	fiListingMarketplace = "market"
+
+	// fiOfferMarketplace is the name of the DB column storing the address of the Marketplace of an offer.
+	fiOfferMarketplace = "market"
+
+	// fiAuctionHall is the name of the DB column storing the address of the Auction contract of an auction.
+	fiAuctionHall = "auction"
+
+	// contractMigrationTimeout represents the timeout applied to the contract address migration.
+	contractMigrationTimeout = 10 * time.Minute
+)
+
+// recordKeyFunc decodes the current record of the cursor, assigns it the contract address
+// and provides its new identifier.
+type recordKeyFunc func(cur *mongo.Cursor, adr common.Address) (primitive.ObjectID, error)
+
+// MigrateListingsMarketplace assigns the given Marketplace to listings recorded before
+// the Marketplace address has been stored with them.
+func (db *MongoDbBridge) MigrateListingsMarketplace(adr common.Address) error {
+	return db.migrateContractAddress(coListings, fiListingMarketplace, adr, func(cur *mongo.Cursor, adr common.Address) (primitive.ObjectID, error) {
+		var row types.Listing
+		if err := cur.Decode(&row); err != nil {
+			return primitive.NilObjectID, err
+		}
+		row.Marketplace = adr
+		return row.ID(), nil
+	})
+}
+
+// MigrateOffersMarketplace assigns the given Marketplace to offers recorded before
+// the Marketplace address has been stored with them.
+func (db *MongoDbBridge) MigrateOffersMarketplace(adr common.Address) error {
+	return db.migrateContractAddress(coOffers, fiOfferMarketplace, adr, func(cur *mongo.Cursor, adr common.Address) (primitive.ObjectID, error) {
+		var row types.Offer
+		if err := cur.Decode(&row); err != nil {
+			return primitive.NilObjectID, err
+		}
+		row.Marketplace = adr
+		return row.ID(), nil
+	})
+}
+
+// MigrateAuctionsHall assigns the given Auction contract to auctions recorded before
+// the Auction contract address has been stored with them.
+func (db *MongoDbBridge) MigrateAuctionsHall(adr common.Address) error {
+	return db.migrateContractAddress(coAuctions, fiAuctionHall, adr, func(cur *mongo.Cursor, adr common.Address) (primitive.ObjectID, error) {
+		var row types.Auction
+		if err := cur.Decode(&row); err != nil {
+			return primitive.NilObjectID, err
+		}
+		row.AuctionHall = adr
+		return row.ID(), nil
+	})
+}
+
+// migrateContractAddress assigns the contract address to records of the collection missing it.
+// The contract address is a part of the record identifier, so the records are re-keyed.
+func (db *MongoDbBridge) migrateContractAddress(coName string, field string, adr common.Address, key recordKeyFunc) error {
+	col := db.client.Database(db.dbName).Collection(coName)
+	ctx, cancel := context.WithTimeout(context.Background(), contractMigrationTimeout)
+	defer cancel()
+
+	cur, err := col.Find(ctx, bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: field, Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: field, Value: nil}},
+		bson.D{{Key: field, Value: common.Address{}.String()}},
+	}}})
+	if err != nil {
+		log.Errorf("can not load %s to migrate; %s", coName, err.Error())
+		return err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	var count int
+	for cur.Next(ctx) {
+		id, err := key(cur, adr)
+		if err != nil {
+			log.Errorf("can not decode %s record; %s", coName, err.Error())
+			return err
+		}
+
+		var doc bson.D
+		if err := cur.Decode(&doc); err != nil {
+			log.Errorf("can not decode %s record; %s", coName, err.Error())
+			return err
+		}
+		oldID := cur.Current.Lookup(fieldId)
+
+		// replace the identifier and the contract address in the stored document
+		out := bson.D{{Key: fieldId, Value: id}, {Key: field, Value: adr.String()}}
+		for _, e := range doc {
+			if e.Key != fieldId && e.Key != field {
+				out = append(out, e)
+			}
+		}
+
+		if _, err := col.InsertOne(ctx, out); err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Errorf("can not store migrated %s record; %s", coName, err.Error())
+			return err
+		}
+		if _, err := col.DeleteOne(ctx, bson.D{{Key: fieldId, Value: oldID}}); err != nil {
+			log.Errorf("can not remove migrated %s record; %s", coName, err.Error())
+			return err
+		}
+		count++
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate %s to migrate; %s", coName, err.Error())
+		return err
+	}
+
+	if count > 0 {
+		log.Noticef("%d %s assigned to contract %s", count, coName, adr.String())
+	}
+	return nil
+}
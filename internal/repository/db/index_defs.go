@@ -9,13 +9,26 @@ import (
 
 // IndexDefinitionTokens provides a list of indexes expected to exist on tokens' collection.
 func IndexDefinitionTokens() []mongo.IndexModel {
-	ix := make([]mongo.IndexModel, 2)
+	ix := make([]mongo.IndexModel, 6)
 
 	ixContractToken := "ix_contract_token"
 	ix[0] = mongo.IndexModel{Keys: bson.D{{Key: "contract", Value: 1}, {Key: "token", Value: 1}}, Options: &options.IndexOptions{Name: &ixContractToken}}
 
 	ixOrdinal := "ix_ordinal"
 	ix[1] = mongo.IndexModel{Keys: bson.D{{Key: "index", Value: -1}}, Options: &options.IndexOptions{Name: &ixOrdinal}}
+
+	ixContractTraits := "ix_contract_traits"
+	ix[2] = mongo.IndexModel{Keys: bson.D{{Key: "contract", Value: 1}, {Key: "attributes.trait", Value: 1}, {Key: "attributes.value", Value: 1}}, Options: &options.IndexOptions{Name: &ixContractTraits}}
+
+	ixMetaError := "ix_meta_error"
+	sparse := true
+	ix[3] = mongo.IndexModel{Keys: bson.D{{Key: "meta_error.category", Value: 1}, {Key: "meta_error.host", Value: 1}}, Options: &options.IndexOptions{Name: &ixMetaError, Sparse: &sparse}}
+
+	ixThumbSweep := "ix_thumb_status_queued"
+	ix[4] = mongo.IndexModel{Keys: bson.D{{Key: "thumb_status", Value: 1}, {Key: "thumb_queued", Value: 1}}, Options: &options.IndexOptions{Name: &ixThumbSweep}}
+
+	ixCids := "ix_cids"
+	ix[5] = mongo.IndexModel{Keys: bson.D{{Key: "cids", Value: 1}}, Options: &options.IndexOptions{Name: &ixCids}}
 	return ix
 }
 
@@ -52,6 +65,42 @@ func IndexDefinitionOwnership() []mongo.IndexModel {
 	return ix
 }
 
+// IndexDefinitionOwnershipHistory provides a list of indexes expected to exist on tokens' ownership history.
+func IndexDefinitionOwnershipHistory() []mongo.IndexModel {
+	ix := make([]mongo.IndexModel, 2)
+
+	ixContractBlock := "ix_contract_block"
+	ix[0] = mongo.IndexModel{Keys: bson.D{{Key: "contract", Value: 1}, {Key: "block", Value: 1}}, Options: &options.IndexOptions{Name: &ixContractBlock}}
+
+	ixContractTokenOwner := "ix_contract_token_owner"
+	ix[1] = mongo.IndexModel{Keys: bson.D{{Key: "contract", Value: 1}, {Key: "token", Value: 1}, {Key: "owner", Value: 1}}, Options: &options.IndexOptions{Name: &ixContractTokenOwner}}
+	return ix
+}
+
+// IndexDefinitionTokenMetadataHistory provides a list of indexes expected to exist on tokens' metadata history.
+func IndexDefinitionTokenMetadataHistory() []mongo.IndexModel {
+	ix := make([]mongo.IndexModel, 1)
+
+	ixContractToken := "ix_contract_token_seen"
+	ix[0] = mongo.IndexModel{Keys: bson.D{{Key: "contract", Value: 1}, {Key: "token", Value: 1}, {Key: "first_seen", Value: -1}}, Options: &options.IndexOptions{Name: &ixContractToken}}
+	return ix
+}
+
+// IndexDefinitionTokenDrafts provides a list of indexes expected to exist on token drafts.
+func IndexDefinitionTokenDrafts() []mongo.IndexModel {
+	ix := make([]mongo.IndexModel, 3)
+
+	ixOwnerCreated := "ix_owner_created"
+	ix[0] = mongo.IndexModel{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "created", Value: -1}}, Options: &options.IndexOptions{Name: &ixOwnerCreated}}
+
+	ixMintedCreated := "ix_minted_created"
+	ix[1] = mongo.IndexModel{Keys: bson.D{{Key: "minted", Value: 1}, {Key: "created", Value: 1}}, Options: &options.IndexOptions{Name: &ixMintedCreated}}
+
+	ixCids := "ix_cids"
+	ix[2] = mongo.IndexModel{Keys: bson.D{{Key: "cids", Value: 1}}, Options: &options.IndexOptions{Name: &ixCids}}
+	return ix
+}
+
 // IndexDefinitionUsers provides a list of indexes expected to exist on users' collection.
 func IndexDefinitionUsers() []mongo.IndexModel {
 	ix := make([]mongo.IndexModel, 2)
@@ -113,8 +162,8 @@ func IndexDefinitionActivities() []mongo.IndexModel {
 	ixContractToken := "ix_contract_token"
 	ix[0] = mongo.IndexModel{Keys: bson.D{{Key: "contract", Value: 1}, {Key: "token", Value: 1}}, Options: &options.IndexOptions{Name: &ixContractToken}}
 
-	ixOrdinal := "ix_ordinal"
-	ix[1] = mongo.IndexModel{Keys: bson.D{{Key: "index", Value: -1}}, Options: &options.IndexOptions{Name: &ixOrdinal}}
+	ixOrdinal := "ix_ordinal_seq"
+	ix[1] = mongo.IndexModel{Keys: bson.D{{Key: "index", Value: -1}, {Key: "seq", Value: -1}}, Options: &options.IndexOptions{Name: &ixOrdinal}}
 
 	ixFrom := "ix_from"
 	ix[2] = mongo.IndexModel{Keys: bson.D{{Key: "from", Value: 1}}, Options: &options.IndexOptions{Name: &ixFrom}}
@@ -122,4 +171,19 @@ func IndexDefinitionActivities() []mongo.IndexModel {
 	ixTo := "ix_to"
 	ix[3] = mongo.IndexModel{Keys: bson.D{{Key: "to", Value: 1}}, Options: &options.IndexOptions{Name: &ixTo}}
 	return ix
-}
\ No newline at end of file
+}
+
+// IndexDefinitionBurns provides a list of indexes expected to exist on tokens' burns collection.
+func IndexDefinitionBurns() []mongo.IndexModel {
+	ix := make([]mongo.IndexModel, 3)
+
+	ixContractToken := "ix_contract_token"
+	ix[0] = mongo.IndexModel{Keys: bson.D{{Key: "contract", Value: 1}, {Key: "token", Value: 1}}, Options: &options.IndexOptions{Name: &ixContractToken}}
+
+	ixOwner := "ix_owner"
+	ix[1] = mongo.IndexModel{Keys: bson.D{{Key: "owner", Value: 1}}, Options: &options.IndexOptions{Name: &ixOwner}}
+
+	ixOrdinal := "ix_ordinal"
+	ix[2] = mongo.IndexModel{Keys: bson.D{{Key: "index", Value: -1}}, Options: &options.IndexOptions{Name: &ixOrdinal}}
+	return ix
+}
@@ -23,15 +23,19 @@ type IndexList struct {
 func (db *MongoDbBridge) updateDatabaseIndexes() {
 	// define index list loaders
 	var ixLoaders = map[string]IndexListProvider{
-		coActivities:      IndexDefinitionActivities,
-		coAuctions:        IndexDefinitionAuctions,
-		coAuctionBids:     IndexDefinitionAuctionBids,
-		coCollection:      IndexDefinitionCollections,
-		coListings:        IndexDefinitionListings,
-		coOffers:          IndexDefinitionOffers,
-		coTokenOwnerships: IndexDefinitionOwnership,
-		coTokens:          IndexDefinitionTokens,
-		coUsers:           IndexDefinitionUsers,
+		coActivities:           IndexDefinitionActivities,
+		coAuctions:             IndexDefinitionAuctions,
+		coAuctionBids:          IndexDefinitionAuctionBids,
+		coCollection:           IndexDefinitionCollections,
+		coTokenBurns:           IndexDefinitionBurns,
+		coListings:             IndexDefinitionListings,
+		coOffers:               IndexDefinitionOffers,
+		coTokenOwnerships:      IndexDefinitionOwnership,
+		coOwnershipHistory:     IndexDefinitionOwnershipHistory,
+		coTokens:               IndexDefinitionTokens,
+		coTokenMetadataHistory: IndexDefinitionTokenMetadataHistory,
+		coTokenDrafts:          IndexDefinitionTokenDrafts,
+		coUsers:                IndexDefinitionUsers,
 	}
 
 	// the DB bridge needs a way to terminate this thread
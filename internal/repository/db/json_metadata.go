@@ -0,0 +1,73 @@
+// Package db provides access to the persistent storage.
+package db
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const (
+	// coJsonMetadata is the name of the collection keeping fetched NFT metadata JSON documents.
+	coJsonMetadata = "json_metadata"
+
+	// fiJsonMetadataData is the name of the DB column storing the JSON document.
+	fiJsonMetadataData = "data"
+
+	// fiJsonMetadataFetched is the name of the DB column storing the time the document was fetched.
+	fiJsonMetadataFetched = "fetched"
+
+	// coJsonMetadataQueryTimeout represents the timeout applied to metadata cache queries.
+	coJsonMetadataQueryTimeout = 5 * time.Second
+)
+
+// cachedJsonMetadata represents a metadata JSON document stored in the cache.
+type cachedJsonMetadata struct {
+	Data    []byte    `bson:"data"`
+	Fetched time.Time `bson:"fetched"`
+}
+
+// JsonMetadata loads the cached metadata JSON document fetched from the given URI.
+// Nil is returned if the document has not been fetched yet.
+func (db *MongoDbBridge) JsonMetadata(uri string) ([]byte, error) {
+	col := db.client.Database(db.dbName).Collection(coJsonMetadata)
+	ctx, cancel := context.WithTimeout(context.Background(), coJsonMetadataQueryTimeout)
+	defer func() {
+		cancel()
+	}()
+
+	var row cachedJsonMetadata
+	if err := col.FindOne(ctx, bson.D{{Key: fieldId, Value: uri}}).Decode(&row); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.Errorf("can not load cached metadata of %s; %s", uri, err.Error())
+		return nil, err
+	}
+	return row.Data, nil
+}
+
+// StoreJsonMetadata stores the metadata JSON document fetched from the given URI into the cache.
+func (db *MongoDbBridge) StoreJsonMetadata(uri string, data []byte) error {
+	col := db.client.Database(db.dbName).Collection(coJsonMetadata)
+	ctx, cancel := context.WithTimeout(context.Background(), coJsonMetadataQueryTimeout)
+	defer func() {
+		cancel()
+	}()
+
+	if _, err := col.UpdateOne(
+		ctx,
+		bson.D{{Key: fieldId, Value: uri}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: fiJsonMetadataData, Value: data},
+			{Key: fiJsonMetadataFetched, Value: time.Now().UTC()},
+		}}},
+		options.Update().SetUpsert(true),
+	); err != nil {
+		log.Errorf("can not cache metadata of %s; %s", uri, err.Error())
+		return err
+	}
+	return nil
+}
@@ -33,12 +33,12 @@ const (
 	fiListingClosed = "closed"
 )
 
-// GetListing provides the token listing stored in the database, if available.
-func (db *MongoDbBridge) GetListing(contract *common.Address, tokenID *big.Int, owner *common.Address) (*types.Listing, error) {
+// GetListing provides the token listing of the given marketplace stored in the database, if available.
+func (db *MongoDbBridge) GetListing(marketplace *common.Address, contract *common.Address, tokenID *big.Int, owner *common.Address) (*types.Listing, error) {
 	// get the collection
 	col := db.client.Database(db.dbName).Collection(coListings)
 
-	sr := col.FindOne(context.Background(), bson.D{{Key: fieldId, Value: types.ListingID(contract, tokenID, owner)}})
+	sr := col.FindOne(context.Background(), bson.D{{Key: fieldId, Value: types.ListingID(marketplace, contract, tokenID, owner)}})
 	if sr.Err() != nil {
 		if sr.Err() == mongo.ErrNoDocuments {
 			log.Warningf("could not find listing %s/%s of owner %s; %s",
@@ -89,6 +89,28 @@ func (db *MongoDbBridge) StoreListing(lst *types.Listing) error {
 	return nil
 }
 
+// CloseListings closes all the open listings of the given NFT token.
+func (db *MongoDbBridge) CloseListings(contract *common.Address, tokenID *big.Int, ts *types.Time) error {
+	col := db.client.Database(db.dbName).Collection(coListings)
+
+	rs, err := col.UpdateMany(context.Background(), bson.D{
+		{Key: fiListingContract, Value: *contract},
+		{Key: fiListingTokenId, Value: hexutil.Big(*tokenID)},
+		{Key: fiListingClosed, Value: bson.D{{Key: "$type", Value: 10}}},
+	}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: fiListingClosed, Value: *ts}}},
+	})
+	if err != nil {
+		log.Errorf("could not close listings of %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+		return err
+	}
+
+	if rs.ModifiedCount > 0 {
+		log.Infof("%d listings of %s/%s closed", rs.ModifiedCount, contract.String(), (*hexutil.Big)(tokenID).String())
+	}
+	return nil
+}
+
 // OpenListingSince pulls the earliest date of open listing for the token.
 // If there is no open listing, it returns nil.
 func (db *MongoDbBridge) OpenListingSince(contract *common.Address, tokenID *big.Int) *types.Time {
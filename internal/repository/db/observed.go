@@ -19,6 +19,12 @@ const (
 
 	// fiContractType is the name of the field keeping the contract type.
 	fiContractType = "type"
+
+	// fiContractBlock is the name of the field keeping the contract deployment block.
+	fiContractBlock = "block"
+
+	// fiContractVersion is the name of the field keeping the contract ABI version.
+	fiContractVersion = "ver"
 )
 
 // AddObservedContract adds the specified observed contract record to the collection.
@@ -36,12 +42,15 @@ func (db *MongoDbBridge) AddObservedContract(oc *types.ObservedContract) error {
 }
 
 // ObservedContractAddressByType provides address of an observed contract by its type, if available.
+// If several contracts of the type are observed, the most recently deployed one is provided.
 func (db *MongoDbBridge) ObservedContractAddressByType(t string) (*common.Address, error) {
 	col := db.client.Database(db.dbName).Collection(coObservedContracts)
 	sr := col.FindOne(
 		context.Background(),
 		bson.D{{Key: "type", Value: t}},
-		options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}}),
+		options.FindOne().
+			SetProjection(bson.D{{Key: "_id", Value: 1}}).
+			SetSort(bson.D{{Key: fiContractBlock, Value: -1}}),
 	)
 
 	if sr.Err() != nil {
@@ -63,6 +72,39 @@ func (db *MongoDbBridge) ObservedContractAddressByType(t string) (*common.Addres
 	return &row.ID, nil
 }
 
+// ObservedContractsByType provides list of all observed contracts of the given type.
+// The list is sorted by the deployment block so the most recent contract is the last one.
+func (db *MongoDbBridge) ObservedContractsByType(t string) ([]*types.ObservedContract, error) {
+	col := db.client.Database(db.dbName).Collection(coObservedContracts)
+
+	fi, err := col.Find(
+		context.Background(),
+		bson.D{{Key: fiContractType, Value: t}},
+		options.Find().SetSort(bson.D{{Key: fiContractBlock, Value: 1}}),
+	)
+	if err != nil {
+		log.Errorf("can not pull observed contracts of type %s; %s", t, err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := fi.Close(context.Background()); err != nil {
+			log.Errorf("can not close observed contracts list cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.ObservedContract, 0)
+	for fi.Next(context.Background()) {
+		var row types.ObservedContract
+		if err := fi.Decode(&row); err != nil {
+			log.Errorf("failed to decode observed contract; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
+
 // isObservedContractKnown checks if the given observed contract is already stored in the database.
 func (db *MongoDbBridge) isObservedContractKnown(col *mongo.Collection, oc *types.ObservedContract) bool {
 	return db.exists(col, &bson.D{{Key: fiContractAddress, Value: oc.Address.String()}})
@@ -181,3 +223,18 @@ func (db *MongoDbBridge) NFTContractsTypeMap() map[common.Address]string {
 	log.Noticef("%d NFT contracts known", len(list))
 	return list
 }
+
+// SetObservedContractVersion updates the ABI version of the observed contract.
+func (db *MongoDbBridge) SetObservedContractVersion(adr common.Address, version int) error {
+	col := db.client.Database(db.dbName).Collection(coObservedContracts)
+
+	if _, err := col.UpdateOne(
+		context.Background(),
+		bson.D{{Key: fiContractAddress, Value: adr.String()}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: fiContractVersion, Value: version}}}},
+	); err != nil {
+		log.Errorf("can not update version of contract %s; %s", adr.String(), err.Error())
+		return err
+	}
+	return nil
+}
@@ -35,12 +35,12 @@ const (
 	// fiOfferDeadline = "deadline"
 )
 
-// GetOffer provides the token offer stored in the database, if available.
-func (db *MongoDbBridge) GetOffer(contract *common.Address, tokenID *big.Int, proposer *common.Address) (*types.Offer, error) {
+// GetOffer provides the token offer of the given marketplace stored in the database, if available.
+func (db *MongoDbBridge) GetOffer(marketplace *common.Address, contract *common.Address, tokenID *big.Int, proposer *common.Address) (*types.Offer, error) {
 	// get the collection
 	col := db.client.Database(db.dbName).Collection(coOffers)
 
-	sr := col.FindOne(context.Background(), bson.D{{Key: fieldId, Value: types.OfferID(contract, tokenID, proposer)}})
+	sr := col.FindOne(context.Background(), bson.D{{Key: fieldId, Value: types.OfferID(marketplace, contract, tokenID, proposer)}})
 	if sr.Err() != nil {
 		if sr.Err() == mongo.ErrNoDocuments {
 			log.Warningf("could not find offer %s/%s proposed by %s",
@@ -91,6 +91,28 @@ func (db *MongoDbBridge) StoreOffer(offer *types.Offer) error {
 	return nil
 }
 
+// CloseOffers closes all the open offers of the given NFT token.
+func (db *MongoDbBridge) CloseOffers(contract *common.Address, tokenID *big.Int, ts *types.Time) error {
+	col := db.client.Database(db.dbName).Collection(coOffers)
+
+	rs, err := col.UpdateMany(context.Background(), bson.D{
+		{Key: fiOfferContract, Value: *contract},
+		{Key: fiOfferTokenId, Value: hexutil.Big(*tokenID)},
+		{Key: fiOfferClosed, Value: bson.D{{Key: "$type", Value: 10}}},
+	}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: fiOfferClosed, Value: *ts}}},
+	})
+	if err != nil {
+		log.Errorf("could not close offers of %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+		return err
+	}
+
+	if rs.ModifiedCount > 0 {
+		log.Infof("%d offers of %s/%s closed", rs.ModifiedCount, contract.String(), (*hexutil.Big)(tokenID).String())
+	}
+	return nil
+}
+
 // OpenOfferUntil provides the latest active offer date/time if any.
 func (db *MongoDbBridge) OpenOfferUntil(contract *common.Address, tokenID *big.Int) *types.Time {
 	var row struct {
@@ -10,7 +10,9 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"math/big"
 	"time"
 )
 
@@ -111,6 +113,99 @@ func (db *MongoDbBridge) IsOwnerOf(contract common.Address, tokenId hexutil.Big,
 	return count > 0, err
 }
 
+// TokenHoldersCount provides the number of owners holding some amount of the given NFT.
+func (db *MongoDbBridge) TokenHoldersCount(contract *common.Address, tokenId *hexutil.Big) (int64, error) {
+	filter := bson.D{
+		{Key: fiOwnershipContract, Value: contract.String()},
+		{Key: fiOwnershipTokenId, Value: tokenId.String()},
+	}
+	col := db.client.Database(db.dbName).Collection(coTokenOwnerships)
+	return db.getTotalCount(col, filter)
+}
+
+// TokenOwnershipsSupply provides the total amount of the given NFT held by all its owners
+// along with the number of the owners. Balances are taken from the chain, so the sum does not drift
+// if transfers are processed more than once.
+func (db *MongoDbBridge) TokenOwnershipsSupply(contract *common.Address, tokenId *hexutil.Big) (*big.Int, int64, error) {
+	col := db.client.Database(db.dbName).Collection(coTokenOwnerships)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenOwnershipsQueryTimeout)
+	defer func() {
+		cancel()
+	}()
+
+	cur, err := col.Find(ctx, bson.D{
+		{Key: fiOwnershipContract, Value: contract.String()},
+		{Key: fiOwnershipTokenId, Value: tokenId.String()},
+	})
+	if err != nil {
+		log.Errorf("can not load owners of %s / #%s; %s", contract.String(), tokenId.String(), err.Error())
+		return nil, 0, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	supply := new(big.Int)
+	var holders int64
+	for cur.Next(ctx) {
+		var row types.Ownership
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode ownership; %s", err.Error())
+			return nil, 0, err
+		}
+		supply.Add(supply, row.Qty.ToInt())
+		holders++
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate owners of %s / #%s; %s", contract.String(), tokenId.String(), err.Error())
+		return nil, 0, err
+	}
+	return supply, holders, nil
+}
+
+// CollectionHoldersCount provides the number of unique owners holding any NFT of the given collection.
+// The owners are counted on the server side, so the list of them is not transferred.
+func (db *MongoDbBridge) CollectionHoldersCount(contract *common.Address) (int64, error) {
+	col := db.client.Database(db.dbName).Collection(coTokenOwnerships)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenOwnershipsQueryTimeout)
+	defer func() {
+		cancel()
+	}()
+
+	cur, err := col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: fiOwnershipContract, Value: contract.String()}}}},
+		{{Key: "$group", Value: bson.D{{Key: fieldId, Value: "$" + fiOwnershipOwner}}}},
+		{{Key: "$count", Value: "holders"}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		log.Errorf("can not count holders of %s; %s", contract.String(), err.Error())
+		return 0, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	// no document is provided if the collection does not have any holder
+	if !cur.Next(ctx) {
+		return 0, cur.Err()
+	}
+
+	var row struct {
+		Holders int64 `bson:"holders"`
+	}
+	if err := cur.Decode(&row); err != nil {
+		log.Errorf("can not decode holders count of %s; %s", contract.String(), err.Error())
+		return 0, err
+	}
+	return row.Holders, nil
+}
+
 func (db *MongoDbBridge) ListOwnerships(contract *common.Address, tokenId *hexutil.Big, owner *common.Address, cursor types.Cursor, count int, backward bool) (out *types.OwnershipList, err error) {
 	filter := bson.D{}
 	if contract != nil {
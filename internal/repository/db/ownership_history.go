@@ -0,0 +1,249 @@
+// Package db provides access to the persistent storage.
+package db
+
+import (
+	"artion-api-graphql/internal/types"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const (
+	// coOwnershipHistory is the name of the collection keeping history of token ownership changes.
+	coOwnershipHistory = "ownership_history"
+
+	// fiOwnershipHistoryBlock is the name of the DB column of the block number of the change.
+	fiOwnershipHistoryBlock = "block"
+
+	// fiOwnershipHistoryOrdinal is the name of the DB column of the ordinal index of the change.
+	fiOwnershipHistoryOrdinal = "index"
+
+	// coOwnershipHistorySnapshotTimeout represents the timeout applied to the holders snapshot aggregation.
+	coOwnershipHistorySnapshotTimeout = 5 * time.Minute
+
+	// coOwnershipHistorySeedTimeout represents the timeout applied to the ownership history seeding.
+	coOwnershipHistorySeedTimeout = 30 * time.Minute
+
+	// keyOwnershipHistorySeeded represents the system state record of the finished ownership history seeding.
+	keyOwnershipHistorySeeded = "ownershipHistorySeeded"
+)
+
+// SeedOwnershipHistory adds the current balance of each owner missing in the ownership history
+// as a change made at the mint of the token, so snapshots include holders whose tokens have not moved
+// since the history is recorded. The seeding runs once; its completion is kept in the system state.
+func (db *MongoDbBridge) SeedOwnershipHistory() error {
+	state := db.client.Database(db.dbName).Collection(coSystemStateCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), coOwnershipHistorySeedTimeout)
+	defer cancel()
+
+	done, err := state.CountDocuments(ctx, bson.D{{Key: fieldId, Value: keyOwnershipHistorySeeded}})
+	if err != nil {
+		log.Errorf("can not check ownership history state; %s", err.Error())
+		return err
+	}
+	if done > 0 {
+		return nil
+	}
+
+	cur, err := db.client.Database(db.dbName).Collection(coTokenOwnerships).Find(ctx, bson.D{})
+	if err != nil {
+		log.Errorf("can not load ownerships to seed history; %s", err.Error())
+		return err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	col := db.client.Database(db.dbName).Collection(coOwnershipHistory)
+	var count int
+	for cur.Next(ctx) {
+		var ow types.Ownership
+		if err := cur.Decode(&ow); err != nil {
+			log.Errorf("can not decode ownership; %s", err.Error())
+			return err
+		}
+
+		known, err := col.CountDocuments(ctx, bson.D{
+			{Key: fiOwnershipContract, Value: ow.Contract.String()},
+			{Key: fiOwnershipTokenId, Value: ow.TokenId.String()},
+			{Key: fiOwnershipOwner, Value: ow.Owner.String()},
+		}, options.Count().SetLimit(1))
+		if err != nil {
+			log.Errorf("can not check ownership history of %s / #%s; %s", ow.Contract.String(), ow.TokenId.String(), err.Error())
+			return err
+		}
+		if known > 0 {
+			continue
+		}
+
+		// the balance is attributed to the mint of the token, the real time of the transfer is not known
+		oc := types.OwnershipChange{
+			Contract: ow.Contract,
+			TokenId:  ow.TokenId,
+			Owner:    ow.Owner,
+			Qty:      ow.Qty,
+			Updated:  ow.Updated,
+		}
+		if tok, err := db.GetToken(&ow.Contract, ow.TokenId.ToInt()); err == nil && tok != nil {
+			oc.OrdinalIndex = tok.OrdinalIndex
+			oc.Block = uint64(tok.OrdinalIndex >> 12)
+		}
+		if err := db.StoreOwnershipChange(&oc); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate ownerships to seed history; %s", err.Error())
+		return err
+	}
+
+	if _, err := state.UpdateOne(ctx, bson.D{{Key: fieldId, Value: keyOwnershipHistorySeeded}}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: "time", Value: time.Now().UTC()}, {Key: "count", Value: count}}},
+	}, options.Update().SetUpsert(true)); err != nil {
+		log.Errorf("can not store ownership history state; %s", err.Error())
+		return err
+	}
+	log.Noticef("ownership history seeded with %d current balances", count)
+	return nil
+}
+
+// StoreOwnershipChange adds NFT ownership change record into the persistent storage.
+func (db *MongoDbBridge) StoreOwnershipChange(oc *types.OwnershipChange) error {
+	if oc == nil {
+		return fmt.Errorf("no value to store")
+	}
+
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(coOwnershipHistory)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenOwnershipsQueryTimeout)
+	defer func() {
+		cancel()
+	}()
+
+	// try to do the insert
+	id := oc.ID()
+	if _, err := col.UpdateOne(
+		ctx,
+		bson.D{{Key: fieldId, Value: id}},
+		bson.D{
+			{Key: "$set", Value: oc},
+			{Key: "$setOnInsert", Value: bson.D{{
+				Key: fieldId, Value: id,
+			}}},
+		},
+		options.Update().SetUpsert(true),
+	); err != nil {
+		log.Errorf("can not store ownership change %s of %s / #%s; %s",
+			oc.Owner.String(), oc.Contract.String(), oc.TokenId.String(), err.Error())
+		return err
+	}
+	return nil
+}
+
+// HoldersSnapshot rebuilds the list of holders of the given NFT collection at the given block
+// from the ownership history. The latest balance of each owner and token up to the block counts.
+// If the limit is positive, the snapshot fails with types.ErrHoldersSnapshotTooLarge
+// if more token balances than the limit are collected.
+func (db *MongoDbBridge) HoldersSnapshot(contract *common.Address, block uint64, limit int64) (*types.HoldersSnapshot, error) {
+	col := db.client.Database(db.dbName).Collection(coOwnershipHistory)
+	ctx, cancel := context.WithTimeout(context.Background(), coOwnershipHistorySnapshotTimeout)
+	defer func() {
+		cancel()
+	}()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: fiOwnershipContract, Value: contract.String()},
+			{Key: fiOwnershipHistoryBlock, Value: bson.D{{Key: "$lte", Value: block}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: fiOwnershipHistoryOrdinal, Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "token", Value: "$" + fiOwnershipTokenId},
+				{Key: "owner", Value: "$" + fiOwnershipOwner},
+			}},
+			{Key: "qty", Value: bson.D{{Key: "$first", Value: "$qty"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "qty", Value: bson.D{{Key: "$ne", Value: "0x0"}}}}}},
+	}
+	if limit > 0 {
+		// one more balance tells us the limit has been exceeded
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit + 1}})
+	}
+
+	cur, err := col.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		log.Errorf("can not collect holders of %s at #%d; %s", contract.String(), block, err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	// sum balances of all the tokens by owner
+	holders := make(map[common.Address]*types.HolderBalance)
+	var count int64
+	for cur.Next(ctx) {
+		count++
+		if limit > 0 && count > limit {
+			log.Warningf("holders snapshot of %s at #%d exceeds %d balances", contract.String(), block, limit)
+			return nil, types.ErrHoldersSnapshotTooLarge
+		}
+
+		var row struct {
+			ID struct {
+				Owner common.Address `bson:"owner"`
+			} `bson:"_id"`
+			Qty hexutil.Big `bson:"qty"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode holder balance; %s", err.Error())
+			return nil, err
+		}
+
+		hb, ok := holders[row.ID.Owner]
+		if !ok {
+			hb = &types.HolderBalance{Owner: row.ID.Owner}
+			holders[row.ID.Owner] = hb
+		}
+		hb.Qty = hexutil.Big(*new(big.Int).Add(hb.Qty.ToInt(), row.Qty.ToInt()))
+		hb.Tokens++
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate holders of %s at #%d; %s", contract.String(), block, err.Error())
+		return nil, err
+	}
+
+	snap := types.HoldersSnapshot{
+		Contract: *contract,
+		Block:    block,
+		Holders:  make([]*types.HolderBalance, 0, len(holders)),
+	}
+	for _, hb := range holders {
+		snap.Holders = append(snap.Holders, hb)
+	}
+
+	// the biggest holders go first, the order of equal holders must be stable
+	sort.Slice(snap.Holders, func(i, j int) bool {
+		if c := snap.Holders[i].Qty.ToInt().Cmp(snap.Holders[j].Qty.ToInt()); c != 0 {
+			return c > 0
+		}
+		return bytes.Compare(snap.Holders[i].Owner.Bytes(), snap.Holders[j].Owner.Bytes()) < 0
+	})
+	return &snap, nil
+}
@@ -0,0 +1,155 @@
+// Package db provides access to the persistent storage.
+package db
+
+import (
+	"artion-api-graphql/internal/types"
+	"bytes"
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
+	"time"
+)
+
+const (
+	// bucketThumbnails is the name of the GridFS bucket keeping derived media (image thumbnails).
+	bucketThumbnails = "thumbnails"
+
+	// bucketThumbnailsTimeout represents the timeout applied to transfers of thumbnails.
+	bucketThumbnailsTimeout = 30 * time.Second
+
+	// bucketThumbnailsPurgeBatch is the max number of derived media files removed in one purge run.
+	bucketThumbnailsPurgeBatch = 500
+)
+
+// thumbnailMeta represents the metadata stored with a thumbnail file.
+type thumbnailMeta struct {
+	Uri  string          `bson:"uri"`
+	Size int             `bson:"size"`
+	Type types.ImageType `bson:"type"`
+	ETag string          `bson:"etag"`
+}
+
+// thumbnailsBucket opens the GridFS bucket of the derived media.
+func (db *MongoDbBridge) thumbnailsBucket() (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(db.client.Database(db.dbName), options.GridFSBucket().SetName(bucketThumbnails))
+}
+
+// Thumbnail loads the given variant of the thumbnail of the image on the given source URI.
+// Nil is returned if the variant has not been stored yet.
+func (db *MongoDbBridge) Thumbnail(uri string, v types.ThumbnailVariant) (*types.Image, error) {
+	bucket, err := db.thumbnailsBucket()
+	if err != nil {
+		log.Errorf("thumbnails bucket not available; %s", err.Error())
+		return nil, err
+	}
+	if err := bucket.SetReadDeadline(time.Now().Add(bucketThumbnailsTimeout)); err != nil {
+		return nil, err
+	}
+
+	ds, err := bucket.OpenDownloadStreamByName(v.Key(uri))
+	if err != nil {
+		if err == gridfs.ErrFileNotFound {
+			return nil, nil
+		}
+		log.Errorf("could not open thumbnail %d of %s; %s", v.Size, uri, err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := ds.Close(); err != nil {
+			log.Errorf("could not close thumbnail stream; %s", err.Error())
+		}
+	}()
+
+	var meta thumbnailMeta
+	if err := bson.Unmarshal(ds.GetFile().Metadata, &meta); err != nil {
+		log.Errorf("invalid thumbnail %d metadata of %s; %s", v.Size, uri, err.Error())
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(ds); err != nil {
+		log.Errorf("could not read thumbnail %d of %s; %s", v.Size, uri, err.Error())
+		return nil, err
+	}
+	return &types.Image{Data: buf.Bytes(), Type: meta.Type, Modified: ds.GetFile().UploadDate, ETag: meta.ETag}, nil
+}
+
+// StoreThumbnail stores the given variant of the thumbnail of the image on the given source URI.
+func (db *MongoDbBridge) StoreThumbnail(uri string, v types.ThumbnailVariant, img *types.Image) error {
+	if img == nil {
+		return fmt.Errorf("no value to store")
+	}
+
+	bucket, err := db.thumbnailsBucket()
+	if err != nil {
+		log.Errorf("thumbnails bucket not available; %s", err.Error())
+		return err
+	}
+	if err := bucket.SetWriteDeadline(time.Now().Add(bucketThumbnailsTimeout)); err != nil {
+		return err
+	}
+
+	_, err = bucket.UploadFromStream(v.Key(uri), bytes.NewReader(img.Data),
+		options.GridFSUpload().SetMetadata(thumbnailMeta{Uri: uri, Size: v.Size, Type: img.Type, ETag: img.ContentTag()}))
+	if err != nil {
+		log.Errorf("could not store thumbnail %d of %s; %s", v.Size, uri, err.Error())
+		return err
+	}
+	return nil
+}
+
+// PurgeDerivedMedia removes original media stored before the given time
+// and derived media files stored under keys of previous versions.
+// The number of removed files is returned.
+func (db *MongoDbBridge) PurgeDerivedMedia(originalsBefore time.Time) (int, error) {
+	bucket, err := db.thumbnailsBucket()
+	if err != nil {
+		log.Errorf("thumbnails bucket not available; %s", err.Error())
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bucketThumbnailsTimeout)
+	defer cancel()
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{
+			{Key: "metadata.size", Value: types.OriginalVariant.Size},
+			{Key: "uploadDate", Value: bson.D{{Key: "$lt", Value: originalsBefore}}},
+		},
+		bson.D{{Key: "filename", Value: bson.D{{Key: "$not", Value: primitive.Regex{
+			Pattern: "^" + regexp.QuoteMeta(types.ThumbnailKeyPrefix()),
+		}}}}},
+	}}}
+
+	cur, err := bucket.Find(filter, options.GridFSFind().SetLimit(bucketThumbnailsPurgeBatch))
+	if err != nil {
+		log.Errorf("could not find derived media to purge; %s", err.Error())
+		return 0, err
+	}
+	defer func() {
+		if err := cur.Close(ctx); err != nil {
+			log.Errorf("error closing derived media purge cursor; %s", err.Error())
+		}
+	}()
+
+	count := 0
+	for cur.Next(ctx) {
+		var row struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("could not decode derived media file; %s", err.Error())
+			return count, err
+		}
+		if err := bucket.Delete(row.ID); err != nil && err != gridfs.ErrFileNotFound {
+			log.Errorf("could not purge derived media file %v; %s", row.ID, err.Error())
+			return count, err
+		}
+		count++
+	}
+	return count, cur.Err()
+}
@@ -22,6 +22,12 @@ const (
 	// fiTokenContract is the column storing the address of the NFT token contract.
 	fiTokenContract = "contract"
 
+	// fiTokenTokenId is the column storing the ID of the NFT token.
+	fiTokenTokenId = "token"
+
+	// fiTokenOrdinalIndex is the column storing the ordinal index of the NFT token.
+	fiTokenOrdinalIndex = "index"
+
 	// fiTokenIsActive is the column storing the NFT token activity mark.
 	fiTokenIsActive = "is_active"
 
@@ -48,6 +54,9 @@ const (
 	// of the metadata update schedule of the NFT token.
 	fiTokenMetadataUpdateFailures = "meta_failures"
 
+	// fiTokenMetadataError is the column storing the last failure of the NFT token metadata update.
+	fiTokenMetadataError = "meta_error"
+
 	// fiTokenCreatedBy is the column marking creator of the token.
 	fiTokenCreatedBy = "created_by"
 
@@ -95,6 +104,54 @@ const (
 
 	// fiTokenCategories is the column storing categories ids of the token.
 	fiTokenCategories = "categories"
+
+	// fiTokenBurned is the column storing the date/time the token was burned.
+	fiTokenBurned = "burned"
+
+	// fiTokenBurnedBy is the column storing the address which burned the token.
+	fiTokenBurnedBy = "burned_by"
+
+	// fiTokenAnimationURI is the column storing the animation (rich media) URI of the NFT token.
+	fiTokenAnimationURI = "animation"
+
+	// fiTokenAnimationType is the column storing mimetype of the animation of the NFT token.
+	fiTokenAnimationType = "animation_type"
+
+	// fiTokenAnimationKind is the column storing the kind of media of the animation.
+	fiTokenAnimationKind = "animation_kind"
+
+	// fiTokenContentCids is the column storing root CIDs of IPFS content referenced by the token.
+	fiTokenContentCids = "cids"
+
+	// fiTokenExternalURL is the column storing the external URL of the NFT token.
+	fiTokenExternalURL = "external_url"
+
+	// fiTokenBackgroundColor is the column storing the background color of the NFT token.
+	fiTokenBackgroundColor = "bg_color"
+
+	// fiTokenThumbStatus is the column storing the state of the pre-generated image thumbnail.
+	fiTokenThumbStatus = "thumb_status"
+
+	// fiTokenThumbQueued is the column storing the time the image thumbnail has been queued for generation.
+	fiTokenThumbQueued = "thumb_queued"
+
+	// fiTokenAttributes is the column storing the token traits.
+	fiTokenAttributes = "attributes"
+
+	// fiTokenRarityScore is the column storing the rarity score of the token.
+	fiTokenRarityScore = "rarity"
+
+	// fiTokenRarityRank is the column storing the rarity rank of the token within its collection.
+	fiTokenRarityRank = "rarity_rank"
+
+	// fiTokenSupply is the column storing the total supply of the token.
+	fiTokenSupply = "supply"
+
+	// fiTokenHolders is the column storing the number of token holders.
+	fiTokenHolders = "holders"
+
+	// fiTokenSupplyCheck is the column storing the time of the next on-chain supply check.
+	fiTokenSupplyCheck = "supply_check"
 )
 
 // GetToken loads specific NFT token for the given contract address and token ID
@@ -179,14 +236,23 @@ func (db *MongoDbBridge) UpdateTokenMetadata(nft *types.Token) error {
 		{Key: fiTokenDescription, Value: nft.Description},
 		{Key: fiTokenImageURI, Value: nft.ImageURI},
 		{Key: fiTokenImageType, Value: nft.ImageType},
+		{Key: fiTokenAnimationURI, Value: nft.AnimationURI},
+		{Key: fiTokenAnimationType, Value: nft.AnimationType},
+		{Key: fiTokenAnimationKind, Value: nft.AnimationMedia},
+		{Key: fiTokenContentCids, Value: nft.Cids},
+		{Key: fiTokenExternalURL, Value: nft.ExternalLink},
+		{Key: fiTokenBackgroundColor, Value: nft.BgColor},
 		{Key: fiTokenCategories, Value: nft.Categories},
+		{Key: fiTokenAttributes, Value: nft.Attributes},
 		{Key: fiTokenMetadataUpdate, Value: nft.MetaUpdate},
 		{Key: fiTokenMetadataUpdateFailures, Value: nft.MetaFailures},
+		{Key: fiTokenMetadataError, Value: nft.MetaError},
 		{Key: fiTokenIsActive, Value: nft.IsActive},
 	})
 }
 
-// UpdateTokenMetadataRefreshSchedule sets the NFT metadata update schedule time.
+// UpdateTokenMetadataRefreshSchedule sets the NFT metadata update schedule time
+// and the last failure of the metadata update.
 func (db *MongoDbBridge) UpdateTokenMetadataRefreshSchedule(nft *types.Token) error {
 	if nft == nil {
 		return fmt.Errorf("no value to store")
@@ -195,6 +261,7 @@ func (db *MongoDbBridge) UpdateTokenMetadataRefreshSchedule(nft *types.Token) er
 	return db.UpdateToken(&nft.Contract, (*big.Int)(&nft.TokenId), bson.D{
 		{Key: fiTokenMetadataUpdate, Value: nft.MetaUpdate},
 		{Key: fiTokenMetadataUpdateFailures, Value: nft.MetaFailures},
+		{Key: fiTokenMetadataError, Value: nft.MetaError},
 	})
 }
 
@@ -281,6 +348,159 @@ func (db *MongoDbBridge) TokenMarkSold(contract *common.Address, tokenID *big.In
 	})
 }
 
+// TokenMarkBurned marks the given NFT as burned. A burned token does not have
+// any active listing, offer, or auction.
+func (db *MongoDbBridge) TokenMarkBurned(contract *common.Address, tokenID *big.Int, by *common.Address, ts *time.Time) error {
+	return db.UpdateToken(contract, tokenID, bson.D{
+		{Key: fiTokenBurned, Value: *ts},
+		{Key: fiTokenBurnedBy, Value: *by},
+		{Key: fiTokenHasListingSince, Value: nil},
+		{Key: fiTokenHasOfferUntil, Value: nil},
+		{Key: fiTokenHasAuctionSince, Value: nil},
+		{Key: fiTokenHasAuctionUntil, Value: nil},
+		{Key: fiTokenHasBid, Value: false},
+		{Key: fiTokenSupply, Value: hexutil.Big{}},
+		{Key: fiTokenHolders, Value: 0},
+	})
+}
+
+// TokenClearBurned removes the burn marks of the given NFT minted again after it has been burned.
+func (db *MongoDbBridge) TokenClearBurned(contract *common.Address, tokenID *big.Int) error {
+	return db.UpdateToken(contract, tokenID, bson.D{
+		{Key: fiTokenBurned, Value: nil},
+		{Key: fiTokenBurnedBy, Value: nil},
+	})
+}
+
+// TokenUpdateThumbnailStatus sets the state of the pre-generated image thumbnail of the given NFT.
+// The time of queueing is kept with the pending state, so lost thumbnails can be retried.
+func (db *MongoDbBridge) TokenUpdateThumbnailStatus(contract *common.Address, tokenID *big.Int, status types.ThumbnailStatus) error {
+	data := bson.D{{Key: fiTokenThumbStatus, Value: status}}
+	if status == types.ThumbnailPending {
+		data = append(data, bson.E{Key: fiTokenThumbQueued, Value: time.Now()})
+	}
+	return db.UpdateToken(contract, tokenID, data)
+}
+
+// TokenThumbnailSweepSet pulls up to the given number of NFT tokens with an image,
+// which thumbnail has never been generated, or is pending since before the given time.
+func (db *MongoDbBridge) TokenThumbnailSweepSet(pendingBefore time.Time, limit int64) ([]*types.Token, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cur, err := col.Find(
+		ctx,
+		bson.D{
+			{Key: fiTokenImageURI, Value: bson.D{{Key: "$nin", Value: bson.A{"", nil}}}},
+			{Key: fiTokenBurned, Value: nil},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: fiTokenThumbStatus, Value: bson.D{{Key: "$in", Value: bson.A{types.ThumbnailNone, nil}}}}},
+				bson.D{
+					{Key: fiTokenThumbStatus, Value: types.ThumbnailPending},
+					{Key: "$or", Value: bson.A{
+						bson.D{{Key: fiTokenThumbQueued, Value: nil}},
+						bson.D{{Key: fiTokenThumbQueued, Value: bson.D{{Key: "$lt", Value: pendingBefore}}}},
+					}},
+				},
+			}},
+		},
+		options.Find().SetLimit(limit),
+	)
+	if err != nil {
+		log.Errorf("can not pull thumbnail sweep set; %s", err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("can not close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.Token, 0, limit)
+	for cur.Next(ctx) {
+		var row types.Token
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode Token; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
+
+// TokenUpdateSupply sets the total supply and the number of holders of the given NFT.
+func (db *MongoDbBridge) TokenUpdateSupply(contract *common.Address, tokenID *big.Int, supply *big.Int, holders int64) error {
+	return db.UpdateToken(contract, tokenID, bson.D{
+		{Key: fiTokenSupply, Value: hexutil.Big(*supply)},
+		{Key: fiTokenHolders, Value: holders},
+	})
+}
+
+// TokenMarkSupplyChecked sets the total supply and the number of holders of the given NFT
+// verified on chain and schedules the next supply check.
+func (db *MongoDbBridge) TokenMarkSupplyChecked(contract *common.Address, tokenID *big.Int, supply *big.Int, holders int64) error {
+	return db.UpdateToken(contract, tokenID, bson.D{
+		{Key: fiTokenSupply, Value: hexutil.Big(*supply)},
+		{Key: fiTokenHolders, Value: holders},
+		{Key: fiTokenSupplyCheck, Value: time.Now().Add(types.TokenSupplyCheckDelay)},
+	})
+}
+
+// IsCollectionTokenCreator checks if the given user created any token of the given collection.
+func (db *MongoDbBridge) IsCollectionTokenCreator(contract *common.Address, user *common.Address) (bool, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	count, err := col.CountDocuments(context.Background(), bson.D{
+		{Key: fiTokenContract, Value: contract.String()},
+		{Key: fiTokenCreatedBy, Value: user.String()},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		log.Errorf("can not check creator %s of %s; %s", user.String(), contract.String(), err.Error())
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// TokenSupplyCheckSet pulls a set of NFT tokens scheduled for the on-chain supply check up to this time.
+func (db *MongoDbBridge) TokenSupplyCheckSet() ([]*types.Token, error) {
+	list := make([]*types.Token, types.SupplyCheckSetSize)
+	col := db.client.Database(db.dbName).Collection(coTokens)
+
+	// load the set from database; tokens never checked go first
+	cur, err := col.Find(
+		context.Background(),
+		bson.D{
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: fiTokenSupplyCheck, Value: bson.D{{Key: "$exists", Value: false}}}},
+				bson.D{{Key: fiTokenSupplyCheck, Value: bson.D{{Key: "$lt", Value: time.Now()}}}},
+			}},
+			{Key: fiTokenBurned, Value: nil},
+		},
+		options.Find().SetSort(bson.D{{Key: fiTokenSupplyCheck, Value: 1}}).SetLimit(types.SupplyCheckSetSize),
+	)
+	if err != nil {
+		log.Errorf("can not pull supply check set; %s", err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("can not close cursor; %s", err.Error())
+		}
+	}()
+
+	var i int
+	for cur.Next(context.Background()) {
+		var row types.Token
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode Token; %s", err.Error())
+			return nil, err
+		}
+		list[i] = &row
+		i++
+	}
+	return list[:i], nil
+}
+
 // TokenMetadataRefreshSet pulls s set of NFT tokens scheduled to be updated up to this time.
 func (db *MongoDbBridge) TokenMetadataRefreshSet() ([]*types.Token, error) {
 	list := make([]*types.Token, types.MetadataRefreshSetSize)
@@ -292,6 +512,7 @@ func (db *MongoDbBridge) TokenMetadataRefreshSet() ([]*types.Token, error) {
 		bson.D{
 			{Key: fiTokenMetadataUpdate, Value: bson.D{{"$lt", time.Now()}}},
 			{Key: fiTokenMetadataURI, Value: bson.D{{"$ne", ""}}},
+			{Key: fiTokenBurned, Value: nil},
 		},
 		options.Find().SetSort(bson.D{{Key: fiTokenMetadataUpdate, Value: -1}}).SetLimit(types.MetadataRefreshSetSize),
 	)
@@ -318,6 +539,44 @@ func (db *MongoDbBridge) TokenMetadataRefreshSet() ([]*types.Token, error) {
 	return list[:i], nil
 }
 
+// CollectionMetadataRefreshSet pulls up to the given number of NFT tokens of a collection
+// to have their metadata refreshed on demand. Tokens with failing metadata go first.
+func (db *MongoDbBridge) CollectionMetadataRefreshSet(contract *common.Address, limit int64) ([]*types.Token, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cur, err := col.Find(
+		ctx,
+		bson.D{
+			{Key: fiTokenContract, Value: contract.String()},
+			{Key: fiTokenMetadataURI, Value: bson.D{{Key: "$ne", Value: ""}}},
+			{Key: fiTokenBurned, Value: nil},
+		},
+		options.Find().SetSort(bson.D{{Key: fiTokenMetadataUpdateFailures, Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		log.Errorf("can not pull refresh set of %s; %s", contract.String(), err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("can not close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.Token, 0)
+	for cur.Next(ctx) {
+		var row types.Token
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode Token; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, cur.Err()
+}
+
 func (db *MongoDbBridge) ListTokens(filter *types.TokenFilter, sorting sorting.TokenSorting, sortDesc bool, cursor types.Cursor, count int, backward bool) (out *types.TokenList, err error) {
 	var list types.TokenList
 	col := db.client.Database(db.dbName).Collection(coTokens)
@@ -369,6 +628,12 @@ func (db *MongoDbBridge) ListTokens(filter *types.TokenFilter, sorting sorting.T
 
 func tokenFilterToBson(f *types.TokenFilter) bson.D {
 	filter := bson.D{}
+
+	// burned tokens are excluded, unless explicitly requested
+	if f == nil || f.IncludeBurned == nil || !*f.IncludeBurned {
+		filter = append(filter, bson.E{Key: fiTokenBurned, Value: nil})
+	}
+
 	if f == nil {
 		return filter
 	}
@@ -439,5 +704,151 @@ func tokenFilterToBson(f *types.TokenFilter) bson.D {
 		filter = append(filter, bson.E{Key: fiTokenCreatedBy, Value: *f.CreatedBy})
 	}
 
+	// all the trait filters must match; any of the values of a trait matches
+	if f.Traits != nil && len(*f.Traits) > 0 {
+		traits := make(bson.A, 0, len(*f.Traits))
+		for _, tf := range *f.Traits {
+			traits = append(traits, bson.D{{Key: fiTokenAttributes, Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+				{Key: "trait", Value: tf.TraitType},
+				{Key: "value", Value: bson.D{{Key: "$in", Value: tf.Values}}},
+			}}}}})
+		}
+		filter = append(filter, bson.E{Key: "$and", Value: traits})
+	}
+
 	return filter
 }
+
+// CollectionsWithTraits provides the list of collections having at least one token with traits.
+func (db *MongoDbBridge) CollectionsWithTraits() ([]common.Address, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	res, err := col.Distinct(ctx, fiTokenContract, bson.D{
+		{Key: fiTokenAttributes + ".0", Value: bson.D{{Key: "$exists", Value: true}}},
+	})
+	if err != nil {
+		log.Errorf("can not load collections with traits; %s", err.Error())
+		return nil, err
+	}
+
+	list := make([]common.Address, 0, len(res))
+	for _, v := range res {
+		if s, ok := v.(string); ok && common.IsHexAddress(s) {
+			list = append(list, common.HexToAddress(s))
+		}
+	}
+	return list, nil
+}
+
+// CollectionTokensTraits loads traits of all the tokens of the given collection.
+// Only the data needed for the rarity calculation are loaded.
+func (db *MongoDbBridge) CollectionTokensTraits(contract *common.Address) ([]*types.Token, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	cur, err := col.Find(
+		context.Background(),
+		bson.D{
+			{Key: fiTokenContract, Value: contract.String()},
+			{Key: fiTokenBurned, Value: nil},
+		},
+		options.Find().SetProjection(bson.D{
+			{Key: fiTokenContract, Value: 1},
+			{Key: fiTokenTokenId, Value: 1},
+			{Key: fiTokenOrdinalIndex, Value: 1},
+			{Key: fiTokenAttributes, Value: 1},
+		}),
+	)
+	if err != nil {
+		log.Errorf("can not load traits of %s; %s", contract.String(), err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("can not close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.Token, 0)
+	for cur.Next(context.Background()) {
+		var row types.Token
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode Token; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
+
+// TokensUpdateRarity stores the rarity scores and ranks of the given tokens.
+func (db *MongoDbBridge) TokensUpdateRarity(list []*types.TokenRarity) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, len(list))
+	for i, r := range list {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: fieldId, Value: r.Token.ID()}}).
+			SetUpdate(bson.D{{Key: "$set", Value: bson.D{
+				{Key: fiTokenRarityScore, Value: r.Score},
+				{Key: fiTokenRarityRank, Value: r.Rank},
+			}}})
+	}
+
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	if _, err := col.BulkWrite(context.Background(), models, options.BulkWrite().SetOrdered(false)); err != nil {
+		log.Errorf("can not update tokens rarity; %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// CollectionTraits provides the number of tokens of the given collection by their trait values.
+func (db *MongoDbBridge) CollectionTraits(contract *common.Address) ([]*types.TraitValueCount, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	cur, err := col.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: fiTokenContract, Value: contract.String()},
+			{Key: fiTokenBurned, Value: nil},
+		}}},
+		{{Key: "$unwind", Value: "$" + fiTokenAttributes}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "trait", Value: "$" + fiTokenAttributes + ".trait"},
+				{Key: "value", Value: "$" + fiTokenAttributes + ".value"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "trait", Value: "$_id.trait"},
+			{Key: "value", Value: "$_id.value"},
+			{Key: "count", Value: 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "trait", Value: 1}, {Key: "count", Value: -1}, {Key: "value", Value: 1}}}},
+	})
+	if err != nil {
+		log.Errorf("can not collect traits of %s; %s", contract.String(), err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.TraitValueCount, 0)
+	for cur.Next(context.Background()) {
+		var row types.TraitValueCount
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode trait count; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"artion-api-graphql/internal/types"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"math/big"
+	"time"
+)
+
+const (
+	// coTokenDrafts is the name of the collection keeping token data uploaded, but not minted yet.
+	coTokenDrafts = "token_drafts"
+
+	// fiTokenDraftOwner is the name of the DB column of the address of the uploader.
+	fiTokenDraftOwner = "owner"
+
+	// fiTokenDraftCids is the name of the DB column of the list of pinned content CIDs.
+	fiTokenDraftCids = "cids"
+
+	// fiTokenDraftCreated is the name of the DB column of the upload time.
+	fiTokenDraftCreated = "created"
+
+	// fiTokenDraftContract is the name of the DB column of the contract the draft has been minted on.
+	fiTokenDraftContract = "contract"
+
+	// fiTokenDraftToken is the name of the DB column of the ID of the token minted from the draft.
+	fiTokenDraftToken = "token"
+
+	// fiTokenDraftMinted is the name of the DB column of the time the mint of the draft has been observed.
+	fiTokenDraftMinted = "minted"
+
+	// coTokenDraftsTimeout represents the timeout applied to the token drafts queries.
+	coTokenDraftsTimeout = 30 * time.Second
+)
+
+// StoreTokenDraft stores the token draft; an existing draft of the same content path is replaced.
+func (db *MongoDbBridge) StoreTokenDraft(draft *types.TokenDraft) error {
+	if draft == nil {
+		return fmt.Errorf("no value to store")
+	}
+
+	col := db.client.Database(db.dbName).Collection(coTokenDrafts)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenDraftsTimeout)
+	defer cancel()
+
+	if _, err := col.ReplaceOne(ctx, bson.D{{Key: fieldId, Value: draft.Path}}, draft, options.Replace().SetUpsert(true)); err != nil {
+		log.Errorf("can not store token draft %s; %s", draft.Path, err.Error())
+		return err
+	}
+	return nil
+}
+
+// TokenDrafts provides the list of not minted token drafts of the given owner, the latest draft goes first.
+func (db *MongoDbBridge) TokenDrafts(owner common.Address) ([]*types.TokenDraft, error) {
+	return db.findTokenDrafts(bson.D{
+		{Key: fiTokenDraftOwner, Value: owner.String()},
+		{Key: fiTokenDraftMinted, Value: nil},
+	}, options.Find().SetSort(bson.D{{Key: fiTokenDraftCreated, Value: -1}}))
+}
+
+// ExpiredTokenDrafts provides a batch of not minted token drafts created before the given time.
+func (db *MongoDbBridge) ExpiredTokenDrafts(before time.Time, limit int64) ([]*types.TokenDraft, error) {
+	return db.findTokenDrafts(bson.D{
+		{Key: fiTokenDraftMinted, Value: nil},
+		{Key: fiTokenDraftCreated, Value: bson.D{{Key: "$lt", Value: before}}},
+	}, options.Find().SetSort(bson.D{{Key: fiTokenDraftCreated, Value: 1}}).SetLimit(limit))
+}
+
+// findTokenDrafts loads token drafts matching the filter.
+func (db *MongoDbBridge) findTokenDrafts(filter bson.D, opt *options.FindOptions) ([]*types.TokenDraft, error) {
+	col := db.client.Database(db.dbName).Collection(coTokenDrafts)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenDraftsTimeout)
+	defer cancel()
+
+	cur, err := col.Find(ctx, filter, opt)
+	if err != nil {
+		log.Errorf("can not load token drafts; %s", err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.TokenDraft, 0)
+	for cur.Next(ctx) {
+		var row types.TokenDraft
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode token draft; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate token drafts; %s", err.Error())
+		return nil, err
+	}
+	return list, nil
+}
+
+// TokenDraftMinted marks the not minted token draft of the given content path as minted into the given token.
+// The returned draft is nil if no such draft exists.
+func (db *MongoDbBridge) TokenDraftMinted(path string, contract *common.Address, tokenID *hexutil.Big, ts types.Time) (*types.TokenDraft, error) {
+	col := db.client.Database(db.dbName).Collection(coTokenDrafts)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenDraftsTimeout)
+	defer cancel()
+
+	rs := col.FindOneAndUpdate(
+		ctx,
+		bson.D{{Key: fieldId, Value: path}, {Key: fiTokenDraftMinted, Value: nil}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: fiTokenDraftContract, Value: contract.String()},
+			{Key: fiTokenDraftToken, Value: tokenID.String()},
+			{Key: fiTokenDraftMinted, Value: ts},
+		}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if rs.Err() != nil {
+		if rs.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.Errorf("can not mark token draft %s minted; %s", path, rs.Err().Error())
+		return nil, rs.Err()
+	}
+
+	var row types.TokenDraft
+	if err := rs.Decode(&row); err != nil {
+		log.Errorf("can not decode token draft; %s", err.Error())
+		return nil, err
+	}
+	return &row, nil
+}
+
+// IsTokenDraftContentShared checks if the pinned content of the given CID is used by any other token draft.
+func (db *MongoDbBridge) IsTokenDraftContentShared(cid string, path string) (bool, error) {
+	col := db.client.Database(db.dbName).Collection(coTokenDrafts)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenDraftsTimeout)
+	defer cancel()
+
+	count, err := col.CountDocuments(ctx, bson.D{
+		{Key: fiTokenDraftCids, Value: cid},
+		{Key: fieldId, Value: bson.D{{Key: "$ne", Value: path}}},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		log.Errorf("can not check usage of content %s; %s", cid, err.Error())
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsTokenContentUsed checks if the pinned content of the given canonical CID is referenced by the metadata,
+// the image or the animation URI of any known token; root CIDs of the URIs are kept with tokens.
+func (db *MongoDbBridge) IsTokenContentUsed(cid string) (bool, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenDraftsTimeout)
+	defer cancel()
+
+	count, err := col.CountDocuments(ctx, bson.D{{Key: fiTokenContentCids, Value: cid}}, options.Count().SetLimit(1))
+	if err != nil {
+		log.Errorf("can not check tokens usage of content %s; %s", cid, err.Error())
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// RemoveTokenDraft removes the token draft of the given content path.
+func (db *MongoDbBridge) RemoveTokenDraft(path string) error {
+	col := db.client.Database(db.dbName).Collection(coTokenDrafts)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenDraftsTimeout)
+	defer cancel()
+
+	if _, err := col.DeleteOne(ctx, bson.D{{Key: fieldId, Value: path}}); err != nil {
+		log.Errorf("can not remove token draft %s; %s", path, err.Error())
+		return err
+	}
+	return nil
+}
+
+// TokensWithoutCids provides tokens stored before root CIDs of their content have been kept with them.
+func (db *MongoDbBridge) TokensWithoutCids(limit int64) ([]*types.Token, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenDraftsTimeout)
+	defer cancel()
+
+	cur, err := col.Find(ctx, bson.D{{Key: fiTokenContentCids, Value: bson.D{{Key: "$exists", Value: false}}}}, options.Find().SetLimit(limit))
+	if err != nil {
+		log.Errorf("can not load tokens without content CIDs; %s", err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.Token, 0, limit)
+	for cur.Next(ctx) {
+		var row types.Token
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode token; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, cur.Err()
+}
+
+// UpdateTokenCids sets root CIDs of IPFS content referenced by the token.
+func (db *MongoDbBridge) UpdateTokenCids(contract *common.Address, tokenID *big.Int, cids []string) error {
+	return db.UpdateToken(contract, tokenID, bson.D{{Key: fiTokenContentCids, Value: cids}})
+}
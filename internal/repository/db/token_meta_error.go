@@ -0,0 +1,144 @@
+package db
+
+import (
+	"artion-api-graphql/internal/types"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const (
+	// fiTokenMetadataErrorCategory is the column storing the category of the last metadata update failure.
+	fiTokenMetadataErrorCategory = "meta_error.category"
+
+	// fiTokenMetadataErrorHost is the column storing the host of the last metadata update failure.
+	fiTokenMetadataErrorHost = "meta_error.host"
+
+	// fiTokenMetadataErrorTime is the column storing the time of the last metadata update failure.
+	fiTokenMetadataErrorTime = "meta_error.time"
+
+	// coTokenMetaErrorTimeout represents the timeout applied to the metadata failures queries.
+	coTokenMetaErrorTimeout = 60 * time.Second
+)
+
+// metadataFailureFilter provides the filter of tokens failing on the metadata update
+// optionally limited to the given category and host.
+func metadataFailureFilter(category *string, host *string) bson.D {
+	filter := bson.D{
+		{Key: fiTokenMetadataError, Value: bson.D{{Key: "$type", Value: "object"}}},
+		{Key: fiTokenBurned, Value: nil},
+	}
+	if category != nil {
+		filter = append(filter, bson.E{Key: fiTokenMetadataErrorCategory, Value: *category})
+	}
+	if host != nil {
+		filter = append(filter, bson.E{Key: fiTokenMetadataErrorHost, Value: *host})
+	}
+	return filter
+}
+
+// MetadataFailureGroups provides the number of tokens failing on the metadata update
+// grouped by the failure category and host. The largest groups go first.
+func (db *MongoDbBridge) MetadataFailureGroups() ([]*types.MetadataFailureGroup, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenMetaErrorTimeout)
+	defer cancel()
+
+	cur, err := col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: metadataFailureFilter(nil, nil)}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "category", Value: "$" + fiTokenMetadataErrorCategory},
+				{Key: "host", Value: "$" + fiTokenMetadataErrorHost},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "last", Value: bson.D{{Key: "$max", Value: "$" + fiTokenMetadataErrorTime}}},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "category", Value: "$_id.category"},
+			{Key: "host", Value: "$_id.host"},
+			{Key: "count", Value: 1},
+			{Key: "last", Value: 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "last", Value: -1}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		log.Errorf("can not collect metadata failures; %s", err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.MetadataFailureGroup, 0)
+	for cur.Next(ctx) {
+		var row types.MetadataFailureGroup
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode metadata failure group; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate metadata failures; %s", err.Error())
+		return nil, err
+	}
+	return list, nil
+}
+
+// MetadataFailingTokens provides up to the given number of tokens failing on the metadata update
+// with the given failure category on the given host. The most recent failures go first.
+func (db *MongoDbBridge) MetadataFailingTokens(category string, host string, limit int64) ([]*types.Token, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenMetaErrorTimeout)
+	defer cancel()
+
+	cur, err := col.Find(ctx, metadataFailureFilter(&category, &host),
+		options.Find().SetSort(bson.D{{Key: fiTokenMetadataErrorTime, Value: -1}}).SetLimit(limit))
+	if err != nil {
+		log.Errorf("can not load tokens failing with %s on %s; %s", category, host, err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.Token, 0)
+	for cur.Next(ctx) {
+		var row types.Token
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode Token; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, cur.Err()
+}
+
+// RetryFailedMetadata schedules immediate metadata update of tokens failing with the given category
+// on the given host; nil category or host matches any. The number of tokens scheduled is returned.
+func (db *MongoDbBridge) RetryFailedMetadata(category *string, host *string) (int64, error) {
+	col := db.client.Database(db.dbName).Collection(coTokens)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenMetaErrorTimeout)
+	defer cancel()
+
+	rs, err := col.UpdateMany(ctx, metadataFailureFilter(category, host), bson.D{
+		{Key: "$set", Value: bson.D{{Key: fiTokenMetadataUpdate, Value: types.Time(time.Now().Add(-time.Second))}}},
+	})
+	if err != nil {
+		log.Errorf("can not schedule failed metadata update; %s", err.Error())
+		return 0, err
+	}
+
+	log.Infof("%d tokens with failed metadata scheduled for update", rs.ModifiedCount)
+	return rs.ModifiedCount, nil
+}
@@ -0,0 +1,144 @@
+// Package db provides access to the persistent storage.
+package db
+
+import (
+	"artion-api-graphql/internal/types"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const (
+	// coTokenMetadataHistory is the name of the collection keeping versions of tokens' metadata.
+	coTokenMetadataHistory = "token_metadata_history"
+
+	// fiTokenMetadataHistoryContract is the name of the DB column of the token contract address.
+	fiTokenMetadataHistoryContract = "contract"
+
+	// fiTokenMetadataHistoryToken is the name of the DB column of the token ID.
+	fiTokenMetadataHistoryToken = "token"
+
+	// fiTokenMetadataHistoryFirstSeen is the name of the DB column of the time the version was first fetched.
+	fiTokenMetadataHistoryFirstSeen = "first_seen"
+
+	// fiTokenMetadataHistoryLastSeen is the name of the DB column of the time the version was last fetched.
+	fiTokenMetadataHistoryLastSeen = "last_seen"
+
+	// coMetadataChangeSequence is the name of the collection keeping the number of metadata changes recorded per block.
+	coMetadataChangeSequence = "metadata_change_seq"
+
+	// fiMetadataChangeSequence is the name of the DB column of the number of metadata changes of the block.
+	fiMetadataChangeSequence = "seq"
+
+	// coTokenMetadataHistoryTimeout represents the timeout applied to the metadata history queries.
+	coTokenMetadataHistoryTimeout = 30 * time.Second
+)
+
+// StoreTokenMetadataVersion stores the metadata version of a token. If the version is already known,
+// only its last seen time is updated. The returned flag is true if the version has not been seen before.
+func (db *MongoDbBridge) StoreTokenMetadataVersion(mv *types.TokenMetadataVersion) (bool, error) {
+	if mv == nil {
+		return false, fmt.Errorf("no value to store")
+	}
+
+	col := db.client.Database(db.dbName).Collection(coTokenMetadataHistory)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenMetadataHistoryTimeout)
+	defer cancel()
+
+	id := mv.ID()
+	rs, err := col.UpdateOne(
+		ctx,
+		bson.D{{Key: fieldId, Value: id}},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: fiTokenMetadataHistoryLastSeen, Value: mv.LastSeen}}},
+			{Key: "$setOnInsert", Value: bson.D{
+				{Key: fieldId, Value: id},
+				{Key: fiTokenMetadataHistoryContract, Value: mv.Contract},
+				{Key: fiTokenMetadataHistoryToken, Value: mv.TokenId},
+				{Key: "hash", Value: mv.Hash},
+				{Key: "uri", Value: mv.Uri},
+				{Key: "name", Value: mv.Name},
+				{Key: "desc", Value: mv.Description},
+				{Key: "image", Value: mv.ImageURI},
+				{Key: "animation", Value: mv.AnimationURI},
+				{Key: "data", Value: mv.Data},
+				{Key: fiTokenMetadataHistoryFirstSeen, Value: mv.FirstSeen},
+			}},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Errorf("can not store metadata version of %s/%s; %s", mv.Contract.String(), mv.TokenId.String(), err.Error())
+		return false, err
+	}
+	return rs.UpsertedCount > 0, nil
+}
+
+// TokenMetadataHistory provides the list of metadata versions of the given token, the latest version goes first.
+func (db *MongoDbBridge) TokenMetadataHistory(contract *common.Address, tokenID *hexutil.Big) ([]*types.TokenMetadataVersion, error) {
+	col := db.client.Database(db.dbName).Collection(coTokenMetadataHistory)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenMetadataHistoryTimeout)
+	defer cancel()
+
+	cur, err := col.Find(ctx, bson.D{
+		{Key: fiTokenMetadataHistoryContract, Value: contract.String()},
+		{Key: fiTokenMetadataHistoryToken, Value: tokenID.String()},
+	}, options.Find().SetSort(bson.D{{Key: fiTokenMetadataHistoryFirstSeen, Value: -1}}))
+	if err != nil {
+		log.Errorf("can not load metadata history of %s/%s; %s", contract.String(), tokenID.String(), err.Error())
+		return nil, err
+	}
+
+	defer func() {
+		if err := cur.Close(context.Background()); err != nil {
+			log.Errorf("failed to close cursor; %s", err.Error())
+		}
+	}()
+
+	list := make([]*types.TokenMetadataVersion, 0)
+	for cur.Next(ctx) {
+		var row types.TokenMetadataVersion
+		if err := cur.Decode(&row); err != nil {
+			log.Errorf("can not decode metadata version; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	if err := cur.Err(); err != nil {
+		log.Errorf("can not iterate metadata history of %s/%s; %s", contract.String(), tokenID.String(), err.Error())
+		return nil, err
+	}
+	return list, nil
+}
+
+// NextMetadataChangeSequence provides the sequence number of the next metadata change recorded
+// at the given block; the first change of the block gets zero.
+func (db *MongoDbBridge) NextMetadataChangeSequence(blk uint64) (int64, error) {
+	col := db.client.Database(db.dbName).Collection(coMetadataChangeSequence)
+	ctx, cancel := context.WithTimeout(context.Background(), coTokenMetadataHistoryTimeout)
+	defer cancel()
+
+	rs := col.FindOneAndUpdate(
+		ctx,
+		bson.D{{Key: fieldId, Value: int64(blk)}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: fiMetadataChangeSequence, Value: int64(1)}}}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+	if rs.Err() != nil {
+		log.Errorf("can not allocate metadata change of block #%d; %s", blk, rs.Err().Error())
+		return 0, rs.Err()
+	}
+
+	var row struct {
+		Seq int64 `bson:"seq"`
+	}
+	if err := rs.Decode(&row); err != nil {
+		log.Errorf("can not decode metadata change sequence; %s", err.Error())
+		return 0, err
+	}
+	return row.Seq - 1, nil
+}
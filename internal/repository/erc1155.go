@@ -10,6 +10,11 @@ func (p *Proxy) Erc1155BalanceOf(contract *common.Address, tokenId *big.Int, own
 	return p.rpc.Erc1155BalanceOf(contract, tokenId, owner, block)
 }
 
+// Erc1155TotalSupply extracts the total supply of a NFT from the ERC-1155 contract.
+func (p *Proxy) Erc1155TotalSupply(contract *common.Address, tokenId *big.Int) (*big.Int, error) {
+	return p.rpc.Erc1155TotalSupply(contract, tokenId)
+}
+
 // Erc1155TokenUri gets a token specific URI address from ERC-1155 contract using uri() call.
 func (p *Proxy) Erc1155TokenUri(contract *common.Address, tokenId *big.Int) (string, error) {
 	return p.rpc.Erc1155TokenUri(contract, tokenId)
@@ -1,53 +1,155 @@
 package repository
 
 import (
+	"artion-api-graphql/internal/repository/svg"
 	"artion-api-graphql/internal/types"
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/disintegration/imaging"
 	ffmpeg "github.com/u2takey/ffmpeg-go"
+	_ "golang.org/x/image/webp" // register WebP decoder
+	"image"
+	"image/color"
 )
 
-const thumbnailMaxHeight = 500
-const thumbnailMaxWidth = 500
+// createThumbnail resize the Image to the given thumbnail variant
+func createThumbnail(input types.Image, v types.ThumbnailVariant) (output types.Image, err error) {
+	if len(input.Data) == 0 {
+		return input, nil // skip thumbnailing of empty files
+	}
+
+	// SVG is served as vector (sanitized already) unless a raster format is requested
+	if input.Type == types.ImageTypeSvg {
+		if v.Format == types.ImageTypeUnknown || v.Format == types.ImageTypeSvg {
+			return input, nil
+		}
+		return createSvgThumbnail(input, v)
+	}
 
-// createThumbnail resize the Image
-func createThumbnail(input types.Image) (output types.Image, err error) {
-	if input.Type == types.ImageTypeSvg || len(input.Data) == 0 {
-		return input, nil // skip thumbnailing of SVG and empty files
+	// video preview clip
+	if v.Format == types.ImageTypeMp4 {
+		if input.Type != types.ImageTypeMp4 {
+			return types.Image{}, fmt.Errorf("video preview of image type %d not supported", input.Type)
+		}
+		return createVideoPreview(input, v)
 	}
+
+	// keep animations of animated images, if the output format allows it
+	if input.Type == types.ImageTypeGif && isAnimatedFormat(v.Format) {
+		if out, ok, err := createAnimatedGifThumbnail(input, v); ok {
+			return out, err
+		}
+	}
+	if input.Type == types.ImageTypeWebp && isAnimatedWebp(input.Data) {
+		if isAnimatedFormat(v.Format) {
+			out, err := createAnimatedWebpThumbnail(input, v)
+			if err == nil {
+				return out, nil
+			}
+			log.Warningf("animated webp thumbnail not available, using the first frame; %s", err.Error())
+		}
+
+		// animated WebP can not be decoded, the thumbnail is made of the first frame
+		input.Data, err = webpFirstFrame(input.Data)
+		if err != nil {
+			return types.Image{}, err
+		}
+	}
+
 	if input.Type == types.ImageTypeMp4 {
 		input, err = createVideoThumbnail(input)
 		if err != nil {
 			return types.Image{}, err
 		}
 	}
-	return createImageThumbnail(input)
+	return createImageThumbnail(input, v)
 }
 
-func createImageThumbnail(input types.Image) (output types.Image, err error) {
+func createImageThumbnail(input types.Image, v types.ThumbnailVariant) (output types.Image, err error) {
 	reader := bytes.NewReader(input.Data)
-	var writer bytes.Buffer
 
 	img, err := imaging.Decode(reader, imaging.AutoOrientation(true))
 	if err != nil {
 		return types.Image{}, fmt.Errorf("decoding failed (type %d); %s", input.Type, err)
 	}
 
-	small := imaging.Fit(img, thumbnailMaxWidth, thumbnailMaxHeight, imaging.Linear)
+	small := imaging.Fit(img, v.Size, v.Size, imaging.Linear)
 
-	if input.Type == types.ImageTypeJpeg {
+	format := v.Format
+	if format == types.ImageTypeUnknown {
+		format = types.ImageTypePng // also for GIFs
+		if input.Type == types.ImageTypeJpeg {
+			format = types.ImageTypeJpeg
+		}
+	}
+	return encodeThumbnail(small, format)
+}
+
+// createSvgThumbnail rasterizes the SVG image into the requested raster format.
+func createSvgThumbnail(input types.Image, v types.ThumbnailVariant) (output types.Image, err error) {
+	img, err := svg.Rasterize(input.Data, v.Size)
+	if errors.Is(err, svg.ErrUnsupported) {
+		// an incomplete raster would misrepresent the image, the sanitized vector is served instead
+		log.Debugf("svg served as vector; %s", err.Error())
+		return input, nil
+	}
+	if err != nil {
+		return types.Image{}, fmt.Errorf("svg rasterization failed; %w", err)
+	}
+
+	// JPEG has no transparency, the image is placed on white background
+	if v.Format == types.ImageTypeJpeg {
+		bg := imaging.New(img.Bounds().Dx(), img.Bounds().Dy(), color.White)
+		return encodeThumbnail(imaging.Overlay(bg, img, image.Point{}, 1), v.Format)
+	}
+	return encodeThumbnail(img, v.Format)
+}
+
+// encodeThumbnail encodes the resized image into the given format.
+func encodeThumbnail(small image.Image, format types.ImageType) (output types.Image, err error) {
+	var writer bytes.Buffer
+
+	switch format {
+	case types.ImageTypeJpeg:
 		err = imaging.Encode(&writer, small, imaging.JPEG, imaging.JPEGQuality(80))
-	} else {
-		err = imaging.Encode(&writer, small, imaging.PNG) // also for GIFs
-		input.Type = types.ImageTypePng
+	case types.ImageTypeGif:
+		err = imaging.Encode(&writer, small, imaging.GIF)
+	default:
+		err = imaging.Encode(&writer, small, imaging.PNG)
 	}
 	if err != nil {
 		return types.Image{}, err
 	}
+
+	out := types.Image{
+		Data: writer.Bytes(),
+		Type: types.ImageTypePng,
+	}
+	switch format {
+	case types.ImageTypeJpeg, types.ImageTypeGif:
+		out.Type = format
+	case types.ImageTypeWebp:
+		return encodeWebp(out)
+	}
+	return out, nil
+}
+
+// encodeWebp converts the given image into WebP; imaging can not encode WebP, so we use ffmpeg.
+func encodeWebp(input types.Image) (output types.Image, err error) {
+	writer := bytes.NewBuffer(nil)
+	err = ffmpeg.
+		Input("pipe:", ffmpeg.KwArgs{"f": "image2pipe"}).
+		Output("pipe:", ffmpeg.KwArgs{"vframes": 1, "format": "webp", "vcodec": "libwebp", "quality": 80}).
+		WithInput(bytes.NewReader(input.Data)).
+		WithOutput(writer).
+		Run()
+	if err != nil {
+		return types.Image{}, fmt.Errorf("webp encoding failed; %s", err)
+	}
 	return types.Image{
 		Data: writer.Bytes(),
-		Type: input.Type,
+		Type: types.ImageTypeWebp,
 	}, nil
 }
 
@@ -3,7 +3,13 @@ package repository
 import (
 	"artion-api-graphql/internal/repository/uri"
 	"artion-api-graphql/internal/types"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
 	"github.com/onsi/gomega"
+	"image"
+	"image/color"
+	"image/gif"
 	"testing"
 )
 
@@ -13,7 +19,7 @@ func TestVideoThumbnail(t *testing.T) {
 	downloader := &uri.Downloader{}
 	image, err := downloader.GetImage("https://artion.mypinata.cloud/ipfs/QmePhQPfwwCWzqSTpxa2CQFCWLbDwj2PATdL6AFYRw7nFc")
 	g.Expect(image.Type).To(gomega.Equal(types.ImageTypeMp4))
-	thumb, err := createThumbnail(*image)
+	thumb, err := createThumbnail(*image, types.DefaultThumbnailVariant)
 	g.Expect(err).To(gomega.BeNil())
 	g.Expect(len(thumb.Data) > 10).To(gomega.BeTrue())
 	g.Expect(thumb.Type).To(gomega.Equal(types.ImageTypeJpeg))
@@ -28,7 +34,7 @@ func TestJpgThumbnail(t *testing.T) {
 	downloader := &uri.Downloader{}
 	image, err := downloader.GetImage("https://artion.mypinata.cloud/ipfs/QmbcqNsWpQuE56xVKQ226rDM29KM7UegmueqMeXPpq5qwo/140.png")
 	g.Expect(image.Type).To(gomega.Equal(types.ImageTypePng))
-	thumb, err := createThumbnail(*image)
+	thumb, err := createThumbnail(*image, types.DefaultThumbnailVariant)
 	g.Expect(err).To(gomega.BeNil())
 	g.Expect(len(thumb.Data) > 10).To(gomega.BeTrue())
 	g.Expect(thumb.Type).To(gomega.Equal(types.ImageTypePng))
@@ -36,3 +42,74 @@ func TestJpgThumbnail(t *testing.T) {
 	//err = os.WriteFile("/tmp/140.png", thumb.Data, 0744)
 	//g.Expect(err).To(gomega.BeNil())
 }
+
+func TestAnimatedGifThumbnail(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// two frames 200x100 animation, the second frame covers just a part of the screen
+	pal := color.Palette{color.Black, color.White}
+	anim := gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 200, 100), pal),
+			image.NewPaletted(image.Rect(50, 25, 100, 75), pal),
+		},
+		Delay:  []int{10, 10},
+		Config: image.Config{ColorModel: pal, Width: 200, Height: 100},
+	}
+	var buf bytes.Buffer
+	g.Expect(gif.EncodeAll(&buf, &anim)).To(gomega.BeNil())
+
+	thumb, err := createThumbnail(types.Image{Data: buf.Bytes(), Type: types.ImageTypeGif}, types.ThumbnailVariant{Size: 64})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(thumb.Type).To(gomega.Equal(types.ImageTypeGif))
+
+	out, err := gif.DecodeAll(bytes.NewReader(thumb.Data))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(out.Image)).To(gomega.Equal(2))
+	g.Expect(out.Image[1].Bounds()).To(gomega.Equal(image.Rect(0, 0, 64, 32)))
+}
+
+func TestAnimatedWebpFirstFrame(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// 1x1 lossless still image wrapped into a single frame animation
+	still, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	g.Expect(err).To(gomega.BeNil())
+
+	chunk := func(fourcc string, payload []byte) []byte {
+		out := make([]byte, 8, 8+len(payload)+1)
+		copy(out, fourcc)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(payload)))
+		out = append(out, payload...)
+		if len(payload)&1 != 0 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	body = append(body, chunk("ANIM", []byte{0, 0, 0, 0, 0, 0})...)
+	body = append(body, chunk("ANMF", append(make([]byte, 16), still[12:]...))...)
+	anim := append(chunk("RIFF", body)[:8], body...)
+	g.Expect(isAnimatedWebp(anim)).To(gomega.BeTrue())
+
+	frame, err := webpFirstFrame(anim)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(isAnimatedWebp(frame)).To(gomega.BeFalse())
+
+	// static formats get the thumbnail of the first frame
+	thumb, err := createThumbnail(types.Image{Data: anim, Type: types.ImageTypeWebp}, types.ThumbnailVariant{Size: 64, Format: types.ImageTypeJpeg})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(thumb.Type).To(gomega.Equal(types.ImageTypeJpeg))
+
+	// small animations are kept as they are
+	thumb, err = createThumbnail(types.Image{Data: anim, Type: types.ImageTypeWebp}, types.ThumbnailVariant{Size: 64})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(thumb.Data).To(gomega.Equal(anim))
+
+	// the requested format is honored even for small animations
+	thumb, err = createThumbnail(types.Image{Data: anim, Type: types.ImageTypeWebp}, types.ThumbnailVariant{Size: 64, Format: types.ImageTypeGif})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(thumb.Type).To(gomega.Equal(types.ImageTypeGif))
+}
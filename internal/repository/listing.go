@@ -8,9 +8,9 @@ import (
 	"math/big"
 )
 
-// GetListing provides an NFT listing stored in the database, if available.
-func (p *Proxy) GetListing(contract *common.Address, tokenID *big.Int, owner *common.Address) (*types.Listing, error) {
-	return p.db.GetListing(contract, tokenID, owner)
+// GetListing provides an NFT listing of the given marketplace stored in the database, if available.
+func (p *Proxy) GetListing(marketplace *common.Address, contract *common.Address, tokenID *big.Int, owner *common.Address) (*types.Listing, error) {
+	return p.db.GetListing(marketplace, contract, tokenID, owner)
 }
 
 // StoreListing stores the given token listing into the persistent storage.
@@ -21,3 +21,8 @@ func (p *Proxy) StoreListing(lst *types.Listing) error {
 func (p *Proxy) ListListings(nft *common.Address, tokenId *hexutil.Big, owner *common.Address, cursor types.Cursor, count int, backward bool) (out *types.ListingList, err error) {
 	return p.db.ListListings(nft, tokenId, owner, cursor, count, backward)
 }
+
+// CloseListings closes all the open listings of the given NFT token.
+func (p *Proxy) CloseListings(contract *common.Address, tokenID *big.Int, ts *types.Time) error {
+	return p.db.CloseListings(contract, tokenID, ts)
+}
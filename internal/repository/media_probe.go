@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"artion-api-graphql/internal/types"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+	"image"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mediaProbeTimeout is the max time the media stream analysis may take.
+const mediaProbeTimeout = 30 * time.Second
+
+// imageFormatByMimetype maps mimetypes of raster images to the names of their Go image decoders.
+var imageFormatByMimetype = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// ProbeMedia decodes the header of the media of the given mimetype and provides its dimensions and duration.
+// Only the header is decoded, so the media properties can be checked before the media is decoded as a whole.
+func (p *Proxy) ProbeMedia(data []byte, mimetype string) (*types.MediaInfo, error) {
+	switch types.MediaKindFromMimetype(mimetype) {
+	case types.MediaKindImage:
+		return probeImage(data, mimetype)
+	case types.MediaKindVideo, types.MediaKindAudio:
+		return probeStreams(data, types.MediaKindFromMimetype(mimetype))
+	}
+	return nil, fmt.Errorf("media type %s not supported", mimetype)
+}
+
+// VerifyMedia decodes the whole media of the given mimetype to make sure it's not damaged.
+// Video and audio streams are verified by probing them already, see ProbeMedia.
+func (p *Proxy) VerifyMedia(data []byte, mimetype string) error {
+	if types.MediaKindFromMimetype(mimetype) != types.MediaKindImage {
+		return nil
+	}
+
+	// animated WebP can not be decoded, its header has been checked by the probe
+	if mimetype == "image/webp" && isAnimatedWebp(data) {
+		return nil
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("image can not be decoded; %s", err)
+	}
+	return nil
+}
+
+// probeImage decodes the header of a raster image.
+func probeImage(data []byte, mimetype string) (*types.MediaInfo, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("image header can not be decoded; %s", err)
+	}
+	if imageFormatByMimetype[mimetype] != format {
+		return nil, fmt.Errorf("image format %s does not match %s", format, mimetype)
+	}
+	return &types.MediaInfo{Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// probeStreams analyses video or audio streams of the media using ffprobe.
+// The media is expected to contain at least one stream of the given kind.
+func probeStreams(data []byte, kind types.MediaKind) (*types.MediaInfo, error) {
+	// ffprobe needs to seek in the media, so it's analysed from a temporary file
+	tmp, err := ioutil.TempFile("", "artion-probe-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := ffmpeg.ProbeWithTimeout(tmp.Name(), mediaProbeTimeout, ffmpeg.KwArgs{})
+	if err != nil {
+		return nil, fmt.Errorf("media can not be decoded; %s", err)
+	}
+
+	var probe struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal([]byte(out), &probe); err != nil {
+		return nil, fmt.Errorf("unexpected media probe output; %s", err)
+	}
+
+	info := types.MediaInfo{}
+	found := false
+	for _, st := range probe.Streams {
+		if strings.ToUpper(st.CodecType) != kind.String() {
+			continue
+		}
+		found = true
+		if st.Width > info.Width {
+			info.Width = st.Width
+		}
+		if st.Height > info.Height {
+			info.Height = st.Height
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no %s stream found", strings.ToLower(kind.String()))
+	}
+
+	if sec, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(sec * float64(time.Second))
+	}
+	return &info, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"bytes"
+	"github.com/onsi/gomega"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestProbeImage(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	p := Proxy{}
+
+	var buf bytes.Buffer
+	g.Expect(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30)))).To(gomega.Succeed())
+
+	info, err := p.ProbeMedia(buf.Bytes(), "image/png")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(info.Width).To(gomega.Equal(40))
+	g.Expect(info.Height).To(gomega.Equal(30))
+	g.Expect(p.VerifyMedia(buf.Bytes(), "image/png")).To(gomega.Succeed())
+
+	_, err = p.ProbeMedia(buf.Bytes(), "image/jpeg")
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	truncated := buf.Bytes()[:buf.Len()-20]
+	_, err = p.ProbeMedia(truncated, "image/png")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p.VerifyMedia(truncated, "image/png")).ToNot(gomega.Succeed())
+}
@@ -8,9 +8,9 @@ import (
 	"math/big"
 )
 
-// GetOffer provides the token offer stored in the database, if available.
-func (p *Proxy) GetOffer(contract *common.Address, tokenID *big.Int, proposer *common.Address) (*types.Offer, error) {
-	return p.db.GetOffer(contract, tokenID, proposer)
+// GetOffer provides the token offer of the given marketplace stored in the database, if available.
+func (p *Proxy) GetOffer(marketplace *common.Address, contract *common.Address, tokenID *big.Int, proposer *common.Address) (*types.Offer, error) {
+	return p.db.GetOffer(marketplace, contract, tokenID, proposer)
 }
 
 // StoreOffer adds the provided offer into the database.
@@ -21,3 +21,8 @@ func (p *Proxy) StoreOffer(offer *types.Offer) error {
 func (p *Proxy) ListOffers(nft *common.Address, tokenId *hexutil.Big, creator *common.Address, cursor types.Cursor, count int, backward bool) (out *types.OfferList, err error) {
 	return p.db.ListOffers(nft, tokenId, creator, cursor, count, backward)
 }
+
+// CloseOffers closes all the open offers of the given NFT token.
+func (p *Proxy) CloseOffers(contract *common.Address, tokenID *big.Int, ts *types.Time) error {
+	return p.db.CloseOffers(contract, tokenID, ts)
+}
@@ -5,6 +5,7 @@ import (
 	"artion-api-graphql/internal/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
 )
 
 // StoreOwnership stores the given NFT ownership record in persistent storage.
@@ -17,6 +18,37 @@ func (p *Proxy) StoreBurn(bu *types.NFTBurn) error {
 	return p.db.StoreBurn(bu)
 }
 
+// ListBurns lists NFT burn records.
+func (p *Proxy) ListBurns(contract *common.Address, tokenId *hexutil.Big, owner *common.Address, cursor types.Cursor, count int, backward bool) (out *types.NFTBurnList, err error) {
+	return p.db.ListBurns(contract, tokenId, owner, cursor, count, backward)
+}
+
+// StoreOwnershipChange adds NFT ownership change record into the persistent storage.
+func (p *Proxy) StoreOwnershipChange(oc *types.OwnershipChange) error {
+	return p.db.StoreOwnershipChange(oc)
+}
+
+// HoldersSnapshot provides the list of holders of the given NFT collection at the given block.
+// The snapshot fails if it collects more token balances than the given positive limit.
+func (p *Proxy) HoldersSnapshot(contract *common.Address, block uint64, limit int64) (*types.HoldersSnapshot, error) {
+	return p.db.HoldersSnapshot(contract, block, limit)
+}
+
+// TokenHoldersCount provides the number of owners holding some amount of the given NFT.
+func (p *Proxy) TokenHoldersCount(contract *common.Address, tokenId *hexutil.Big) (int64, error) {
+	return p.db.TokenHoldersCount(contract, tokenId)
+}
+
+// TokenOwnershipsSupply provides the total amount of the given NFT held by its owners and the number of the owners.
+func (p *Proxy) TokenOwnershipsSupply(contract *common.Address, tokenId *hexutil.Big) (*big.Int, int64, error) {
+	return p.db.TokenOwnershipsSupply(contract, tokenId)
+}
+
+// CollectionHoldersCount provides the number of unique owners holding any NFT of the given collection.
+func (p *Proxy) CollectionHoldersCount(contract *common.Address) (int64, error) {
+	return p.db.CollectionHoldersCount(contract)
+}
+
 // ListOwnerships lists token ownerships records.
 func (p *Proxy) ListOwnerships(contract *common.Address, tokenId *hexutil.Big, owner *common.Address, cursor types.Cursor, count int, backward bool) (out *types.OwnershipList, err error) {
 	return p.db.ListOwnerships(contract, tokenId, owner, cursor, count, backward)
@@ -0,0 +1,70 @@
+package pinner
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fsProvider is a development stand-in of the pinning service keeping files in a local directory.
+// Files are stored as {dir}/ipfs/{CID}, so a static file server of the directory acts as an IPFS gateway.
+type fsProvider struct {
+	dir string
+}
+
+// newFsProvider creates a new filesystem pinning stand-in keeping files in the given directory.
+func newFsProvider(dir string) (*fsProvider, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "ipfs"), 0755); err != nil {
+		return nil, fmt.Errorf("can not create pinning directory; %w", err)
+	}
+	return &fsProvider{dir: dir}, nil
+}
+
+// Pin stores the file in the directory under its CID.
+func (fp *fsProvider) Pin(_ string, content []byte) (cid string, err error) {
+	cid = rawCid(content)
+
+	// write to a temporary file first, so a partially written file is never served
+	tmp, err := ioutil.TempFile(filepath.Join(fp.dir, "ipfs"), ".pin-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(fp.dir, "ipfs", cid)); err != nil {
+		return "", err
+	}
+	return cid, nil
+}
+
+// Unpin removes the file of the given CID from the directory.
+func (fp *fsProvider) Unpin(cid string) error {
+	if cid == "" || strings.ContainsAny(cid, "/\\.") {
+		return fmt.Errorf("invalid CID %q", cid)
+	}
+	if err := os.Remove(filepath.Join(fp.dir, "ipfs", cid)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// rawCid provides the CIDv1 of the content as a single raw block (sha2-256 multihash),
+// encoded in the lower case base32 multibase.
+func rawCid(content []byte) string {
+	digest := sha256.Sum256(content)
+
+	// version 1, raw codec, sha2-256 multihash of 32 bytes
+	bin := append([]byte{0x01, 0x55, 0x12, 0x20}, digest[:]...)
+	return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bin))
+}
@@ -0,0 +1,29 @@
+package pinner
+
+import (
+	"bytes"
+	ipfsapi "github.com/ipfs/go-ipfs-api"
+	"time"
+)
+
+// kuboProvider pins files on a Kubo (go-ipfs) node using its HTTP API.
+type kuboProvider struct {
+	shell *ipfsapi.Shell
+}
+
+// newKuboProvider creates a new provider pinning on the Kubo node of the given HTTP API address.
+func newKuboProvider(url string, timeout time.Duration) *kuboProvider {
+	sh := ipfsapi.NewShell(url)
+	sh.SetTimeout(timeout)
+	return &kuboProvider{shell: sh}
+}
+
+// Pin adds the file to the Kubo node and pins it there.
+func (kp *kuboProvider) Pin(_ string, content []byte) (cid string, err error) {
+	return kp.shell.Add(bytes.NewReader(content), ipfsapi.Pin(true), ipfsapi.CidVersion(1))
+}
+
+// Unpin removes the pin of the content from the Kubo node.
+func (kp *kuboProvider) Unpin(cid string) error {
+	return kp.shell.Unpin(cid)
+}
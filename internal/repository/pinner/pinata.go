@@ -0,0 +1,101 @@
+package pinner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// pinataProvider pins files using the Pinata pinning service.
+type pinataProvider struct {
+	url    string
+	jwt    string
+	client *http.Client
+}
+
+// newPinataProvider creates a new Pinata pinning provider.
+func newPinataProvider(apiUrl string, jwt string, timeout time.Duration) *pinataProvider {
+	return &pinataProvider{
+		url:    strings.TrimRight(apiUrl, "/"),
+		jwt:    jwt,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Pin uploads the file to Pinata
+// based on https://github.com/wabarc/ipfs-pinner/blob/v1.0.1/pkg/pinata/pinata.go
+func (pp *pinataProvider) Pin(filename string, content []byte) (cid string, err error) {
+	r, w := io.Pipe()
+	m := multipart.NewWriter(w)
+
+	go func() {
+		part, err := m.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = part.Write(content)
+		}
+		if err == nil {
+			err = m.Close()
+		}
+		w.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, pp.url+"/pinning/pinFileToIPFS", r)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Content-Type", m.FormDataContentType())
+	req.Header.Add("Authorization", "Bearer "+pp.jwt)
+
+	resp, err := pp.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var dat map[string]interface{}
+	if err := json.Unmarshal(data, &dat); err != nil {
+		return "", fmt.Errorf("pinata returned %s; %s", resp.Status, err)
+	}
+
+	if errStr, hasErr := dat["error"].(string); hasErr {
+		return "", fmt.Errorf("pinata error: %s", errStr)
+	}
+	if hash, ok := dat["IpfsHash"].(string); ok {
+		return hash, nil
+	}
+	log.Errorf("pinata returned no IpfsHash - response: %s", data)
+	return "", errors.New("pinata returned no IpfsHash")
+}
+
+// Unpin removes the pin of the content from Pinata.
+func (pp *pinataProvider) Unpin(cid string) error {
+	req, err := http.NewRequest(http.MethodDelete, pp.url+"/pinning/unpin/"+url.PathEscape(cid), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", "Bearer "+pp.jwt)
+
+	resp, err := pp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pinata returned %s; %s", resp.Status, data)
+	}
+	return nil
+}
@@ -5,28 +5,53 @@ import (
 	"artion-api-graphql/internal/logger"
 	"artion-api-graphql/internal/types"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
-	"mime/multipart"
-	"net/http"
-	"time"
+	"strings"
 )
 
 // log represents the logger to be used by the repository.
 var log logger.Logger
 
-// Pinner allows to pin files to Pinata IPFS service
+// Provider represents a service pinning content on IPFS.
+type Provider interface {
+	// Pin stores the content under the given file name and provides its CID.
+	Pin(filename string, content []byte) (cid string, err error)
+
+	// Unpin removes the pin of the content with the given CID, so it can be dropped.
+	Unpin(cid string) error
+}
+
+// Pinner allows to pin files to IPFS using the configured pinning provider.
 type Pinner struct {
-	pinataBearer string
+	provider Provider
 }
 
-// New provides new Pinner instance.
+// New provides new Pinner instance using the pinning provider selected in the configuration.
 func New(cfg *config.Config) *Pinner {
-	return &Pinner{
-		pinataBearer: cfg.Ipfs.GatewayBearer,
+	provider, err := newProvider(cfg)
+	if err != nil {
+		panic(fmt.Errorf("invalid pinning configuration; %w", err))
+	}
+	return &Pinner{provider: provider}
+}
+
+// newProvider creates the pinning provider selected in the configuration.
+func newProvider(cfg *config.Config) (Provider, error) {
+	switch strings.ToLower(cfg.Pinning.Provider) {
+	case "pinata":
+		jwt := cfg.Pinning.PinataJwt
+		if jwt == "" && cfg.Ipfs.GatewayBearer != "" {
+			log.Warning("pinning.pinata_jwt not set, using ipfs.gateway_bearer as the Pinata key")
+			jwt = cfg.Ipfs.GatewayBearer
+		}
+		return newPinataProvider(cfg.Pinning.PinataUrl, jwt, cfg.Pinning.Timeout), nil
+	case "kubo":
+		return newKuboProvider(cfg.Pinning.KuboUrl, cfg.Pinning.Timeout), nil
+	case "fs":
+		log.Warning("filesystem pinning is for development only, uploaded content is not available on IPFS")
+		return newFsProvider(cfg.Pinning.FsPath)
 	}
+	return nil, fmt.Errorf("unknown pinning provider %s", cfg.Pinning.Provider)
 }
 
 // SetLogger sets the repository logger to be used to collect logging info.
@@ -34,82 +59,66 @@ func SetLogger(l logger.Logger) {
 	log = l.ModuleLogger("pinner")
 }
 
-// PinTokenData uploads token image and JSON metadata to Pinata and returns public JSON URI
-func (p Pinner) PinTokenData(metadata types.JsonMetadata, image types.Image) (uri string, err error) {
+// ipfsUri provides the IPFS URI of the content with the given CID.
+func ipfsUri(cid string) string {
+	return "ipfs://" + cid
+}
+
+// PinTokenData uploads token image, optional rich media (video, audio) and JSON metadata to IPFS
+// and returns IPFS URI of the JSON along with CIDs of all the pinned files.
+// The metadata are updated with IPFS URIs of the image and the rich media.
+func (p Pinner) PinTokenData(metadata *types.JsonMetadata, image types.Image, animation []byte) (uri string, cids []string, err error) {
 	cid, err := p.PinFile("token-image", image.Data)
 	if err != nil {
-		return "", fmt.Errorf("uploading token image failed; %s", err)
+		return "", nil, fmt.Errorf("uploading token image failed; %s", err)
 	}
-	imageUri := "https://artion.mypinata.cloud/ipfs/" + cid
+	cids = append(cids, cid)
+	imageUri := ipfsUri(cid)
 	metadata.Image = &imageUri
 
+	if animation != nil {
+		cid, err = p.PinFile("token-animation", animation)
+		if err != nil {
+			return "", nil, fmt.Errorf("uploading token media failed; %s", err)
+		}
+		cids = append(cids, cid)
+		animationUri := ipfsUri(cid)
+		metadata.AnimationUrl = &animationUri
+	}
+
 	data, err := json.Marshal(metadata)
 	if err != nil {
-		return "", fmt.Errorf("marshaling json meta failed; %s", err)
+		return "", nil, fmt.Errorf("marshaling json meta failed; %s", err)
 	}
 
 	cid, err = p.PinFile("token-meta", data)
 	if err != nil {
-		return "", fmt.Errorf("uploading token image failed; %s", err)
+		return "", nil, fmt.Errorf("uploading token metadata failed; %s", err)
 	}
-	return "https://artion.mypinata.cloud/ipfs/" + cid, nil
+	return ipfsUri(cid), append(cids, cid), nil
 }
 
-// PinFile uploads the file to Pinata
-// based on https://github.com/wabarc/ipfs-pinner/blob/v1.0.1/pkg/pinata/pinata.go
+// PinFile uploads the file to IPFS and provides its CID.
 func (p Pinner) PinFile(filename string, content []byte) (cid string, err error) {
-	r, w := io.Pipe()
-	m := multipart.NewWriter(w)
-
-	go func() {
-		defer w.Close()
-		defer m.Close()
-
-		part, err := m.CreateFormFile("file", filename)
-		if err != nil {
-			return
-		}
-
-		if _, err = part.Write(content); err != nil {
-			return
-		}
-	}()
-
-	req, err := http.NewRequest(http.MethodPost, "https://api.pinata.cloud/pinning/pinFileToIPFS", r)
-	req.Header.Add("Content-Type", m.FormDataContentType())
-	req.Header.Add("Authorization", "Bearer " + p.pinataBearer)
-
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	log.Infof("pinning file "+filename)
+	log.Infof("pinning file %s", filename)
 
-	resp, err := client.Do(req)
+	cid, err = p.provider.Pin(filename, content)
 	if err != nil {
+		log.Errorf("pinning file %s failed; %s", filename, err.Error())
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
-
-	if err != nil {
-		return "", err
-	}
+	log.Infof("file %s pinned as %s", filename, cid)
+	return cid, nil
+}
 
-	var dat map[string]interface{}
-	if err := json.Unmarshal(data, &dat); err != nil {
-		return "", err
+// Unpin removes the pin of the content with the given CID.
+func (p Pinner) Unpin(cid string) error {
+	if err := p.provider.Unpin(cid); err != nil {
+		log.Errorf("unpinning %s failed; %s", cid, err.Error())
+		return err
 	}
 
-	if errStr, hasErr := dat["error"].(string); hasErr {
-		log.Errorf("pinata error: %s", errStr)
-		return "", errors.New(errStr)
-	}
-	if hash, ok := dat["IpfsHash"].(string); ok {
-		log.Infof("file pinned as "+hash)
-		return hash, nil
-	}
-	log.Errorf("pinata returned no IpfsHash - response: %s", data)
-	return "", errors.New("pinata returned no IpfsHash")
+	log.Infof("content %s unpinned", cid)
+	return nil
 }
@@ -0,0 +1,47 @@
+package pinner
+
+import (
+	"artion-api-graphql/internal/config"
+	"artion-api-graphql/internal/logger"
+	"artion-api-graphql/internal/types"
+	"github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRawCid(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(rawCid([]byte{})).To(gomega.Equal("bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"))
+}
+
+func TestFsPinTokenData(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cfg, err := config.Load()
+	g.Expect(err).To(gomega.BeNil())
+	cfg.Pinning.Provider, cfg.Pinning.FsPath = "fs", t.TempDir()
+	SetLogger(logger.New(cfg))
+	p := New(cfg)
+
+	uri, cids, err := p.PinTokenData(&types.JsonMetadata{Name: "Token #1"}, types.Image{Data: []byte("<svg/>"), Type: types.ImageTypeSvg}, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(uri).To(gomega.HavePrefix("ipfs://b"))
+	g.Expect(cids).To(gomega.HaveLen(2))
+	g.Expect(cids[1]).To(gomega.Equal(strings.TrimPrefix(uri, "ipfs://")))
+
+	data, err := ioutil.ReadFile(filepath.Join(cfg.Pinning.FsPath, "ipfs", strings.TrimPrefix(uri, "ipfs://")))
+	g.Expect(err).To(gomega.BeNil())
+
+	md, err := types.DecodeJsonMetadata(data)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(md.Name).To(gomega.Equal("Token #1"))
+	g.Expect(*md.Image).To(gomega.Equal("ipfs://" + rawCid([]byte("<svg/>"))))
+
+	g.Expect(p.Unpin(cids[0])).To(gomega.BeNil())
+	_, err = os.Stat(filepath.Join(cfg.Pinning.FsPath, "ipfs", cids[0]))
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+	g.Expect(p.Unpin("../ipfs")).NotTo(gomega.BeNil())
+}
@@ -9,6 +9,7 @@ import (
 	"artion-api-graphql/internal/repository/email"
 	"artion-api-graphql/internal/repository/pinner"
 	"artion-api-graphql/internal/repository/rpc"
+	"artion-api-graphql/internal/repository/uploads"
 	"artion-api-graphql/internal/repository/uri"
 	"artion-api-graphql/internal/types"
 	"fmt"
@@ -37,7 +38,9 @@ var instanceMux sync.Mutex
 type Proxy struct {
 	rpc       *rpc.Opera
 	uri       *uri.Downloader
+	bgUri     *uri.Downloader
 	pinner    *pinner.Pinner
+	uploads   *uploads.Store
 	db        *db.MongoDbBridge
 	shared    *db.SharedMongoDbBridge
 	cache     *cache.MemCache
@@ -108,6 +111,9 @@ func passEnvironment() {
 
 	// pinner
 	pinner.SetLogger(log)
+
+	// resumable uploads storage
+	uploads.SetLogger(log)
 }
 
 // newProxy creates new instance of Proxy, implementing the Repository interface.
@@ -120,6 +126,7 @@ func newProxy() *Proxy {
 		rpc:       rpc.New(),
 		uri:       uri.New(cfg),
 		pinner:    pinner.New(cfg),
+		uploads:   uploads.New(cfg),
 		db:        db.New(),
 		shared:    db.NewShared(),
 		cache:     cache.New(),
@@ -134,6 +141,9 @@ func newProxy() *Proxy {
 		return nil
 	}
 
+	// background workers do not wait for busy hosts
+	p.bgUri = p.uri.FailFast()
+
 	// register contracts to the repository
 	p.registerContracts()
 
@@ -154,14 +164,37 @@ func (p *Proxy) Close() {
 	}
 }
 
-// registerContracts will pass contract addresses to the RPC provider.
+// registerContracts will pass contracts to the RPC provider.
+// All the observed versions of a contract type are registered.
 func (p *Proxy) registerContracts() {
-	var contractTypes = []string{"auction", "market", "rng", "token_registry"}
+	var contractTypes = []string{types.ContractTypeAuction, types.ContractTypeMarketplace, "rng", "token_registry"}
 
 	for _, ct := range contractTypes {
-		err := p.rpc.RegisterContract(ct, p.ObservedContractAddressByType(ct))
-		if err != nil {
+		list := p.ObservedContractsByType(ct)
+		if len(list) == 0 {
 			log.Panicf("mandatory contract %s not available", ct)
 		}
+
+		for _, oc := range list {
+			if ct == types.ContractTypeAuction {
+				p.setAuctionVersion(oc)
+			}
+			if err := p.rpc.RegisterContract(oc); err != nil {
+				log.Panicf("mandatory contract %s at %s not available; %s", ct, oc.Address.String(), err.Error())
+			}
+		}
+	}
+
+	// records created before several contract versions were supported belong to the first deployed contract
+	p.migrateContractRecords()
+
+	// holders of tokens not moved since the ownership history is recorded are missing in the history
+	if err := p.db.SeedOwnershipHistory(); err != nil {
+		log.Panicf("ownership history seeding failed; %s", err.Error())
+	}
+
+	// activities stored before the sequence has been introduced are ordered by the ordinal index only
+	if err := p.db.MigrateActivitySequence(); err != nil {
+		log.Panicf("activity sequence migration failed; %s", err.Error())
 	}
 }
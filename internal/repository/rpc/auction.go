@@ -3,6 +3,7 @@
 package rpc
 
 import (
+	"artion-api-graphql/internal/repository/rpc/contracts"
 	"artion-api-graphql/internal/types"
 	"context"
 	"fmt"
@@ -16,19 +17,68 @@ import (
 // auctionDefaultDurationShift defines the default duration of an auction, if not defined otherwise.
 const auctionDefaultDurationShift = (24 * 365 * 10) * time.Hour
 
+// auctionContract represents an Auction contract deployment
+// with bindings of both the V1 and the current Auction contract ABI.
+type auctionContract struct {
+	address  common.Address
+	version  int
+	contract *contracts.FantomAuction
+	legacy   *contracts.FantomAuctionV1
+}
+
+// registerAuction adds a new version of the Auction contract.
+func (o *Opera) registerAuction(oc *types.ObservedContract) (err error) {
+	ac := auctionContract{
+		address: oc.Address,
+		version: oc.Version,
+	}
+
+	ac.contract, err = contracts.NewFantomAuction(oc.Address, o.ftm)
+	if err != nil {
+		return err
+	}
+
+	ac.legacy, err = contracts.NewFantomAuctionV1(oc.Address, o.ftm)
+	if err != nil {
+		return err
+	}
+
+	o.auctions[oc.Address] = &ac
+	return nil
+}
+
+// auctionAt provides the Auction contract deployed at the given address, nil if the address is not known.
+func (o *Opera) auctionAt(adr *common.Address) *auctionContract {
+	if adr == nil {
+		return nil
+	}
+	return o.auctions[*adr]
+}
+
 // ExtendAuctionDetailAt adds contract stored details to the provided auction record.
+// The auction details are pulled from the Auction contract the auction has been created on.
 func (o *Opera) ExtendAuctionDetailAt(au *types.Auction, block *big.Int) error {
+	ac := o.auctionAt(&au.AuctionHall)
+	if ac == nil {
+		return fmt.Errorf("no auction contract available for %s", au.AuctionHall.String())
+	}
+
+	// legacy contract?
+	if ac.version == types.AuctionVersionV1 {
+		return o.extendAuctionV1DetailAt(ac, au, block)
+	}
+
 	// get auction details
-	res, err := o.auctionContract.Auctions(&bind.CallOpts{
+	res, err := ac.contract.Auctions(&bind.CallOpts{
 		BlockNumber: block,
 		Context:     context.Background(),
 	}, au.Contract, (*big.Int)(&au.TokenId))
 	if err != nil {
-		log.Errorf("auction %s/%s not available; %s",
-			au.Contract.String(), au.TokenId.String(), au.Owner.String(), err.Error())
+		log.Errorf("auction %s/%s not available at %s; %s",
+			au.Contract.String(), au.TokenId.String(), ac.address.String(), err.Error())
 
 		// try V1 contract ABI
-		return o.ExtendAuctionV1DetailAt(au, block)
+		return o.extendAuctionV1DetailAt(ac, au, block)
 	}
 
 	// make sure we have what we came for
@@ -58,16 +108,16 @@ func (o *Opera) ExtendAuctionDetailAt(au *types.Auction, block *big.Int) error {
 	return nil
 }
 
-// ExtendAuctionV1DetailAt adds contract stored details to the provided auction record using V1 auction ABI.
-func (o *Opera) ExtendAuctionV1DetailAt(au *types.Auction, block *big.Int) error {
+// extendAuctionV1DetailAt adds contract stored details to the provided auction record using V1 auction ABI.
+func (o *Opera) extendAuctionV1DetailAt(ac *auctionContract, au *types.Auction, block *big.Int) error {
 	// get auction details
-	res, err := o.auctionV1Contract.Auctions(&bind.CallOpts{
+	res, err := ac.legacy.Auctions(&bind.CallOpts{
 		BlockNumber: block,
 		Context:     context.Background(),
 	}, au.Contract, (*big.Int)(&au.TokenId))
 	if err != nil {
-		log.Errorf("auction %s/%s not available; %s",
-			au.Contract.String(), au.TokenId.String(), au.Owner.String(), err.Error())
+		log.Errorf("V1 auction %s/%s not available at %s; %s",
+			au.Contract.String(), au.TokenId.String(), ac.address.String(), err.Error())
 		return err
 	}
 
@@ -98,8 +148,23 @@ func (o *Opera) ExtendAuctionV1DetailAt(au *types.Auction, block *big.Int) error
 }
 
 // AuctionHighestBidAmount collects the value of the current highest bid from the auction.
-func (o *Opera) AuctionHighestBidAmount(contract *common.Address, tokenID *big.Int) (*big.Int, error) {
-	res, err := o.auctionContract.HighestBids(nil, *contract, tokenID)
+func (o *Opera) AuctionHighestBidAmount(auction *common.Address, contract *common.Address, tokenID *big.Int) (*big.Int, error) {
+	ac := o.auctionAt(auction)
+	if ac == nil {
+		return nil, fmt.Errorf("no auction contract available")
+	}
+
+	// legacy contract?
+	if ac.version == types.AuctionVersionV1 {
+		res, err := ac.legacy.HighestBids(nil, *contract, tokenID)
+		if err != nil {
+			log.Errorf("can not get the highest bid of %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+			return nil, err
+		}
+		return res.Bid, nil
+	}
+
+	res, err := ac.contract.HighestBids(nil, *contract, tokenID)
 	if err != nil {
 		log.Errorf("can not get the highest bid of %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
 		return nil, err
@@ -108,8 +173,24 @@ func (o *Opera) AuctionHighestBidAmount(contract *common.Address, tokenID *big.I
 }
 
 // AuctionMinimalBidAmount collects the value of the current minimal bid from the auction.
-func (o *Opera) AuctionMinimalBidAmount(contract *common.Address, tokenID *big.Int) *big.Int {
-	res, err := o.auctionContract.Auctions(nil, *contract, tokenID)
+func (o *Opera) AuctionMinimalBidAmount(auction *common.Address, contract *common.Address, tokenID *big.Int) *big.Int {
+	ac := o.auctionAt(auction)
+	if ac == nil {
+		log.Errorf("no auction contract available for %s/%s", contract.String(), (*hexutil.Big)(tokenID).String())
+		return new(big.Int)
+	}
+
+	// legacy contract?
+	if ac.version == types.AuctionVersionV1 {
+		res, err := ac.legacy.Auctions(nil, *contract, tokenID)
+		if err != nil {
+			log.Errorf("can not get auction %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+			return new(big.Int)
+		}
+		return res.ReservePrice
+	}
+
+	res, err := ac.contract.Auctions(nil, *contract, tokenID)
 	if err != nil {
 		log.Errorf("can not get auction %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
 		return new(big.Int)
@@ -118,8 +199,20 @@ func (o *Opera) AuctionMinimalBidAmount(contract *common.Address, tokenID *big.I
 }
 
 // AuctionMinBidIncrement collects the amount of minimal bid increment for auctions.
-func (o *Opera) AuctionMinBidIncrement() *big.Int {
-	val, err := o.auctionContract.MinBidIncrement(nil)
+func (o *Opera) AuctionMinBidIncrement(auction *common.Address) *big.Int {
+	ac := o.auctionAt(auction)
+	if ac == nil {
+		log.Errorf("no auction contract available")
+		return new(big.Int)
+	}
+
+	var val *big.Int
+	var err error
+	if ac.version == types.AuctionVersionV1 {
+		val, err = ac.legacy.MinBidIncrement(nil)
+	} else {
+		val, err = ac.contract.MinBidIncrement(nil)
+	}
 	if err != nil {
 		log.Errorf("failed to get min bid increment; %s", err.Error())
 		return new(big.Int)
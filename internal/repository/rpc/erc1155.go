@@ -33,6 +33,31 @@ func (o *Opera) Erc1155BalanceOf(contract *common.Address, tokenId *big.Int, own
 	return new(big.Int).SetBytes(data), nil
 }
 
+// Erc1155TotalSupply extracts the total supply of a NFT using totalSupply() call.
+// The call is not part of the ERC-1155 standard, not all the contracts support it.
+func (o *Opera) Erc1155TotalSupply(contract *common.Address, tokenId *big.Int) (*big.Int, error) {
+	// prepare params
+	input, err := o.Erc1155Abi().Pack("totalSupply", tokenId)
+	if err != nil {
+		log.Errorf("can not pack data; %s", err.Error())
+		return nil, err
+	}
+
+	// call the contract
+	data, err := o.ftm.CallContract(context.Background(), ethereum.CallMsg{
+		From: common.Address{},
+		To:   contract,
+		Data: input,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 32 {
+		return nil, fmt.Errorf("invalid totalSupply() response of %s", contract.String())
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
 // Erc1155TokenUri gets a token specific URI address from ERC-1155 contract using uri() call.
 func (o *Opera) Erc1155TokenUri(contract *common.Address, tokenId *big.Int) (string, error) {
 	// prepare params
@@ -4,14 +4,20 @@ import (
 	"artion-api-graphql/internal/repository/rpc/contracts"
 	"artion-api-graphql/internal/types"
 	"context"
+	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"math/big"
 )
 
 // GetPayTokenPrice extracts price of pay token using Marketplace contract.
-func (o *Opera) GetPayTokenPrice(_ *common.Address, token *common.Address, _ *big.Int) (*big.Int, error) {
-	return o.marketplace.GetPrice(nil, *token)
+// The default Marketplace is used if no marketplace is given.
+func (o *Opera) GetPayTokenPrice(marketplace *common.Address, token *common.Address, _ *big.Int) (*big.Int, error) {
+	mp := o.marketplaceAt(marketplace)
+	if mp == nil {
+		return nil, fmt.Errorf("no marketplace contract available for %v", marketplace)
+	}
+	return mp.GetPrice(nil, *token)
 }
 
 // ListPayTokens obtains list of tokens allowed for market payments from TokenRegistry contract
@@ -6,6 +6,7 @@ import (
 	"artion-api-graphql/internal/config"
 	"artion-api-graphql/internal/logger"
 	"artion-api-graphql/internal/repository/rpc/contracts"
+	"artion-api-graphql/internal/types"
 	"bytes"
 	"embed"
 	"fmt"
@@ -33,10 +34,6 @@ var cfg *config.Config
 // log represents the logger to be used by the repository.
 var log logger.Logger
 
-// contractsMap represents a map of contract type to address as provided
-// by the repository initializer.
-var contractsMap = map[string]common.Address{}
-
 // Opera represents the implementation of the Blockchain interface for Fantom Opera node.
 type Opera struct {
 	// basics of the connection
@@ -55,60 +52,68 @@ type Opera struct {
 	abiFantom1155  *abi.ABI
 	abiMarketplace *abi.ABI
 
-	// contracts
-	marketplace       *contracts.FantomMarketplace
-	auctionContract   *contracts.FantomAuction
-	auctionV1Contract *contracts.FantomAuctionV1
+	// contracts; several versions of the marketplace and auction may be deployed at once,
+	// the default marketplace is the most recently registered one
+	marketplaces          map[common.Address]*contracts.FantomMarketplace
+	defaultMarketplace    *contracts.FantomMarketplace
+	auctions              map[common.Address]*auctionContract
 	tokenRegistryContract *contracts.FantomTokenRegistry
-	rngFeedContract *contracts.RandomNumberOracle
+	rngFeedContract       *contracts.RandomNumberOracle
 }
 
-// RegisterContract adds a new contract address to the RPC provider.
-func (o *Opera) RegisterContract(ct string, addr *common.Address) (err error) {
-	// address provided?
-	if nil == addr {
-		return fmt.Errorf("empty address on %s", ct)
+// RegisterContract adds a new contract to the RPC provider.
+func (o *Opera) RegisterContract(oc *types.ObservedContract) (err error) {
+	// contract provided?
+	if nil == oc {
+		return fmt.Errorf("empty contract")
 	}
 
 	// load the contract instance
-	switch ct {
-
-	case "auction":
-		o.auctionContract, err = contracts.NewFantomAuction(*addr, o.ftm)
-		if err == nil {
-			log.Noticef("loaded %s contract at %s", ct, addr.String())
-		}
+	switch oc.Type {
 
-		o.auctionV1Contract, err = contracts.NewFantomAuctionV1(*addr, o.ftm)
-		if err == nil {
-			log.Noticef("loaded V1 %s contract at %s", ct, addr.String())
-		}
+	case types.ContractTypeAuction:
+		err = o.registerAuction(oc)
 
-	case "market":
-		o.marketplace, err = contracts.NewFantomMarketplace(*addr, o.ftm)
-		if err == nil {
-			log.Noticef("loaded %s contract at %s", ct, addr.String())
-		}
+	case types.ContractTypeMarketplace:
+		err = o.registerMarketplace(oc)
 
 	case "rng":
-		o.rngFeedContract, err = contracts.NewRandomNumberOracle(*addr, o.ftm)
-		if err == nil {
-			log.Noticef("loaded %s contract at %s", ct, addr.String())
-		}
+		o.rngFeedContract, err = contracts.NewRandomNumberOracle(oc.Address, o.ftm)
 
 	case "token_registry":
-		o.tokenRegistryContract, err = contracts.NewFantomTokenRegistry(*addr, o.ftm)
-		if err == nil {
-			log.Noticef("loaded %s contract at %s", ct, addr.String())
-		}
+		o.tokenRegistryContract, err = contracts.NewFantomTokenRegistry(oc.Address, o.ftm)
 
 	default:
-		err = fmt.Errorf("unknown contract type %s", ct)
+		err = fmt.Errorf("unknown contract type %s", oc.Type)
 	}
 
+	if err == nil {
+		log.Noticef("loaded %s contract v%d at %s", oc.Type, oc.Version, oc.Address.String())
+	}
 	return err
 }
 
+// registerMarketplace adds a new version of the Marketplace contract.
+func (o *Opera) registerMarketplace(oc *types.ObservedContract) error {
+	mp, err := contracts.NewFantomMarketplace(oc.Address, o.ftm)
+	if err != nil {
+		return err
+	}
+
+	o.marketplaces[oc.Address] = mp
+	o.defaultMarketplace = mp
+	return nil
+}
+
+// marketplaceAt provides the Marketplace contract deployed at the given address.
+// The default Marketplace is used if no address is given; nil is returned for unknown address.
+func (o *Opera) marketplaceAt(adr *common.Address) *contracts.FantomMarketplace {
+	if adr == nil {
+		return o.defaultMarketplace
+	}
+	return o.marketplaces[*adr]
+}
+
 // New provides a new instance of the RPC access point.
 func New() *Opera {
 	con, err := connect()
@@ -124,6 +129,9 @@ func New() *Opera {
 		wg:       new(sync.WaitGroup),
 		sigClose: make(chan bool, 1),
 		headers:  make(chan *eth.Header, headerObserverCapacity),
+
+		marketplaces: make(map[common.Address]*contracts.FantomMarketplace),
+		auctions:     make(map[common.Address]*auctionContract),
 	}
 
 	// load and parse ABIs
@@ -0,0 +1,360 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+)
+
+// curveSegments is the number of line segments a Bézier curve is flattened into.
+const curveSegments = 16
+
+// point represents a point of a path.
+type point struct {
+	x, y float64
+}
+
+// subpath represents a flattened part of a path.
+type subpath struct {
+	pts    []point
+	closed bool
+}
+
+// pathBuilder collects flattened subpaths of a shape.
+type pathBuilder struct {
+	paths []subpath
+	cur   *subpath
+	pen   point
+	start point
+}
+
+// moveTo starts a new subpath.
+func (b *pathBuilder) moveTo(p point) {
+	b.paths = append(b.paths, subpath{pts: []point{p}})
+	b.cur = &b.paths[len(b.paths)-1]
+	b.pen, b.start = p, p
+}
+
+// lineTo adds a line to the current subpath.
+func (b *pathBuilder) lineTo(p point) {
+	if b.cur == nil {
+		b.moveTo(b.pen)
+	}
+	b.cur.pts = append(b.cur.pts, p)
+	b.pen = p
+}
+
+// cubeTo adds a cubic Bézier curve to the current subpath.
+func (b *pathBuilder) cubeTo(c1, c2, p point) {
+	p0 := b.pen
+	for i := 1; i <= curveSegments; i++ {
+		t := float64(i) / curveSegments
+		mt := 1 - t
+		b.lineTo(point{
+			mt*mt*mt*p0.x + 3*mt*mt*t*c1.x + 3*mt*t*t*c2.x + t*t*t*p.x,
+			mt*mt*mt*p0.y + 3*mt*mt*t*c1.y + 3*mt*t*t*c2.y + t*t*t*p.y,
+		})
+	}
+}
+
+// quadTo adds a quadratic Bézier curve to the current subpath.
+func (b *pathBuilder) quadTo(c, p point) {
+	p0 := b.pen
+	for i := 1; i <= curveSegments; i++ {
+		t := float64(i) / curveSegments
+		mt := 1 - t
+		b.lineTo(point{
+			mt*mt*p0.x + 2*mt*t*c.x + t*t*p.x,
+			mt*mt*p0.y + 2*mt*t*c.y + t*t*p.y,
+		})
+	}
+}
+
+// ellipseArc adds an arc of the ellipse given by its center and radii rotated by phi.
+func (b *pathBuilder) ellipseArc(c point, rx, ry, phi, theta, delta float64) {
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 16)))
+	if n < 1 {
+		n = 1
+	}
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	for i := 1; i <= n; i++ {
+		a := theta + delta*float64(i)/float64(n)
+		x, y := rx*math.Cos(a), ry*math.Sin(a)
+		b.lineTo(point{c.x + x*cos - y*sin, c.y + x*sin + y*cos})
+	}
+}
+
+// arcTo adds an elliptical arc given by its end point (SVG path "A" command).
+// See the SVG specification, appendix F.6.5 for the conversion to the center parametrization.
+func (b *pathBuilder) arcTo(rx, ry, rotation float64, large, sweep bool, p point) {
+	p0 := b.pen
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || (p0.x == p.x && p0.y == p.y) {
+		b.lineTo(p)
+		return
+	}
+
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (p0.x-p.x)/2, (p0.y-p.y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// scale up radii too small to reach the end point
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	co := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		co = -co
+	}
+	cx1, cy1 := co*rx*y1/ry, -co*ry*x1/rx
+	c := point{
+		cos*cx1 - sin*cy1 + (p0.x+p.x)/2,
+		sin*cx1 + cos*cy1 + (p0.y+p.y)/2,
+	}
+
+	theta := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+	b.ellipseArc(c, rx, ry, phi, theta, delta)
+	b.pen = p
+}
+
+// closePath closes the current subpath.
+func (b *pathBuilder) closePath() {
+	if b.cur != nil {
+		b.cur.closed = true
+		b.cur = nil
+	}
+	b.pen = b.start
+}
+
+// pathScanner tokenizes the path data.
+type pathScanner struct {
+	d string
+	i int
+}
+
+// skipSeparators skips white spaces and commas.
+func (s *pathScanner) skipSeparators() {
+	for s.i < len(s.d) {
+		switch s.d[s.i] {
+		case ' ', '\t', '\n', '\r', ',':
+			s.i++
+		default:
+			return
+		}
+	}
+}
+
+// command reads the next path command, if there is one.
+func (s *pathScanner) command() (byte, bool) {
+	s.skipSeparators()
+	if s.i < len(s.d) {
+		c := s.d[s.i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			if c == 'e' || c == 'E' {
+				return 0, false
+			}
+			s.i++
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// number reads the next number.
+func (s *pathScanner) number() (float64, bool) {
+	s.skipSeparators()
+	start := s.i
+	if s.i < len(s.d) && (s.d[s.i] == '-' || s.d[s.i] == '+') {
+		s.i++
+	}
+	digits, dot := 0, false
+	for s.i < len(s.d) {
+		c := s.d[s.i]
+		if c >= '0' && c <= '9' {
+			digits++
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		s.i++
+	}
+	if digits == 0 {
+		s.i = start
+		return 0, false
+	}
+	if s.i < len(s.d) && (s.d[s.i] == 'e' || s.d[s.i] == 'E') {
+		j := s.i + 1
+		if j < len(s.d) && (s.d[j] == '-' || s.d[j] == '+') {
+			j++
+		}
+		if j < len(s.d) && s.d[j] >= '0' && s.d[j] <= '9' {
+			for j < len(s.d) && s.d[j] >= '0' && s.d[j] <= '9' {
+				j++
+			}
+			s.i = j
+		}
+	}
+	f, err := strconv.ParseFloat(s.d[start:s.i], 64)
+	return f, err == nil
+}
+
+// flag reads the next arc flag; flags may not be separated from the following number.
+func (s *pathScanner) flag() (bool, bool) {
+	s.skipSeparators()
+	if s.i < len(s.d) && (s.d[s.i] == '0' || s.d[s.i] == '1') {
+		s.i++
+		return s.d[s.i-1] == '1', true
+	}
+	return false, false
+}
+
+// numbers reads the given amount of numbers.
+func (s *pathScanner) numbers(n int) ([]float64, bool) {
+	out := make([]float64, n)
+	for i := range out {
+		f, ok := s.number()
+		if !ok {
+			return nil, false
+		}
+		out[i] = f
+	}
+	return out, true
+}
+
+// parsePath decodes the path data into flattened subpaths.
+// Parsing stops on the first error, the path up to the error is rendered, as the specification requires.
+func parsePath(d string) []subpath {
+	var b pathBuilder
+	s := pathScanner{d: d}
+	var cmd, prev byte
+	var ctrl point // the last control point for smooth curves
+
+	for {
+		if c, ok := s.command(); ok {
+			cmd = c
+		} else {
+			// repeated parameters of the previous command
+			s.skipSeparators()
+			if cmd == 0 || cmd == 'Z' || cmd == 'z' || s.i >= len(s.d) {
+				break
+			}
+			if cmd == 'M' {
+				cmd = 'L' // implicit line to after move to
+			} else if cmd == 'm' {
+				cmd = 'l'
+			}
+		}
+
+		rel := cmd >= 'a'
+		at := func(x, y float64) point {
+			if rel {
+				return point{b.pen.x + x, b.pen.y + y}
+			}
+			return point{x, y}
+		}
+
+		var ok bool
+		switch cmd {
+		case 'M', 'm':
+			var a []float64
+			if a, ok = s.numbers(2); ok {
+				b.moveTo(at(a[0], a[1]))
+			}
+		case 'L', 'l':
+			var a []float64
+			if a, ok = s.numbers(2); ok {
+				b.lineTo(at(a[0], a[1]))
+			}
+		case 'H', 'h':
+			var x float64
+			if x, ok = s.number(); ok {
+				p := at(x, 0)
+				b.lineTo(point{p.x, b.pen.y})
+			}
+		case 'V', 'v':
+			var y float64
+			if y, ok = s.number(); ok {
+				p := at(0, y)
+				b.lineTo(point{b.pen.x, p.y})
+			}
+		case 'C', 'c':
+			var a []float64
+			if a, ok = s.numbers(6); ok {
+				c1, c2, p := at(a[0], a[1]), at(a[2], a[3]), at(a[4], a[5])
+				b.cubeTo(c1, c2, p)
+				ctrl = c2
+			}
+		case 'S', 's':
+			var a []float64
+			if a, ok = s.numbers(4); ok {
+				c1 := b.pen
+				if prev == 'C' || prev == 'c' || prev == 'S' || prev == 's' {
+					c1 = point{2*b.pen.x - ctrl.x, 2*b.pen.y - ctrl.y}
+				}
+				c2, p := at(a[0], a[1]), at(a[2], a[3])
+				b.cubeTo(c1, c2, p)
+				ctrl = c2
+			}
+		case 'Q', 'q':
+			var a []float64
+			if a, ok = s.numbers(4); ok {
+				c, p := at(a[0], a[1]), at(a[2], a[3])
+				b.quadTo(c, p)
+				ctrl = c
+			}
+		case 'T', 't':
+			var a []float64
+			if a, ok = s.numbers(2); ok {
+				c := b.pen
+				if prev == 'Q' || prev == 'q' || prev == 'T' || prev == 't' {
+					c = point{2*b.pen.x - ctrl.x, 2*b.pen.y - ctrl.y}
+				}
+				b.quadTo(c, at(a[0], a[1]))
+				ctrl = c
+			}
+		case 'A', 'a':
+			ok = s.arc(&b, at)
+		case 'Z', 'z':
+			b.closePath()
+			ok = true
+		}
+		if !ok {
+			break
+		}
+		prev = cmd
+	}
+	return b.paths
+}
+
+// arc reads parameters of the arc command and adds the arc to the path.
+func (s *pathScanner) arc(b *pathBuilder, at func(x, y float64) point) bool {
+	r, ok := s.numbers(3)
+	if !ok {
+		return false
+	}
+	large, ok := s.flag()
+	if !ok {
+		return false
+	}
+	sweep, ok := s.flag()
+	if !ok {
+		return false
+	}
+	p, ok := s.numbers(2)
+	if !ok {
+		return false
+	}
+	b.arcTo(r[0], r[1], r[2], large, sweep, at(p[0], p[1]))
+	return true
+}
@@ -0,0 +1,326 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"golang.org/x/image/vector"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// ErrUnsupported is returned if the SVG image uses features the renderer can not draw,
+// the image would be rendered incomplete.
+var ErrUnsupported = errors.New("svg features not supported by the renderer")
+
+// unrenderedElements is the set of elements skipped along with their content by the renderer;
+// these are not visible directly.
+var unrenderedElements = map[string]bool{
+	"defs":          true,
+	"symbol":        true,
+	"title":         true,
+	"desc":          true,
+	"metadata":      true,
+	"script":        true,
+	"foreignobject": true,
+}
+
+// unsupportedElements is the set of elements the renderer can not draw;
+// images containing any of them are refused with ErrUnsupported.
+var unsupportedElements = map[string]bool{
+	"text":           true,
+	"textpath":       true,
+	"tspan":          true,
+	"use":            true,
+	"image":          true,
+	"style":          true,
+	"clippath":       true,
+	"mask":           true,
+	"pattern":        true,
+	"marker":         true,
+	"filter":         true,
+	"lineargradient": true,
+	"radialgradient": true,
+}
+
+// renderer draws shapes of an SVG image on a raster canvas.
+type renderer struct {
+	canvas *image.RGBA
+	size   int
+}
+
+// Rasterize renders the SVG image into a raster image fitting the given size in pixels.
+// Only a basic subset of SVG is rendered - shapes and paths with solid fills and strokes;
+// images with texts, gradients, patterns, masks, filters, style sheets, embedded images
+// or referenced (use) elements are refused with ErrUnsupported.
+func Rasterize(data []byte, size int) (*image.RGBA, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid raster size %d", size)
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	r := renderer{size: size}
+	stack := make([]style, 0, 16)
+	var skip int
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid svg; %s", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if unsupportedElements[name] {
+				return nil, fmt.Errorf("%w; %s element found", ErrUnsupported, name)
+			}
+			if skip > 0 || unrenderedElements[name] {
+				skip++
+				continue
+			}
+
+			attrs := make(map[string]string, len(t.Attr))
+			for _, a := range t.Attr {
+				attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+
+			parent := defaultStyle
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+
+			// the outermost element sets the canvas
+			if r.canvas == nil {
+				if name != "svg" {
+					return nil, fmt.Errorf("svg root element expected, %s found", name)
+				}
+				vp, err := r.viewport(attrs)
+				if err != nil {
+					return nil, err
+				}
+				parent.transform = vp
+			}
+
+			st := parent.inherit(attrs)
+			stack = append(stack, st)
+			if !st.hidden {
+				r.drawElement(name, attrs, st)
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if r.canvas == nil {
+		return nil, fmt.Errorf("svg root element not found")
+	}
+	return r.canvas, nil
+}
+
+// viewport creates the canvas for the root element and provides the transformation
+// of the user space into the canvas, so the view box fits the canvas.
+func (r *renderer) viewport(attrs map[string]string) (matrix, error) {
+	var minX, minY, w, h float64
+	if vb := parseNumbers(attrs["viewbox"]); len(vb) == 4 {
+		minX, minY, w, h = vb[0], vb[1], vb[2], vb[3]
+	} else {
+		w, h = parseLength(attrs["width"], 0), parseLength(attrs["height"], 0)
+	}
+	if w <= 0 || h <= 0 {
+		return identity, fmt.Errorf("svg size not known")
+	}
+
+	scale := float64(r.size) / math.Max(w, h)
+	cw, ch := int(math.Max(1, math.Round(w*scale))), int(math.Max(1, math.Round(h*scale)))
+	r.canvas = image.NewRGBA(image.Rect(0, 0, cw, ch))
+	return matrix{scale, 0, 0, scale, -minX * scale, -minY * scale}, nil
+}
+
+// drawElement draws the shape element with the given style.
+func (r *renderer) drawElement(name string, attrs map[string]string, st style) {
+	num := func(key string) float64 {
+		return parseLength(attrs[key], 0)
+	}
+
+	var b pathBuilder
+	switch name {
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		if w <= 0 || h <= 0 {
+			return
+		}
+		rx, ry := num("rx"), num("ry")
+		if _, ok := attrs["rx"]; !ok {
+			rx = ry
+		}
+		if _, ok := attrs["ry"]; !ok {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+		if rx <= 0 || ry <= 0 {
+			b.moveTo(point{x, y})
+			b.lineTo(point{x + w, y})
+			b.lineTo(point{x + w, y + h})
+			b.lineTo(point{x, y + h})
+		} else {
+			b.moveTo(point{x + rx, y})
+			b.lineTo(point{x + w - rx, y})
+			b.ellipseArc(point{x + w - rx, y + ry}, rx, ry, 0, -math.Pi/2, math.Pi/2)
+			b.lineTo(point{x + w, y + h - ry})
+			b.ellipseArc(point{x + w - rx, y + h - ry}, rx, ry, 0, 0, math.Pi/2)
+			b.lineTo(point{x + rx, y + h})
+			b.ellipseArc(point{x + rx, y + h - ry}, rx, ry, 0, math.Pi/2, math.Pi/2)
+			b.lineTo(point{x, y + ry})
+			b.ellipseArc(point{x + rx, y + ry}, rx, ry, 0, math.Pi, math.Pi/2)
+		}
+		b.closePath()
+	case "circle", "ellipse":
+		rx, ry := num("rx"), num("ry")
+		if name == "circle" {
+			rx, ry = num("r"), num("r")
+		}
+		if rx <= 0 || ry <= 0 {
+			return
+		}
+		c := point{num("cx"), num("cy")}
+		b.moveTo(point{c.x + rx, c.y})
+		b.ellipseArc(c, rx, ry, 0, 0, 2*math.Pi)
+		b.closePath()
+	case "line":
+		b.moveTo(point{num("x1"), num("y1")})
+		b.lineTo(point{num("x2"), num("y2")})
+	case "polyline", "polygon":
+		coords := parseNumbers(attrs["points"])
+		for i := 0; i+1 < len(coords); i += 2 {
+			if i == 0 {
+				b.moveTo(point{coords[i], coords[i+1]})
+			} else {
+				b.lineTo(point{coords[i], coords[i+1]})
+			}
+		}
+		if name == "polygon" {
+			b.closePath()
+		}
+	case "path":
+		b.paths = parsePath(attrs["d"])
+	default:
+		return
+	}
+
+	paths := transform(b.paths, st.transform)
+	if st.fill != nil && name != "line" {
+		r.fill(paths, *st.fill, st.fillOpacity*st.opacity)
+	}
+	if st.stroke != nil && st.strokeWidth > 0 {
+		r.stroke(paths, *st.stroke, st.strokeOpacity*st.opacity, st.strokeWidth*st.transform.scale())
+	}
+}
+
+// transform applies the transformation to all the points of the paths.
+func transform(paths []subpath, m matrix) []subpath {
+	out := make([]subpath, len(paths))
+	for i, sp := range paths {
+		out[i] = subpath{pts: make([]point, len(sp.pts)), closed: sp.closed}
+		for j, p := range sp.pts {
+			out[i].pts[j].x, out[i].pts[j].y = m.apply(p.x, p.y)
+		}
+	}
+	return out
+}
+
+// fill paints the inside of the paths; all subpaths are filled as closed.
+func (r *renderer) fill(paths []subpath, c color.NRGBA, opacity float64) {
+	z := vector.NewRasterizer(r.canvas.Bounds().Dx(), r.canvas.Bounds().Dy())
+	for _, sp := range paths {
+		addPolygon(z, sp.pts)
+	}
+	r.paint(z, c, opacity)
+}
+
+// stroke paints the outline of the paths with the given width; joins and caps are round.
+func (r *renderer) stroke(paths []subpath, c color.NRGBA, opacity float64, width float64) {
+	z := vector.NewRasterizer(r.canvas.Bounds().Dx(), r.canvas.Bounds().Dy())
+	hw := width / 2
+
+	// all the pieces are added in the same orientation, so overlaps do not cancel out
+	for _, sp := range paths {
+		n := len(sp.pts)
+		segments := n - 1
+		if sp.closed {
+			segments = n
+		}
+		for i := 0; i < segments; i++ {
+			p0, p1 := sp.pts[i], sp.pts[(i+1)%n]
+			dx, dy := p1.x-p0.x, p1.y-p0.y
+			l := math.Hypot(dx, dy)
+			if l == 0 {
+				continue
+			}
+			nx, ny := -dy/l*hw, dx/l*hw
+			addPolygon(z, orient([]point{
+				{p0.x + nx, p0.y + ny},
+				{p1.x + nx, p1.y + ny},
+				{p1.x - nx, p1.y - ny},
+				{p0.x - nx, p0.y - ny},
+			}))
+		}
+		for _, p := range sp.pts {
+			var b pathBuilder
+			b.moveTo(point{p.x + hw, p.y})
+			b.ellipseArc(p, hw, hw, 0, 0, 2*math.Pi)
+			addPolygon(z, orient(b.paths[0].pts))
+		}
+	}
+	r.paint(z, c, opacity)
+}
+
+// paint draws the rasterized mask with the given color on the canvas.
+func (r *renderer) paint(z *vector.Rasterizer, c color.NRGBA, opacity float64) {
+	c.A = uint8(math.Round(float64(c.A) * opacity))
+	if c.A == 0 {
+		return
+	}
+	z.Draw(r.canvas, r.canvas.Bounds(), image.NewUniform(c), image.Point{})
+}
+
+// addPolygon adds the closed polygon to the rasterizer.
+func addPolygon(z *vector.Rasterizer, pts []point) {
+	if len(pts) < 3 {
+		return
+	}
+	z.MoveTo(float32(pts[0].x), float32(pts[0].y))
+	for _, p := range pts[1:] {
+		z.LineTo(float32(p.x), float32(p.y))
+	}
+	z.ClosePath()
+}
+
+// orient makes sure the polygon is oriented clockwise (on screen).
+func orient(pts []point) []point {
+	var area float64
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		area += pts[i].x*pts[j].y - pts[j].x*pts[i].y
+	}
+	if area < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	return pts
+}
@@ -0,0 +1,162 @@
+// Package svg provides tools for safe serving of user supplied SVG images,
+// i.e. sanitization of active content and rasterization of the images.
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// forbiddenElements is the set of elements removed from SVG images along with their content.
+var forbiddenElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"object":        true,
+	"embed":         true,
+	"audio":         true,
+	"video":         true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// reUrl matches CSS url() references.
+var reUrl = regexp.MustCompile(`(?i)url\(\s*['"]?([^'")]*)`)
+
+// reActiveContent matches script URLs and legacy CSS expressions.
+var reActiveContent = regexp.MustCompile(`(?i)(javascript|vbscript)\s*:|expression\s*\(|@import`)
+
+// Sanitize removes scripts, event handlers and references to external resources from the SVG image.
+// Local references (#id) and inline raster images (data:image/...) are kept.
+func Sanitize(data []byte) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	var out bytes.Buffer
+	var skip int
+
+	// elements nested in a style must not end the style context, so the depth is tracked
+	var styleDepth int
+	for {
+		// raw tokens keep namespace prefixes as they are in the source
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid svg; %s", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || forbiddenElements[strings.ToLower(t.Name.Local)] {
+				skip++
+				continue
+			}
+			if styleDepth > 0 || strings.EqualFold(t.Name.Local, "style") {
+				styleDepth++
+			}
+			writeStartElement(&out, t)
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if styleDepth > 0 {
+				styleDepth--
+			}
+			out.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			if styleDepth > 0 && !isSafeCss(string(t)) {
+				continue
+			}
+			_ = xml.EscapeText(&out, t)
+		case xml.Comment:
+			// comments are dropped, they may hide conditional content for old browsers
+		case xml.ProcInst:
+			if skip == 0 && t.Target == "xml" {
+				out.WriteString("<?xml " + string(t.Inst) + "?>")
+			}
+		case xml.Directive:
+			// DOCTYPE and entity declarations are dropped
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// writeStartElement writes the element start tag with safe attributes only.
+func writeStartElement(out *bytes.Buffer, el xml.StartElement) {
+	out.WriteString("<" + qualifiedName(el.Name))
+	for _, attr := range el.Attr {
+		if !isSafeAttribute(attr) {
+			continue
+		}
+		out.WriteString(" " + qualifiedName(attr.Name) + `="`)
+		_ = xml.EscapeText(out, []byte(attr.Value))
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+// isSafeAttribute checks if the attribute can be kept in the sanitized image.
+func isSafeAttribute(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+
+	// event handlers
+	if strings.HasPrefix(name, "on") {
+		return false
+	}
+
+	// references may point to local elements or inline images only
+	if name == "href" || name == "src" {
+		return isSafeReference(attr.Value)
+	}
+
+	// animations must not change references
+	if name == "attributename" && strings.Contains(strings.ToLower(attr.Value), "href") {
+		return false
+	}
+	return isSafeCss(attr.Value)
+}
+
+// isSafeReference checks if the reference targets a local element or an inline raster image.
+func isSafeReference(ref string) bool {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if strings.HasPrefix(ref, "#") {
+		return true
+	}
+	for _, prefix := range []string{"data:image/png", "data:image/jpeg", "data:image/gif", "data:image/webp"} {
+		if strings.HasPrefix(ref, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSafeCss checks the value does not contain scripts or references to external resources.
+func isSafeCss(value string) bool {
+	if reActiveContent.MatchString(value) {
+		return false
+	}
+	for _, m := range reUrl.FindAllStringSubmatch(value, -1) {
+		if !isSafeReference(m[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// qualifiedName provides the name of the element or attribute with its namespace prefix.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,270 @@
+package svg
+
+import (
+	"image/color"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// matrix represents an affine transformation [a b c d e f];
+// x' = a*x + c*y + e, y' = b*x + d*y + f
+type matrix [6]float64
+
+// identity is the identity transformation.
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul provides the transformation applying n first and m after it.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+// apply transforms the given point.
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// scale provides the average scale factor of the transformation, used for stroke widths.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// style represents the inherited presentation attributes of an element.
+type style struct {
+	fill          *color.NRGBA
+	stroke        *color.NRGBA
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64
+	strokeWidth   float64
+	transform     matrix
+	hidden        bool
+}
+
+// defaultStyle is the style of the root element.
+var defaultStyle = style{
+	fill:          &color.NRGBA{A: 0xff},
+	fillOpacity:   1,
+	strokeOpacity: 1,
+	opacity:       1,
+	strokeWidth:   1,
+	transform:     identity,
+}
+
+// inherit creates the style of a child element with the given attributes.
+func (s style) inherit(attrs map[string]string) style {
+	out := s
+
+	// the style attribute overrides presentation attributes
+	if css, ok := attrs["style"]; ok {
+		for _, decl := range strings.Split(css, ";") {
+			kv := strings.SplitN(decl, ":", 2)
+			if len(kv) == 2 {
+				attrs[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+			}
+		}
+	}
+
+	for key, val := range attrs {
+		switch key {
+		case "fill":
+			if c, ok := parseColor(val); ok {
+				out.fill = c
+			}
+		case "stroke":
+			if c, ok := parseColor(val); ok {
+				out.stroke = c
+			}
+		case "fill-opacity":
+			out.fillOpacity = parseOpacity(val, out.fillOpacity)
+		case "stroke-opacity":
+			out.strokeOpacity = parseOpacity(val, out.strokeOpacity)
+		case "opacity":
+			// group opacity is approximated by the opacity of the elements
+			out.opacity = s.opacity * parseOpacity(val, 1)
+		case "stroke-width":
+			out.strokeWidth = parseLength(val, out.strokeWidth)
+		case "display":
+			out.hidden = out.hidden || strings.TrimSpace(val) == "none"
+		case "transform":
+			out.transform = s.transform.mul(parseTransform(val))
+		}
+	}
+	return out
+}
+
+// reNumber matches a number in attribute values.
+var reNumber = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// parseLength decodes the length value; units are ignored.
+func parseLength(val string, def float64) float64 {
+	num := reNumber.FindString(val)
+	if num == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// parseNumbers decodes the list of numbers.
+func parseNumbers(val string) []float64 {
+	list := reNumber.FindAllString(val, -1)
+	out := make([]float64, 0, len(list))
+	for _, num := range list {
+		f, err := strconv.ParseFloat(num, 64)
+		if err == nil {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// parseOpacity decodes the opacity value clamped into the <0, 1> range.
+func parseOpacity(val string, def float64) float64 {
+	val = strings.TrimSpace(val)
+	f := parseLength(val, def)
+	if strings.HasSuffix(val, "%") {
+		f /= 100
+	}
+	return math.Max(0, math.Min(1, f))
+}
+
+// reTransform matches a single transformation function.
+var reTransform = regexp.MustCompile(`(?i)(matrix|translate|scale|rotate|skewx|skewy)\s*\(([^)]*)\)`)
+
+// parseTransform decodes the transform attribute.
+func parseTransform(val string) matrix {
+	m := identity
+	for _, fn := range reTransform.FindAllStringSubmatch(val, -1) {
+		args := parseNumbers(fn[2])
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+
+		var t matrix
+		switch strings.ToLower(fn[1]) {
+		case "matrix":
+			if len(args) != 6 {
+				continue
+			}
+			copy(t[:], args)
+		case "translate":
+			t = matrix{1, 0, 0, 1, arg(0, 0), arg(1, 0)}
+		case "scale":
+			t = matrix{arg(0, 1), 0, 0, arg(1, arg(0, 1)), 0, 0}
+		case "rotate":
+			a := arg(0, 0) * math.Pi / 180
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = matrix{1, 0, 0, 1, cx, cy}.
+				mul(matrix{math.Cos(a), math.Sin(a), -math.Sin(a), math.Cos(a), 0, 0}).
+				mul(matrix{1, 0, 0, 1, -cx, -cy})
+		case "skewx":
+			t = matrix{1, 0, math.Tan(arg(0, 0) * math.Pi / 180), 1, 0, 0}
+		case "skewy":
+			t = matrix{1, math.Tan(arg(0, 0) * math.Pi / 180), 0, 1, 0, 0}
+		}
+		m = m.mul(t)
+	}
+	return m
+}
+
+// namedColors is the set of the most common named colors.
+var namedColors = map[string]color.NRGBA{
+	"black":   {0, 0, 0, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"red":     {0xff, 0, 0, 0xff},
+	"green":   {0, 0x80, 0, 0xff},
+	"lime":    {0, 0xff, 0, 0xff},
+	"blue":    {0, 0, 0xff, 0xff},
+	"yellow":  {0xff, 0xff, 0, 0xff},
+	"cyan":    {0, 0xff, 0xff, 0xff},
+	"aqua":    {0, 0xff, 0xff, 0xff},
+	"magenta": {0xff, 0, 0xff, 0xff},
+	"fuchsia": {0xff, 0, 0xff, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"grey":    {0x80, 0x80, 0x80, 0xff},
+	"silver":  {0xc0, 0xc0, 0xc0, 0xff},
+	"maroon":  {0x80, 0, 0, 0xff},
+	"olive":   {0x80, 0x80, 0, 0xff},
+	"teal":    {0, 0x80, 0x80, 0xff},
+	"navy":    {0, 0, 0x80, 0xff},
+	"purple":  {0x80, 0, 0x80, 0xff},
+	"orange":  {0xff, 0xa5, 0, 0xff},
+	"pink":    {0xff, 0xc0, 0xcb, 0xff},
+	"brown":   {0xa5, 0x2a, 0x2a, 0xff},
+	"gold":    {0xff, 0xd7, 0, 0xff},
+}
+
+// parseColor decodes the paint value; nil color means no paint.
+// The flag is false if the value is not understood and the inherited paint should be kept.
+func parseColor(val string) (*color.NRGBA, bool) {
+	val = strings.ToLower(strings.TrimSpace(val))
+
+	// paint servers (gradients, patterns) are not supported, the fallback color is used if any
+	if strings.HasPrefix(val, "url(") {
+		if end := strings.Index(val, ")"); end > 0 && strings.TrimSpace(val[end+1:]) != "" {
+			return parseColor(val[end+1:])
+		}
+		return nil, true
+	}
+
+	switch {
+	case val == "none" || val == "transparent":
+		return nil, true
+	case val == "currentcolor":
+		return &color.NRGBA{A: 0xff}, true
+	case strings.HasPrefix(val, "#"):
+		return parseHexColor(val[1:])
+	case strings.HasPrefix(val, "rgb"):
+		args := parseNumbers(val)
+		if len(args) < 3 {
+			return nil, false
+		}
+		pct := strings.Contains(val, "%")
+		ch := func(f float64) uint8 {
+			if pct {
+				f = f * 255 / 100
+			}
+			return uint8(math.Max(0, math.Min(255, math.Round(f))))
+		}
+		c := color.NRGBA{R: ch(args[0]), G: ch(args[1]), B: ch(args[2]), A: 0xff}
+		if len(args) > 3 {
+			c.A = uint8(math.Max(0, math.Min(1, args[3])) * 255)
+		}
+		return &c, true
+	}
+
+	if c, ok := namedColors[val]; ok {
+		return &c, true
+	}
+	return nil, false
+}
+
+// parseHexColor decodes #rgb and #rrggbb colors.
+func parseHexColor(hex string) (*color.NRGBA, bool) {
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return nil, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+	return &color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
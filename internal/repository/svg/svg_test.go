@@ -0,0 +1,63 @@
+package svg
+
+import (
+	"errors"
+	"github.com/onsi/gomega"
+	"image/color"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	out, err := Sanitize([]byte(`<?xml version="1.0"?>
+<!DOCTYPE svg [<!ENTITY x "y">]>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10" onload="alert(1)">
+<script>alert(2)</script>
+<style>@import url(https://evil.example/x.css);</style>
+<style><x/>@import url(https://evil.example/y.css);</style>
+<a xlink:href="javascript:alert(3)"><rect width="10" height="10" fill="url(#g)" onclick="alert(4)"/></a>
+<image href="https://tracker.example/pixel.png"/>
+<image xlink:href="data:image/png;base64,iVBORw0KGgo="/>
+<foreignObject><div>html</div></foreignObject>
+<circle style="fill:url(http://evil.example/#a)" r="1"/>
+</svg>`))
+	g.Expect(err).To(gomega.BeNil())
+
+	s := string(out)
+	g.Expect(s).ToNot(gomega.ContainSubstring("alert"))
+	g.Expect(s).ToNot(gomega.ContainSubstring("evil.example"))
+	g.Expect(s).ToNot(gomega.ContainSubstring("tracker.example"))
+	g.Expect(s).ToNot(gomega.ContainSubstring("ENTITY"))
+	g.Expect(s).ToNot(gomega.ContainSubstring("html"))
+	g.Expect(s).To(gomega.ContainSubstring(`fill="url(#g)"`))
+	g.Expect(s).To(gomega.ContainSubstring(`xlink:href="data:image/png;base64,iVBORw0KGgo="`))
+	g.Expect(s).To(gomega.ContainSubstring(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10">`))
+}
+
+func TestRasterize(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	img, err := Rasterize([]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 20 10">
+<rect width="10" height="10" fill="red"/>
+<g transform="translate(10 0)" style="fill:#00f">
+	<path d="M0,0h10v10z"/>
+	<title>not rendered</title>
+</g>
+<line x1="0" y1="5" x2="20" y2="5" stroke="lime" stroke-width=".5" opacity="0"/>
+</svg>`), 100)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(img.Bounds().Dx()).To(gomega.Equal(100))
+	g.Expect(img.Bounds().Dy()).To(gomega.Equal(50))
+
+	g.Expect(img.RGBAAt(25, 25)).To(gomega.Equal(color.RGBA{R: 0xff, A: 0xff}))
+	g.Expect(img.RGBAAt(95, 40)).To(gomega.Equal(color.RGBA{B: 0xff, A: 0xff}))
+	g.Expect(img.RGBAAt(55, 40)).To(gomega.Equal(color.RGBA{}))
+
+	_, err = Rasterize([]byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 100)
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	// images the renderer can not draw completely are refused
+	_, err = Rasterize([]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><text>x</text></svg>`), 100)
+	g.Expect(errors.Is(err, ErrUnsupported)).To(gomega.BeTrue())
+}
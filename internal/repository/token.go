@@ -2,6 +2,8 @@
 package repository
 
 import (
+	"artion-api-graphql/internal/repository/svg"
+	"artion-api-graphql/internal/repository/uri"
 	"artion-api-graphql/internal/types"
 	"artion-api-graphql/internal/types/sorting"
 	"fmt"
@@ -28,14 +30,40 @@ func (p *Proxy) Token(contract *common.Address, tokenId *hexutil.Big) (*types.To
 // StoreToken puts the given token into the persistent storage.
 // The function is used for both insert and update operation.
 func (p *Proxy) StoreToken(token *types.Token) error {
+	if token != nil {
+		token.Cids = tokenContentCids(token)
+	}
 	return p.db.StoreToken(token)
 }
 
 // UpdateTokenMetadata updates basic metadata of the NFT token.
 func (p *Proxy) UpdateTokenMetadata(nft *types.Token) error {
+	if nft != nil {
+		nft.Cids = tokenContentCids(nft)
+	}
 	return p.db.UpdateTokenMetadata(nft)
 }
 
+// StoreTokenMetadataVersion stores the metadata version of a token; returns true if the version is new.
+func (p *Proxy) StoreTokenMetadataVersion(mv *types.TokenMetadataVersion) (bool, error) {
+	return p.db.StoreTokenMetadataVersion(mv)
+}
+
+// IsCollectionTokenCreator checks if the given user created any token of the given collection.
+func (p *Proxy) IsCollectionTokenCreator(contract *common.Address, user *common.Address) (bool, error) {
+	return p.db.IsCollectionTokenCreator(contract, user)
+}
+
+// NextMetadataChangeSequence provides the sequence number of the next metadata change recorded at the given block.
+func (p *Proxy) NextMetadataChangeSequence(blk uint64) (int64, error) {
+	return p.db.NextMetadataChangeSequence(blk)
+}
+
+// TokenMetadataHistory provides the list of metadata versions of the given token, the latest version goes first.
+func (p *Proxy) TokenMetadataHistory(contract *common.Address, tokenID *hexutil.Big) ([]*types.TokenMetadataVersion, error) {
+	return p.db.TokenMetadataHistory(contract, tokenID)
+}
+
 // UpdateTokenMetadataRefreshSchedule sets the NFT metadata update schedule time.
 func (p *Proxy) UpdateTokenMetadataRefreshSchedule(nft *types.Token) error {
 	return p.db.UpdateTokenMetadataRefreshSchedule(nft)
@@ -46,6 +74,78 @@ func (p *Proxy) TokenMetadataRefreshSet() ([]*types.Token, error) {
 	return p.db.TokenMetadataRefreshSet()
 }
 
+// CollectionMetadataRefreshSet pulls up to the given number of NFT tokens of a collection to have their metadata refreshed.
+func (p *Proxy) CollectionMetadataRefreshSet(contract *common.Address, limit int64) ([]*types.Token, error) {
+	return p.db.CollectionMetadataRefreshSet(contract, limit)
+}
+
+// MetadataErrorCategory provides the category of the error received when downloading NFT metadata or media.
+func (p *Proxy) MetadataErrorCategory(err error) string {
+	return uri.ErrorCategory(err)
+}
+
+// MetadataFailureGroups provides the number of tokens failing on the metadata update grouped by the failure category and host.
+func (p *Proxy) MetadataFailureGroups() ([]*types.MetadataFailureGroup, error) {
+	return p.db.MetadataFailureGroups()
+}
+
+// MetadataFailingTokens provides up to the given number of tokens failing on the metadata update with the given category on the given host.
+func (p *Proxy) MetadataFailingTokens(category string, host string, limit int64) ([]*types.Token, error) {
+	return p.db.MetadataFailingTokens(category, host, limit)
+}
+
+// RetryFailedMetadata schedules immediate metadata update of tokens failing with the given category on the given host.
+func (p *Proxy) RetryFailedMetadata(category *string, host *string) (int64, error) {
+	return p.db.RetryFailedMetadata(category, host)
+}
+
+// TokenUpdateThumbnailStatus sets the state of the pre-generated image thumbnail of the given NFT.
+func (p *Proxy) TokenUpdateThumbnailStatus(contract *common.Address, tokenID *big.Int, status types.ThumbnailStatus) error {
+	return p.db.TokenUpdateThumbnailStatus(contract, tokenID, status)
+}
+
+// TokenThumbnailSweepSet pulls up to the given number of NFT tokens with an image,
+// which thumbnail has never been generated, or is pending since before the given time.
+func (p *Proxy) TokenThumbnailSweepSet(pendingBefore time.Time, limit int64) ([]*types.Token, error) {
+	return p.db.TokenThumbnailSweepSet(pendingBefore, limit)
+}
+
+// TokenUpdateSupply sets the total supply and the number of holders of the given NFT.
+func (p *Proxy) TokenUpdateSupply(contract *common.Address, tokenID *big.Int, supply *big.Int, holders int64) error {
+	return p.db.TokenUpdateSupply(contract, tokenID, supply, holders)
+}
+
+// TokenMarkSupplyChecked sets the total supply and the number of holders of the given NFT
+// verified on chain and schedules the next supply check.
+func (p *Proxy) TokenMarkSupplyChecked(contract *common.Address, tokenID *big.Int, supply *big.Int, holders int64) error {
+	return p.db.TokenMarkSupplyChecked(contract, tokenID, supply, holders)
+}
+
+// TokenSupplyCheckSet pulls a set of NFT tokens scheduled for the on-chain supply check up to this time.
+func (p *Proxy) TokenSupplyCheckSet() ([]*types.Token, error) {
+	return p.db.TokenSupplyCheckSet()
+}
+
+// CollectionsWithTraits provides the list of collections having tokens with traits.
+func (p *Proxy) CollectionsWithTraits() ([]common.Address, error) {
+	return p.db.CollectionsWithTraits()
+}
+
+// CollectionTokensTraits loads traits of all the tokens of the given collection.
+func (p *Proxy) CollectionTokensTraits(contract *common.Address) ([]*types.Token, error) {
+	return p.db.CollectionTokensTraits(contract)
+}
+
+// TokensUpdateRarity stores the rarity scores and ranks of the given tokens.
+func (p *Proxy) TokensUpdateRarity(list []*types.TokenRarity) error {
+	return p.db.TokensUpdateRarity(list)
+}
+
+// CollectionTraits provides the number of tokens of the given collection by their trait values.
+func (p *Proxy) CollectionTraits(contract *common.Address) ([]*types.TraitValueCount, error) {
+	return p.db.CollectionTraits(contract)
+}
+
 // TokenMarkListed marks the given NFT as listed for direct sale for the given price.
 func (p *Proxy) TokenMarkListed(contract *common.Address, tokenID *big.Int, price int64, ts *time.Time) error {
 	return p.db.TokenMarkListed(contract, tokenID, price, ts)
@@ -91,45 +191,227 @@ func (p *Proxy) TokenMarkSold(contract *common.Address, tokenID *big.Int, price
 	return p.db.TokenMarkSold(contract, tokenID, price, ts)
 }
 
+// TokenMarkBurned marks the given NFT as burned by the given address.
+func (p *Proxy) TokenMarkBurned(contract *common.Address, tokenID *big.Int, by *common.Address, ts *time.Time) error {
+	return p.db.TokenMarkBurned(contract, tokenID, by, ts)
+}
+
+// TokenClearBurned removes the burn marks of the given NFT minted again after it has been burned.
+func (p *Proxy) TokenClearBurned(contract *common.Address, tokenID *big.Int) error {
+	return p.db.TokenClearBurned(contract, tokenID)
+}
+
 func (p *Proxy) ListTokens(filter *types.TokenFilter, sorting sorting.TokenSorting, sortDesc bool, cursor types.Cursor, count int, backward bool) (list *types.TokenList, err error) {
 	return p.db.ListTokens(filter, sorting, sortDesc, cursor, count, backward)
 }
 
+// GetTokenJsonMetadata downloads NFT metadata JSON from the given URI.
+// The downloaded document is kept in the persistent metadata cache.
 func (p *Proxy) GetTokenJsonMetadata(uri string) (*types.JsonMetadata, error) {
+	md, _, err := p.downloadTokenJsonMetadata(p.uri, "GetTokenJsonMetadata", uri)
+	return md, err
+}
+
+// GetTokenJsonMetadataData downloads and decodes NFT metadata JSON from the given URI the same way
+// as DownloadTokenJsonMetadata, but the download waits for a busy host instead of failing.
+func (p *Proxy) GetTokenJsonMetadataData(uri string) (*types.JsonMetadata, []byte, error) {
+	return p.downloadTokenJsonMetadata(p.uri, "GetTokenJsonMetadata", uri)
+}
+
+// jsonMetadataDocument represents a downloaded NFT metadata JSON document.
+type jsonMetadataDocument struct {
+	md   *types.JsonMetadata
+	data []byte
+}
+
+// DownloadTokenJsonMetadata downloads and decodes NFT metadata JSON from the given URI.
+// The raw document is provided along with the decoded metadata. The download does not wait
+// for a busy host, it fails with an error of the MetaErrorHostUnavailable category instead.
+func (p *Proxy) DownloadTokenJsonMetadata(uri string) (*types.JsonMetadata, []byte, error) {
+	return p.downloadTokenJsonMetadata(p.bgUri, "DownloadTokenJsonMetadata", uri)
+}
+
+// downloadTokenJsonMetadata downloads and decodes NFT metadata JSON from the given URI using the given downloader.
+func (p *Proxy) downloadTokenJsonMetadata(d *uri.Downloader, prefix string, uri string) (*types.JsonMetadata, []byte, error) {
 	var key strings.Builder
-	key.WriteString("GetTokenJsonMetadata")
+	key.WriteString(prefix)
 	key.WriteString(uri)
 
-	jsonMetadata, err, _ := p.callGroup.Do(key.String(), func() (interface{}, error) {
-		return p.uri.GetJsonMetadata(uri)
+	doc, err, _ := p.callGroup.Do(key.String(), func() (interface{}, error) {
+		data, err := d.GetJsonMetadataData(uri)
+		if err != nil {
+			return nil, err
+		}
+		md, err := types.DecodeJsonMetadata(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode json; %w", err)
+		}
+
+		// on-chain metadata are carried by the URI itself, there is nothing to cache
+		if types.IsDataUri(uri) {
+			return &jsonMetadataDocument{md: md, data: data}, nil
+		}
+
+		// failing cache is not fatal, the document is downloaded again next time
+		if err := p.db.StoreJsonMetadata(uri, data); err != nil {
+			log.Errorf("metadata of %s not cached; %s", uri, err.Error())
+		}
+		return &jsonMetadataDocument{md: md, data: data}, nil
 	})
-	return jsonMetadata.(*types.JsonMetadata), err
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc.(*jsonMetadataDocument).md, doc.(*jsonMetadataDocument).data, nil
 }
 
+// CachedTokenJsonMetadata provides NFT metadata JSON of the given URI from the persistent
+// metadata cache; the document is downloaded only if it has not been fetched before.
+func (p *Proxy) CachedTokenJsonMetadata(uri string) (*types.JsonMetadata, error) {
+	if types.IsDataUri(uri) {
+		return p.GetTokenJsonMetadata(uri)
+	}
+	data, err := p.db.JsonMetadata(uri)
+	if err == nil && data != nil {
+		if md, err := types.DecodeJsonMetadata(data); err == nil {
+			return md, nil
+		}
+	}
+	return p.GetTokenJsonMetadata(uri)
+}
+
+// GetImage downloads the image from the given URI; SVG images are sanitized,
+// so they can be served to clients safely.
 func (p *Proxy) GetImage(uri string) (image *types.Image, err error) {
-	key := "GetImage" + uri
+	return p.getImage(p.uri, "GetImage", uri)
+}
+
+// DownloadImage downloads the image from the given URI the same way as GetImage, but the download
+// does not wait for a busy host, it fails with an error of the MetaErrorHostUnavailable category instead.
+func (p *Proxy) DownloadImage(uri string) (image *types.Image, err error) {
+	return p.getImage(p.bgUri, "DownloadImage", uri)
+}
+
+// getImage downloads and sanitizes the image from the given URI using the given downloader.
+func (p *Proxy) getImage(d *uri.Downloader, prefix string, uri string) (image *types.Image, err error) {
+	key := prefix + uri
 	data, err, _ := p.callGroup.Do(key, func() (interface{}, error) {
-		return p.uri.GetImage(uri)
+		img, err := d.GetImage(uri)
+		if err != nil || img.Type != types.ImageTypeSvg {
+			return img, err
+		}
+		img.Data, err = svg.Sanitize(img.Data)
+		if err != nil {
+			return nil, err
+		}
+		return img, nil
 	})
-	return data.(*types.Image), err
+	if err != nil {
+		return nil, err
+	}
+	return data.(*types.Image), nil
 }
 
-func (p *Proxy) GetImageThumbnail(uri string) (image *types.Image, err error) {
-	key := "GetImageThumbnail" + uri
+// GetMimetype detects mimetype of the media on the given URI.
+func (p *Proxy) GetMimetype(uri string) (string, error) {
+	return p.getMimetype(p.uri, "GetMimetype", uri)
+}
+
+// DetectMimetype detects mimetype of the media on the given URI the same way as GetMimetype, but the request
+// does not wait for a busy host, it fails with an error of the MetaErrorHostUnavailable category instead.
+func (p *Proxy) DetectMimetype(uri string) (string, error) {
+	return p.getMimetype(p.bgUri, "DetectMimetype", uri)
+}
+
+// getMimetype detects mimetype of the media on the given URI using the given downloader.
+func (p *Proxy) getMimetype(d *uri.Downloader, prefix string, uri string) (string, error) {
+	key := prefix + uri
 	data, err, _ := p.callGroup.Do(key, func() (interface{}, error) {
-		image, err := p.GetImage(uri)
-		if err != nil || image == nil {
-			return nil, fmt.Errorf("getImage failed; %s", err)
+		return d.GetMimetype(uri)
+	})
+	if err != nil {
+		return "", err
+	}
+	return data.(string), nil
+}
+
+// GetOriginalMedia provides the original image or video on the given URI.
+// The media is downloaded only once, it's served from the persistent store afterwards.
+func (p *Proxy) GetOriginalMedia(uri string) (*types.Image, error) {
+	// on-chain media are carried by the URI itself, there is nothing to keep
+	if types.IsDataUri(uri) {
+		return p.GetImage(uri)
+	}
+
+	v := types.OriginalVariant
+	key := "GetOriginalMedia" + v.Key(uri)
+	data, err, _ := p.callGroup.Do(key, func() (interface{}, error) {
+		media, err := p.db.Thumbnail(uri, v)
+		if err == nil && media != nil {
+			return media, nil
 		}
-		thumb, err := createThumbnail(*image)
+
+		media, err = p.GetImage(uri)
 		if err != nil {
-			return nil, fmt.Errorf("createThumbnail failed; %s", err)
+			return nil, err
+		}
+		out := *media
+		out.Modified = time.Now().UTC()
+
+		// big originals are not kept, they would occupy the store for a single view
+		if int64(len(out.Data)) > cfg.Media.OriginalMaxSize {
+			return &out, nil
 		}
-		return &thumb, nil
+
+		// failing store is not fatal, the media will be downloaded again next time
+		if err := p.db.StoreThumbnail(uri, v, &out); err != nil {
+			log.Errorf("original media of %s not stored; %s", uri, err.Error())
+		}
+		return &out, nil
 	})
-	return data.(*types.Image), err
+	if err != nil {
+		return nil, err
+	}
+	return data.(*types.Image), nil
+}
+
+// PurgeDerivedMedia removes original media stored before the given time and media stored under outdated keys.
+func (p *Proxy) PurgeDerivedMedia(originalsBefore time.Time) (int, error) {
+	return p.db.PurgeDerivedMedia(originalsBefore)
+}
+
+// GetImageThumbnail provides the default thumbnail of the image on the given URI.
+func (p *Proxy) GetImageThumbnail(uri string) (image *types.Image, err error) {
+	return p.GetImageThumbnailVariant(uri, types.DefaultThumbnailVariant)
 }
 
-func (p *Proxy) UploadTokenData(metadata types.JsonMetadata, image types.Image) (uri string, err error) {
-	return p.pinner.PinTokenData(metadata, image)
+// GetImageThumbnailVariant provides the given variant of the thumbnail of the image on the given URI.
+// The variant is generated only once, it's served from the persistent store afterwards.
+func (p *Proxy) GetImageThumbnailVariant(uri string, v types.ThumbnailVariant) (image *types.Image, err error) {
+	key := "GetImageThumbnail" + v.Key(uri)
+	data, err, _ := p.callGroup.Do(key, func() (interface{}, error) {
+		thumb, err := p.db.Thumbnail(uri, v)
+		if err == nil && thumb != nil {
+			return thumb, nil
+		}
+
+		image, err := p.GetImage(uri)
+		if err != nil {
+			return nil, fmt.Errorf("getImage failed; %w", err)
+		}
+		out, err := createThumbnail(*image, v)
+		if err != nil {
+			return nil, fmt.Errorf("createThumbnail failed; %w", err)
+		}
+		out.Modified = time.Now().UTC()
+
+		// failing store is not fatal, the thumbnail will be generated again next time
+		if err := p.db.StoreThumbnail(uri, v, &out); err != nil {
+			log.Errorf("thumbnail %d of %s not stored; %s", v.Size, uri, err.Error())
+		}
+		return &out, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.(*types.Image), nil
 }
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"artion-api-graphql/internal/repository/uri"
+	"artion-api-graphql/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"time"
+)
+
+// tokenDraftPurgeBatch is the max number of expired token drafts removed in one pass.
+const tokenDraftPurgeBatch = 100
+
+// tokenCidsBackfillBatch is the max number of tokens getting root CIDs of their content in one pass.
+const tokenCidsBackfillBatch = 1000
+
+// UploadTokenData pins the token image, optional rich media and the metadata JSON on IPFS and provides the metadata URI.
+// The upload is recorded as a draft token of the owner, so it can be matched with the mint later.
+func (p *Proxy) UploadTokenData(owner common.Address, metadata types.JsonMetadata, image types.Image, animation []byte) (string, error) {
+	metaUri, cids, err := p.pinner.PinTokenData(&metadata, image, animation)
+	if err != nil {
+		return "", err
+	}
+
+	draft := types.TokenDraft{
+		Path:    uri.IpfsContentPath(metaUri),
+		Uri:     metaUri,
+		Owner:   owner,
+		Name:    metadata.Name,
+		Cids:    cids,
+		Created: types.Time(time.Now().UTC()),
+	}
+	if draft.Path == "" {
+		draft.Path = metaUri
+	}
+	if metadata.Image != nil {
+		draft.ImageURI = *metadata.Image
+	}
+	if metadata.AnimationUrl != nil {
+		draft.AnimationURI = *metadata.AnimationUrl
+	}
+
+	// failing draft is not fatal for the upload, the content is pinned already
+	if err := p.db.StoreTokenDraft(&draft); err != nil {
+		log.Errorf("token draft %s of %s not stored; %s", metaUri, owner.String(), err.Error())
+	}
+	return metaUri, nil
+}
+
+// TokenDrafts provides the list of token drafts uploaded by the owner and not minted yet.
+func (p *Proxy) TokenDrafts(owner common.Address) ([]*types.TokenDraft, error) {
+	return p.db.TokenDrafts(owner)
+}
+
+// MatchTokenDraft links the token draft uploaded under the metadata URI of the token to the minted token.
+// The matched draft is returned, nil if the token has not been minted from a draft.
+func (p *Proxy) MatchTokenDraft(tok *types.Token) (*types.TokenDraft, error) {
+	path := uri.IpfsContentPath(tok.Uri)
+	if path == "" {
+		return nil, nil
+	}
+	return p.db.TokenDraftMinted(path, &tok.Contract, &tok.TokenId, tok.Created)
+}
+
+// PurgeExpiredTokenDrafts removes token drafts not minted since before the given time
+// and unpins their content. The number of drafts removed is returned.
+// Content usage of tokens is known only when all the tokens have root CIDs of their content,
+// so the purge waits for the backfill of tokens stored before.
+func (p *Proxy) PurgeExpiredTokenDrafts(before time.Time) (int, error) {
+	done, err := p.backfillTokenCids()
+	if err != nil || !done {
+		return 0, err
+	}
+
+	list, err := p.db.ExpiredTokenDrafts(before, tokenDraftPurgeBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, draft := range list {
+		if err := p.unpinTokenDraft(draft); err != nil {
+			log.Errorf("content of token draft %s not unpinned; %s", draft.Uri, err.Error())
+			continue
+		}
+		if err := p.db.RemoveTokenDraft(draft.Path); err != nil {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// unpinTokenDraft unpins the content of the token draft not used by any other draft or any known token;
+// the content may have been minted outside the draft, e.g. by a contract not matching drafts.
+func (p *Proxy) unpinTokenDraft(draft *types.TokenDraft) error {
+	for _, cid := range draft.Cids {
+		shared, err := p.db.IsTokenDraftContentShared(cid, draft.Path)
+		if err != nil {
+			return err
+		}
+		if shared {
+			continue
+		}
+
+		canonical := uri.ContentCid(cid)
+		if canonical == "" {
+			canonical = cid
+		}
+		used, err := p.db.IsTokenContentUsed(canonical)
+		if err != nil {
+			return err
+		}
+		if used {
+			log.Noticef("content %s of token draft %s used by a token, keeping it pinned", cid, draft.Uri)
+			continue
+		}
+		if err := p.pinner.Unpin(cid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenContentCids provides the list of root CIDs of IPFS content referenced by the token.
+// The list is never nil, so tokens not referencing any IPFS content are known to be checked.
+func tokenContentCids(tok *types.Token) []string {
+	cids := make([]string, 0, 3)
+	for _, u := range []string{tok.Uri, tok.ImageURI, tok.AnimationURI} {
+		cid := uri.ContentCid(u)
+		if cid == "" {
+			continue
+		}
+
+		known := false
+		for _, c := range cids {
+			known = known || c == cid
+		}
+		if !known {
+			cids = append(cids, cid)
+		}
+	}
+	return cids
+}
+
+// backfillTokenCids adds root CIDs of the content to a batch of tokens stored before the CIDs have been kept.
+// Returns true if there is no token left without them.
+func (p *Proxy) backfillTokenCids() (bool, error) {
+	list, err := p.db.TokensWithoutCids(tokenCidsBackfillBatch)
+	if err != nil {
+		return false, err
+	}
+
+	for _, tok := range list {
+		if err := p.db.UpdateTokenCids(&tok.Contract, tok.TokenId.ToInt(), tokenContentCids(tok)); err != nil {
+			return false, err
+		}
+	}
+	if len(list) > 0 {
+		log.Infof("content CIDs added to %d tokens", len(list))
+	}
+	return len(list) < tokenCidsBackfillBatch, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"artion-api-graphql/internal/repository/uploads"
+	"artion-api-graphql/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"io"
+)
+
+// ErrUploadSessionNotFound is returned if the upload session does not exist or it has expired.
+var ErrUploadSessionNotFound = uploads.ErrSessionNotFound
+
+// ErrUploadOffsetMismatch is returned if an upload chunk does not continue where the upload stopped.
+var ErrUploadOffsetMismatch = uploads.ErrOffsetMismatch
+
+// ErrUploadSizeExceeded is returned if an upload chunk exceeds the declared size of the upload.
+var ErrUploadSizeExceeded = uploads.ErrSizeExceeded
+
+// ErrUploadSessionBusy is returned if another chunk of the upload is being received.
+var ErrUploadSessionBusy = uploads.ErrSessionBusy
+
+// ErrUploadTooManySessions is returned if the owner has too many unfinished uploads.
+var ErrUploadTooManySessions = uploads.ErrTooManySessions
+
+// ErrUploadQuotaExceeded is returned if unfinished uploads of the owner would exceed the total size allowed.
+var ErrUploadQuotaExceeded = uploads.ErrQuotaExceeded
+
+// CreateUploadSession starts a new resumable upload of the given owner for a file of the given size.
+func (p *Proxy) CreateUploadSession(owner common.Address, size int64) (*types.UploadSession, error) {
+	return p.uploads.Create(owner, size)
+}
+
+// UploadSession provides the resumable upload session of the given ID.
+func (p *Proxy) UploadSession(id string) (*types.UploadSession, error) {
+	return p.uploads.Session(id)
+}
+
+// AppendUploadChunk adds the chunk starting at the given offset to the resumable upload.
+func (p *Proxy) AppendUploadChunk(id string, offset int64, chunk io.Reader) (*types.UploadSession, error) {
+	return p.uploads.Append(id, offset, chunk)
+}
+
+// UploadSessionData provides the content of the complete resumable upload.
+func (p *Proxy) UploadSessionData(id string) ([]byte, error) {
+	return p.uploads.Data(id)
+}
+
+// RemoveUploadSession drops the resumable upload and its received data.
+func (p *Proxy) RemoveUploadSession(id string) error {
+	return p.uploads.Remove(id)
+}
+
+// PurgeExpiredUploadSessions removes abandoned resumable uploads and provides the number of them removed.
+func (p *Proxy) PurgeExpiredUploadSessions() (int, error) {
+	return p.uploads.PurgeExpired()
+}
@@ -0,0 +1,350 @@
+// Package uploads keeps partial resumable uploads in a local temporary storage.
+package uploads
+
+import (
+	"artion-api-graphql/internal/config"
+	"artion-api-graphql/internal/logger"
+	"artion-api-graphql/internal/types"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// log represents the logger to be used by the repository.
+var log logger.Logger
+
+// ErrSessionNotFound is returned if the upload session does not exist or it has expired.
+var ErrSessionNotFound = errors.New("upload session not found")
+
+// ErrOffsetMismatch is returned if a chunk does not continue where the upload stopped.
+var ErrOffsetMismatch = errors.New("upload offset mismatch")
+
+// ErrSizeExceeded is returned if the chunk exceeds the declared size of the upload.
+var ErrSizeExceeded = errors.New("upload size exceeded")
+
+// ErrSessionBusy is returned if another chunk of the upload is being received.
+var ErrSessionBusy = errors.New("upload session busy")
+
+// ErrTooManySessions is returned if the owner has too many unfinished uploads.
+var ErrTooManySessions = errors.New("too many upload sessions")
+
+// ErrQuotaExceeded is returned if unfinished uploads of the owner would exceed the total size allowed.
+var ErrQuotaExceeded = errors.New("upload quota exceeded")
+
+// sessionIdPattern is the expected format of upload session identifiers.
+var sessionIdPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Store keeps partial uploads in a local directory until they are finished or expired.
+// Each upload is kept in the {id}.part file along with the {id}.json session description.
+// The store lock guards session files only; chunks are received outside the lock
+// and the session is marked busy meanwhile.
+type Store struct {
+	mu          sync.Mutex
+	dir         string
+	expiry      time.Duration
+	maxSessions int
+	maxBytes    int64
+	busy        map[string]bool
+}
+
+// SetLogger sets the repository logger to be used to collect logging info.
+func SetLogger(l logger.Logger) {
+	log = l.ModuleLogger("uploads")
+}
+
+// New provides a new upload store of the configured directory.
+func New(cfg *config.Config) *Store {
+	dir := cfg.Upload.SessionDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "artion-uploads")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		panic(fmt.Errorf("can not create upload directory; %w", err))
+	}
+	return &Store{
+		dir:         dir,
+		expiry:      cfg.Upload.SessionExpiry,
+		maxSessions: cfg.Upload.MaxSessions,
+		maxBytes:    cfg.Upload.MaxSessionBytes,
+		busy:        make(map[string]bool),
+	}
+}
+
+// path provides the path of the session file with the given extension.
+func (s *Store) path(id string, ext string) string {
+	return filepath.Join(s.dir, id+ext)
+}
+
+// Create starts a new upload session of the given owner for a file of the given size.
+// The number of unfinished uploads of the owner and their total declared size are limited.
+func (s *Store) Create(owner common.Address, size int64) (*types.UploadSession, error) {
+	var rnd [16]byte
+	if _, err := rand.Read(rnd[:]); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	us := types.UploadSession{
+		ID:      hex.EncodeToString(rnd[:]),
+		Owner:   owner,
+		Size:    size,
+		Created: now,
+		Expires: now.Add(s.expiry),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkQuota(owner, size); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(s.path(us.ID, ".part"), nil, 0600); err != nil {
+		return nil, err
+	}
+	if err := s.save(&us); err != nil {
+		_ = os.Remove(s.path(us.ID, ".part"))
+		return nil, err
+	}
+	return &us, nil
+}
+
+// Session provides the upload session of the given ID.
+func (s *Store) Session(id string) (*types.UploadSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+// Append adds the chunk read from the reader to the upload; the chunk must start at the current offset
+// of the upload. The updated session is returned; the received part of the chunk is kept even if
+// the reader fails, so the client can resume from the new offset.
+func (s *Store) Append(id string, offset int64, r io.Reader) (*types.UploadSession, error) {
+	us, err := s.acquire(id, offset)
+	if err != nil {
+		return us, err
+	}
+
+	// the chunk is received without the store lock, so a slow client does not block other uploads
+	n, err := s.write(us, r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.busy, id)
+
+	us.Offset += n
+	us.Expires = time.Now().UTC().Add(s.expiry)
+	if e := s.save(us); e != nil && err == nil {
+		err = e
+	}
+	return us, err
+}
+
+// acquire loads the session and marks it busy receiving a chunk starting at the given offset.
+func (s *Store) acquire(id string, offset int64) (*types.UploadSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	us, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.busy[id] {
+		return nil, ErrSessionBusy
+	}
+	if offset != us.Offset {
+		return us, ErrOffsetMismatch
+	}
+	s.busy[id] = true
+	return us, nil
+}
+
+// write appends the chunk to the data file of the busy session and provides the number of bytes kept.
+func (s *Store) write(us *types.UploadSession, r io.Reader) (int64, error) {
+	f, err := os.OpenFile(s.path(us.ID, ".part"), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, err
+	}
+
+	// read one byte over the limit to detect chunks exceeding the declared size
+	n, err := io.Copy(f, io.LimitReader(r, us.Size-us.Offset+1))
+	if us.Offset+n > us.Size {
+		n = us.Size - us.Offset
+		err = ErrSizeExceeded
+		if e := f.Truncate(us.Size); e != nil {
+			log.Errorf("can not truncate upload %s; %s", us.ID, e.Error())
+		}
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	return n, err
+}
+
+// Data provides the content of the complete upload.
+func (s *Store) Data(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	us, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.busy[id] {
+		return nil, ErrSessionBusy
+	}
+	if !us.IsComplete() {
+		return nil, fmt.Errorf("upload %s is not complete, %d of %d bytes received", id, us.Offset, us.Size)
+	}
+	return ioutil.ReadFile(s.path(id, ".part"))
+}
+
+// Remove drops the upload session and the received data.
+func (s *Store) Remove(id string) error {
+	if !sessionIdPattern.MatchString(id) {
+		return ErrSessionNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.busy[id] {
+		return ErrSessionBusy
+	}
+	return s.remove(id)
+}
+
+// PurgeExpired removes the upload sessions expired before now and provides the number of sessions removed.
+// Sessions receiving a chunk are skipped.
+func (s *Store) PurgeExpired() (int, error) {
+	list, err := filepath.Glob(s.path("*", ".json"))
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int
+	for _, path := range list {
+		id := strings.TrimSuffix(filepath.Base(path), ".json")
+		if s.busy[id] {
+			continue
+		}
+		us, err := s.read(id)
+		if err == nil && us.Expires.After(time.Now()) {
+			continue
+		}
+		if err := s.remove(id); err != nil {
+			log.Errorf("can not remove upload %s; %s", id, err.Error())
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// checkQuota verifies the owner may start another upload of the given size
+// on top of the unfinished uploads kept; zero limits are not applied.
+func (s *Store) checkQuota(owner common.Address, size int64) error {
+	if s.maxSessions <= 0 && s.maxBytes <= 0 {
+		return nil
+	}
+
+	list, err := filepath.Glob(s.path("*", ".json"))
+	if err != nil {
+		return err
+	}
+
+	var count int
+	total := size
+	now := time.Now()
+	for _, path := range list {
+		us, err := s.read(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil || us.Owner != owner || us.Expires.Before(now) {
+			continue
+		}
+		count++
+		total += us.Size
+	}
+
+	if s.maxSessions > 0 && count >= s.maxSessions {
+		return ErrTooManySessions
+	}
+	if s.maxBytes > 0 && total > s.maxBytes {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// load reads the session and checks it's still valid; the received offset is taken from the data file.
+func (s *Store) load(id string) (*types.UploadSession, error) {
+	if !sessionIdPattern.MatchString(id) {
+		return nil, ErrSessionNotFound
+	}
+
+	us, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if us.Expires.Before(time.Now()) {
+		return nil, ErrSessionNotFound
+	}
+
+	fi, err := os.Stat(s.path(id, ".part"))
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	us.Offset = fi.Size()
+	return us, nil
+}
+
+// read decodes the session description file.
+func (s *Store) read(id string) (*types.UploadSession, error) {
+	data, err := ioutil.ReadFile(s.path(id, ".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	var us types.UploadSession
+	if err := json.Unmarshal(data, &us); err != nil {
+		return nil, fmt.Errorf("invalid upload session %s; %w", id, err)
+	}
+	return &us, nil
+}
+
+// save writes the session description file.
+func (s *Store) save(us *types.UploadSession) error {
+	data, err := json.Marshal(us)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path(us.ID, ".json"), data, 0600)
+}
+
+// remove deletes the session files.
+func (s *Store) remove(id string) error {
+	if err := os.Remove(s.path(id, ".part")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(s.path(id, ".json")); err != nil {
+		if os.IsNotExist(err) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return nil
+}
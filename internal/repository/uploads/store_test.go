@@ -0,0 +1,147 @@
+package uploads
+
+import (
+	"artion-api-graphql/internal/config"
+	"artion-api-graphql/internal/logger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/onsi/gomega"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCfg is the configuration of tests; the configuration can be loaded only once.
+var testCfg *config.Config
+
+func newTestStore(t *testing.T) *Store {
+	if testCfg == nil {
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		SetLogger(logger.New(cfg))
+		testCfg = cfg
+	}
+
+	cfg := *testCfg
+	cfg.Upload.SessionDir = t.TempDir()
+	return New(&cfg)
+}
+
+func TestAppend(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := newTestStore(t)
+	owner := common.HexToAddress("0x1")
+
+	us, err := s.Create(owner, 10)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(us.Offset).To(gomega.Equal(int64(0)))
+
+	us, err = s.Append(us.ID, 0, strings.NewReader("abcd"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(us.Offset).To(gomega.Equal(int64(4)))
+
+	_, err = s.Append(us.ID, 0, strings.NewReader("abcd"))
+	g.Expect(err).To(gomega.Equal(ErrOffsetMismatch))
+
+	_, err = s.Data(us.ID)
+	g.Expect(err).NotTo(gomega.BeNil())
+
+	us, err = s.Append(us.ID, 4, strings.NewReader("efghijklmn"))
+	g.Expect(err).To(gomega.Equal(ErrSizeExceeded))
+	g.Expect(us.Offset).To(gomega.Equal(int64(10)))
+
+	us, err = s.Session(us.ID)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(us.Owner).To(gomega.Equal(owner))
+	g.Expect(us.IsComplete()).To(gomega.BeTrue())
+
+	data, err := s.Data(us.ID)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(data)).To(gomega.Equal("abcdefghij"))
+
+	g.Expect(s.Remove(us.ID)).To(gomega.BeNil())
+	_, err = s.Session(us.ID)
+	g.Expect(err).To(gomega.Equal(ErrSessionNotFound))
+}
+
+func TestPurgeExpired(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := newTestStore(t)
+
+	active, err := s.Create(common.Address{}, 10)
+	g.Expect(err).To(gomega.BeNil())
+
+	s.expiry = -time.Second
+	expired, err := s.Create(common.Address{}, 10)
+	g.Expect(err).To(gomega.BeNil())
+
+	count, err := s.PurgeExpired()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(count).To(gomega.Equal(1))
+
+	_, err = s.Session(expired.ID)
+	g.Expect(err).To(gomega.Equal(ErrSessionNotFound))
+	_, err = s.Session(active.ID)
+	g.Expect(err).To(gomega.BeNil())
+}
+
+func TestAppendBusy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := newTestStore(t)
+
+	us, err := s.Create(common.Address{}, 10)
+	g.Expect(err).To(gomega.BeNil())
+
+	// a slow chunk keeps the session busy, but not the store
+	pr, pw := io.Pipe()
+	done := make(chan error)
+	go func() {
+		_, err := s.Append(us.ID, 0, pr)
+		done <- err
+	}()
+	_, _ = pw.Write([]byte("abcd"))
+
+	_, err = s.Append(us.ID, 4, strings.NewReader("efgh"))
+	g.Expect(err).To(gomega.Equal(ErrSessionBusy))
+	g.Expect(s.Remove(us.ID)).To(gomega.Equal(ErrSessionBusy))
+
+	other, err := s.Create(common.Address{}, 10)
+	g.Expect(err).To(gomega.BeNil())
+	_, err = s.Append(other.ID, 0, strings.NewReader("abcd"))
+	g.Expect(err).To(gomega.BeNil())
+
+	g.Expect(pw.Close()).To(gomega.BeNil())
+	g.Expect(<-done).To(gomega.BeNil())
+
+	us, err = s.Append(us.ID, 4, strings.NewReader("efgh"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(us.Offset).To(gomega.Equal(int64(8)))
+}
+
+func TestQuota(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := newTestStore(t)
+	s.maxSessions = 2
+	s.maxBytes = 100
+	owner := common.HexToAddress("0x1")
+
+	_, err := s.Create(owner, 60)
+	g.Expect(err).To(gomega.BeNil())
+	_, err = s.Create(owner, 50)
+	g.Expect(err).To(gomega.Equal(ErrQuotaExceeded))
+
+	us, err := s.Create(owner, 40)
+	g.Expect(err).To(gomega.BeNil())
+	_, err = s.Create(owner, 1)
+	g.Expect(err).To(gomega.Equal(ErrTooManySessions))
+
+	// other owners are not affected, finished uploads release the quota
+	_, err = s.Create(common.HexToAddress("0x2"), 100)
+	g.Expect(err).To(gomega.BeNil())
+
+	g.Expect(s.Remove(us.ID)).To(gomega.BeNil())
+	_, err = s.Create(owner, 40)
+	g.Expect(err).To(gomega.BeNil())
+}
@@ -0,0 +1,162 @@
+package uri
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// defaultArweaveGateway is used to resolve ar:// URIs if no gateway is configured.
+const defaultArweaveGateway = "https://arweave.net"
+
+var (
+	// cidV0Pattern matches base58 encoded CIDv0 (sha256 multihash)
+	cidV0Pattern = regexp.MustCompile(`^Qm[1-9A-HJ-NP-Za-km-z]{44}$`)
+
+	// cidV1Pattern matches base32 encoded CIDv1 (lower case, multibase prefix "b")
+	cidV1Pattern = regexp.MustCompile(`^b[a-z2-7]{58,}$`)
+
+	// arweaveTxPattern matches base64url encoded Arweave transaction id
+	arweaveTxPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{43}$`)
+)
+
+// normalizeCid checks the given CID and provides it in the canonical form.
+// Base32 CIDv1 is case-insensitive, it is lower-cased. Empty string is returned for invalid CID.
+func normalizeCid(cid string) string {
+	if cidV0Pattern.MatchString(cid) {
+		return cid
+	}
+	if lc := strings.ToLower(cid); cidV1Pattern.MatchString(lc) {
+		return lc
+	}
+	return ""
+}
+
+// contentPath builds the canonical "/{namespace}/{root}/{subpath}" path of an IPFS or IPNS content.
+// The rest is the part of the URI after the namespace prefix; repeated namespace prefix
+// (like in "ipfs://ipfs/{CID}") and duplicate slashes are removed, the query is kept.
+// Returns empty string if the root is not a valid CID and strict check is requested.
+func contentPath(namespace string, rest string, strict bool) string {
+	query := ""
+	if idx := strings.IndexByte(rest, '?'); idx >= 0 {
+		rest, query = rest[:idx], rest[idx:]
+	}
+
+	rest = strings.TrimLeft(rest, "/")
+	if strings.HasPrefix(rest, namespace+"/") {
+		rest = strings.TrimLeft(rest[len(namespace)+1:], "/")
+	}
+
+	root, sub := rest, ""
+	if idx := strings.IndexByte(rest, '/'); idx >= 0 {
+		root, sub = rest[:idx], rest[idx:]
+	}
+	if root == "" {
+		return ""
+	}
+
+	if namespace == "ipfs" {
+		if cid := normalizeCid(root); cid != "" {
+			root = cid
+		} else if strict {
+			return ""
+		}
+	}
+
+	for strings.Contains(sub, "//") {
+		sub = strings.ReplaceAll(sub, "//", "/")
+	}
+	return "/" + namespace + "/" + root + sub + query
+}
+
+// IpfsContentPath provides the canonical "/ipfs/{CID}/{path}" content path of the IPFS URI,
+// content path or public IPFS HTTP gateway URL. Empty string is returned for other URIs.
+func IpfsContentPath(uri string) string {
+	var path string
+	switch {
+	case strings.HasPrefix(uri, "/ipfs/"):
+		path = contentPath("ipfs", uri[6:], true)
+	case strings.HasPrefix(uri, "ipfs://"):
+		path = contentPath("ipfs", uri[7:], true)
+	default:
+		path = gatewayContentPath(uri)
+	}
+	if !strings.HasPrefix(path, "/ipfs/") {
+		return ""
+	}
+	return path
+}
+
+// ContentCid provides the canonical root CID of the IPFS content referenced by the URI, content path
+// or public IPFS HTTP gateway URL; a bare CID is accepted too. Empty string is returned for other URIs.
+func ContentCid(uri string) string {
+	path := IpfsContentPath(uri)
+	if path == "" {
+		path = IpfsContentPath("/ipfs/" + uri)
+	}
+	if path == "" {
+		return ""
+	}
+
+	root := path[6:]
+	if idx := strings.IndexAny(root, "/?"); idx >= 0 {
+		root = root[:idx]
+	}
+	return root
+}
+
+// gatewayContentPath converts URL of a public IPFS HTTP gateway into the IPFS/IPNS content path.
+// Both path style ("https://{gateway}/ipfs/{CID}/{path}") and subdomain style
+// ("https://{CID}.ipfs.{gateway}/{path}") gateways are recognized. Empty string is returned
+// if the URL does not reference a valid IPFS content.
+func gatewayContentPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	rest := u.EscapedPath()
+	if u.RawQuery != "" {
+		rest += "?" + u.RawQuery
+	}
+
+	// subdomain style gateway
+	labels := strings.Split(strings.ToLower(u.Hostname()), ".")
+	if len(labels) > 2 && labels[1] == "ipfs" {
+		return contentPath("ipfs", labels[0]+rest, true)
+	}
+	if len(labels) > 2 && labels[1] == "ipns" {
+		return contentPath("ipns", labels[0]+rest, true)
+	}
+
+	// path style gateway
+	if strings.HasPrefix(rest, "/ipfs/") {
+		return contentPath("ipfs", rest[6:], true)
+	}
+	if strings.HasPrefix(rest, "/ipns/") {
+		return contentPath("ipns", rest[6:], true)
+	}
+	return ""
+}
+
+// getArweaveUrl converts the ar:// URI into the Arweave gateway URL - returns empty string for non-arweave uri.
+func (d *Downloader) getArweaveUrl(uri string) string {
+	if !strings.HasPrefix(uri, "ar://") {
+		return ""
+	}
+
+	rest := strings.TrimLeft(uri[5:], "/")
+	tx := rest
+	if idx := strings.IndexAny(rest, "/?"); idx >= 0 {
+		tx = rest[:idx]
+	}
+	if !arweaveTxPattern.MatchString(tx) {
+		return ""
+	}
+
+	gateway := d.arweaveGateway
+	if gateway == "" {
+		gateway = defaultArweaveGateway
+	}
+	return strings.TrimRight(gateway, "/") + "/" + rest
+}
@@ -0,0 +1,122 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/types"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultDataUriMimetype is the media type of a data URI not declaring any (RFC 2397).
+const defaultDataUriMimetype = "text/plain"
+
+// dataUri represents a parsed RFC 2397 data URI.
+type dataUri struct {
+	mimetype string
+	charset  string
+	base64   bool
+	data     []byte
+}
+
+// parseDataUri parses the "data:[<mediatype>][;base64],<data>" URI as specified by RFC 2397.
+// The data are percent-decoded; base64 encoded data are decoded as well. Non-standard forms
+// commonly used by on-chain NFTs, like "data:application/json;utf8,{...}" with raw unescaped
+// content, are accepted. Data in ISO-8859-1 charset are converted to UTF-8.
+func parseDataUri(uri string) (*dataUri, error) {
+	if !types.IsDataUri(uri) {
+		return nil, errors.New("not a data uri")
+	}
+
+	comma := strings.IndexByte(uri, ',')
+	if comma < 0 {
+		return nil, errors.New("invalid data uri - no comma")
+	}
+
+	du := dataUri{mimetype: defaultDataUriMimetype}
+	for i, param := range strings.Split(uri[5:comma], ";") {
+		param = strings.TrimSpace(param)
+		switch {
+		case i == 0 && strings.Contains(param, "/"):
+			du.mimetype = strings.ToLower(param)
+		case strings.EqualFold(param, "base64"):
+			du.base64 = true
+		case len(param) > 8 && strings.EqualFold(param[:8], "charset="):
+			du.charset = strings.ToLower(strings.Trim(param[8:], `"`))
+		}
+	}
+
+	data := percentDecode(uri[comma+1:])
+	if du.base64 {
+		var err error
+		if data, err = decodeBase64(data); err != nil {
+			return nil, fmt.Errorf("invalid data uri base64 content; %w", err)
+		}
+	}
+
+	if du.charset == "iso-8859-1" || du.charset == "latin1" {
+		data = latin1ToUtf8(data)
+	}
+	du.data = data
+	return &du, nil
+}
+
+// percentDecode decodes the percent-encoded octets of the data URI content.
+// Percent signs not followed by two hex digits are kept as they are, so raw content
+// like SVG with "width='100%'" survives; "+" is not a space in URI.
+func percentDecode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			out = append(out, unhex(s[i+1])<<4|unhex(s[i+2]))
+			i += 2
+			continue
+		}
+		out = append(out, s[i])
+	}
+	return out
+}
+
+// decodeBase64 decodes base64 content ignoring white space; both standard and URL alphabets are accepted
+// with or without padding.
+func decodeBase64(data []byte) ([]byte, error) {
+	clean := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, string(data))
+
+	if strings.ContainsAny(clean, "-_") {
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(clean, "="))
+	}
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(clean, "="))
+}
+
+// latin1ToUtf8 converts ISO-8859-1 encoded text to UTF-8.
+func latin1ToUtf8(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	buf := make([]byte, utf8.UTFMax)
+	for _, b := range data {
+		n := utf8.EncodeRune(buf, rune(b))
+		out = append(out, buf[:n]...)
+	}
+	return out
+}
+
+// isHex checks if the byte is a hexadecimal digit.
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// unhex provides the value of the hexadecimal digit.
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
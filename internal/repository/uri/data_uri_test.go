@@ -0,0 +1,76 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/types"
+	"github.com/onsi/gomega"
+	"testing"
+)
+
+func TestParseDataUri(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	du, err := parseDataUri("data:,A%20brief%20note")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(du.mimetype).To(gomega.Equal("text/plain"))
+	g.Expect(string(du.data)).To(gomega.Equal("A brief note"))
+
+	du, err = parseDataUri("data:text/plain;charset=iso-8859-1,caf%E9")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(du.charset).To(gomega.Equal("iso-8859-1"))
+	g.Expect(string(du.data)).To(gomega.Equal("café"))
+
+	du, err = parseDataUri("DATA:Image/PNG;BASE64,iVBO%0ARw0K")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(du.mimetype).To(gomega.Equal("image/png"))
+	g.Expect(du.base64).To(gomega.BeTrue())
+	g.Expect(du.data).To(gomega.Equal([]byte("\x89PNG\r\n")))
+
+	du, err = parseDataUri("data:text/plain;base64,aGk_")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(du.data).To(gomega.Equal([]byte{'h', 'i', 0x3f}))
+
+	_, err = parseDataUri("data:application/json;base64,!!!")
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	_, err = parseDataUri("data:application/json;base64")
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestOnChainJson(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	d := Downloader{}
+
+	md, err := d.GetJsonMetadata(`data:application/json;utf8,{"name":"Loot #1, on chain","description":"100% on chain","image":"data:image/svg+xml;utf8,<svg xmlns='http://www.w3.org/2000/svg'><rect fill='#000'/></svg>"}`)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(md.Name).To(gomega.Equal("Loot #1, on chain"))
+	g.Expect(*md.Description).To(gomega.Equal("100% on chain"))
+
+	md, err = d.GetJsonMetadata("data:application/json,%7B%22name%22%3A%22Encoded%20%231%22%7D")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(md.Name).To(gomega.Equal("Encoded #1"))
+
+	md, err = d.GetJsonMetadata("data:application/json;charset=utf-8;base64,eyJuYW1lIjoiQmFzZTY0ICMxIn0=")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(md.Name).To(gomega.Equal("Base64 #1"))
+}
+
+func TestOnChainSvg(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	d := Downloader{}
+
+	img, err := d.GetImage(`data:image/svg+xml;utf8,<svg xmlns="http://www.w3.org/2000/svg" width="100%" viewBox="0 0 350 350"><rect width="100%" height="100%" fill="#000"/></svg>`)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(img.Type).To(gomega.Equal(types.ImageTypeSvg))
+	g.Expect(string(img.Data)).To(gomega.ContainSubstring(`width="100%"`))
+	g.Expect(string(img.Data)).To(gomega.ContainSubstring(`fill="#000"`))
+
+	img, err = d.GetImage("data:image/svg+xml,%3Csvg%20xmlns%3D%22http%3A%2F%2Fwww.w3.org%2F2000%2Fsvg%22%2F%3E")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(img.Type).To(gomega.Equal(types.ImageTypeSvg))
+	g.Expect(string(img.Data)).To(gomega.Equal(`<svg xmlns="http://www.w3.org/2000/svg"/>`))
+
+	tok := types.Token{Uri: "data:application/json;base64,e30="}
+	g.Expect(tok.HasOnChainMetadata()).To(gomega.BeTrue())
+	tok.Uri = "ipfs://QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi"
+	g.Expect(tok.HasOnChainMetadata()).To(gomega.BeFalse())
+}
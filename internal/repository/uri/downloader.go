@@ -5,11 +5,10 @@ package uri
 import (
 	"artion-api-graphql/internal/config"
 	"artion-api-graphql/internal/types"
-	"encoding/base64"
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
 	ipfsapi "github.com/ipfs/go-ipfs-api"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -18,43 +17,102 @@ import (
 // ipfsRequestTimeout represents the timeout applied to IPFS requests.
 const ipfsRequestTimeout = 5 * time.Second
 
+// httpRequestTimeout represents the timeout applied to HTTP and IPFS gateway requests.
+const httpRequestTimeout = 60 * time.Second
+
 type Downloader struct {
 	ipfsShell *ipfsapi.Shell
 	skipHttpGateways bool
-	gateway string
-	gatewayBearer string
+
+	// gateways is the ordered list of IPFS HTTP gateways; the IPFS node is used if empty
+	gateways []ipfsGateway
+
+	// hedgeDelay is the time after which the next gateway is asked; zero disables hedged requests
+	hedgeDelay time.Duration
+
+	// arweaveGateway is the gateway used to resolve ar:// URIs
+	arweaveGateway string
+
+	// ipfsHost identifies the IPFS node for the host limits
+	ipfsHost string
+
+	// hosts limits requests to remote hosts; no limits are applied if nil
+	hosts *hostGates
+
+	// failFast makes requests to a busy host fail with ErrHostBusy instead of waiting for the host
+	failFast bool
+
+	// client is the restricted HTTP client for remote requests; a plain client is used if nil
+	client *http.Client
+
+	// maxJsonSize and maxMediaSize limit the size of downloaded content; zero means no limit
+	maxJsonSize  int64
+	maxMediaSize int64
 }
 
 // New provides new Downloader instance.
 func New(cfg *config.Config) *Downloader {
+	client, err := newHttpClient(cfg)
+	if err != nil {
+		panic(fmt.Errorf("invalid downloader configuration; %w", err))
+	}
+
 	d := &Downloader{
 		ipfsShell: ipfsapi.NewShell(cfg.Ipfs.Url),
 		skipHttpGateways: cfg.Ipfs.SkipHttpGateways,
-		gateway: cfg.Ipfs.Gateway,
-		gatewayBearer: cfg.Ipfs.GatewayBearer,
+		gateways: newIpfsGateways(cfg),
+		hedgeDelay: cfg.Ipfs.HedgeDelay,
+		arweaveGateway: cfg.Ipfs.ArweaveGateway,
+		ipfsHost: cfg.Ipfs.Url,
+		hosts: newHostGates(
+			cfg.Downloader.HostConcurrency,
+			cfg.Downloader.HostRate,
+			cfg.Downloader.BreakerThreshold,
+			cfg.Downloader.BreakerCooldown,
+		),
+		client:       client,
+		maxJsonSize:  cfg.Downloader.MaxJsonSize,
+		maxMediaSize: cfg.Downloader.MaxMediaSize,
 	}
 	d.ipfsShell.SetTimeout(ipfsRequestTimeout)
 	return d
 }
 
+// FailFast provides a copy of the downloader sharing the host limits, which does not wait
+// for busy hosts and fails with ErrHostBusy instead. Background workers use it to defer the work.
+func (d *Downloader) FailFast() *Downloader {
+	c := *d
+	c.failFast = true
+	return &c
+}
+
 // GetJsonMetadata download and parse NFT token Metadata JSON from given URI
 func (d *Downloader) GetJsonMetadata(uri string) (*types.JsonMetadata, error) {
-	data, _, err := d.getFromUri(uri)
+	data, err := d.GetJsonMetadataData(uri)
 	if err != nil {
-		return nil, fmt.Errorf("unable to download json; %s", err)
+		return nil, err
 	}
 	jsonMeta, err := types.DecodeJsonMetadata(data)
 	if err != nil {
-		return nil, fmt.Errorf("unable to decode json; %s", err)
+		return nil, fmt.Errorf("unable to decode json; %w", err)
 	}
 	return jsonMeta, nil
 }
 
+// GetJsonMetadataData download NFT token Metadata JSON document from given URI without parsing it
+func (d *Downloader) GetJsonMetadataData(uri string) ([]byte, error) {
+	data, _, err := d.getFromUri(uri, d.maxJsonSize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download json; %w", err)
+	}
+	return data, nil
+}
+
 // GetImage downloads image from given URI and detect its mimetype
 func (d *Downloader) GetImage(uri string) (image *types.Image, err error) {
-	data, mimetype, err := d.getFromUri(uri)
+	data, mimetype, err := d.getFromUri(uri, d.maxMediaSize)
 	if err != nil {
-		return nil, fmt.Errorf("unable to download image; %s", err)
+		return nil, fmt.Errorf("unable to download image; %w", err)
 	}
 	if mimetype == "" {
 		mimetype = http.DetectContentType(data)
@@ -63,6 +121,9 @@ func (d *Downloader) GetImage(uri string) (image *types.Image, err error) {
 	if imgType == types.ImageTypeUnknown {
 		imgType = types.ImageTypeFromExtension(uri)
 	}
+	if imgType == types.ImageTypeUnknown && isSvg(data) {
+		imgType = types.ImageTypeSvg
+	}
 	out := types.Image{
 		Data: data,
 		Type: imgType,
@@ -70,118 +131,122 @@ func (d *Downloader) GetImage(uri string) (image *types.Image, err error) {
 	return &out, nil
 }
 
+// isSvg checks if the data look like an SVG image; servers often send SVG as plain text or XML
+// and the content sniffing of the standard library does not recognize it.
+func isSvg(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
+
+// httpClient provides the HTTP client used for remote requests.
+func (d *Downloader) httpClient() *http.Client {
+	if d.client == nil {
+		return defaultHttpClient
+	}
+	return d.client
+}
+
 // getFromUri resolves the URI and download file from the URI using appropriate protocol
-func (d *Downloader) getFromUri(uri string) (data []byte, mimetype string, err error) {
-	if strings.HasPrefix(uri, "data:") {
+// Remote requests are subject to the limits of the target host, the content is limited to the given size.
+func (d *Downloader) getFromUri(uri string, limit int64) (data []byte, mimetype string, err error) {
+	if types.IsDataUri(uri) {
 		return d.getFromDataUri(uri)
 	}
 	if ipfsUri := d.getIpfsUri(uri); ipfsUri != "" {
-		return d.getFromIpfs(ipfsUri)
+		return d.getFromIpfs(ipfsUri, limit)
+	}
+	if src := d.httpSource(uri); src != "" {
+		err = d.hosts.do(hostOf(src), d.failFast, func() (e error) {
+			data, mimetype, e = d.getFromHttp(src, limit)
+			return e
+		})
+		return data, mimetype, err
 	}
+	return nil, "", fmt.Errorf("%w for %s", ErrUnknownScheme, uri)
+}
+
+// httpSource provides the HTTP URL the content of the URI is downloaded from - returns empty string
+// if the URI is not available over HTTP. Arweave URIs are resolved using the Arweave gateway.
+func (d *Downloader) httpSource(uri string) string {
 	if strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://") {
-		return d.getFromHttp(uri)
+		return uri
 	}
-	return nil, "", errors.New("Unexpected URI scheme for " + uri)
+	return d.getArweaveUrl(uri)
 }
 
 // getIpfsUri try to obtain IPFS URI from the URI - returns empty string for non-ipfs uri
-// This function is responsible for IPFS URI detection, unification and for conversion
-// of known IPFS HTTP gateways URI into IPFS URI.
+// This function is responsible for IPFS and IPNS URI detection, unification and for conversion
+// of IPFS HTTP gateways URI into IPFS URI. The result is in "/ipfs/{CID}/{path}" or "/ipns/{name}/{path}" form.
 func (d *Downloader) getIpfsUri(uri string) string {
-	if strings.HasPrefix(uri, "/ipfs/") {
-		return uri
-	}
-	if strings.HasPrefix(uri, "ipfs://") {
-		return "/ipfs/" + uri[7:]
+	switch {
+	case strings.HasPrefix(uri, "/ipfs/"):
+		return contentPath("ipfs", uri[6:], false)
+	case strings.HasPrefix(uri, "ipfs://"):
+		return contentPath("ipfs", uri[7:], false)
+	case strings.HasPrefix(uri, "/ipns/"):
+		return contentPath("ipns", uri[6:], false)
+	case strings.HasPrefix(uri, "ipns://"):
+		return contentPath("ipns", uri[7:], false)
 	}
 	if d.skipHttpGateways {
-		if strings.HasPrefix(uri, "https://gateway.pinata.cloud/ipfs/") {
-			return "/ipfs/" + uri[34:]
-		}
-		if strings.HasPrefix(uri, "https://ipfs.io/ipfs/") {
-			return "/ipfs/" + uri[21:]
-		}
-		if idx := strings.Index(uri, ".mypinata.cloud/ipfs/"); idx > 8 && idx < 30 {
-			return "/ipfs/" + uri[idx+21:]
-		}
+		return gatewayContentPath(uri)
 	}
 	return ""
 }
 
-// getFromIpfs downloads the file from IPFS (URI is expected in "/ipfs/{CID}" form).
-func (d *Downloader) getFromIpfs(uri string) (data []byte, mimetype string, err error) {
-	if d.gateway == "" {
+// getFromIpfs downloads the file from IPFS (URI is expected in "/ipfs/{CID}" or "/ipns/{name}" form).
+// The configured gateways are used if available, the IPFS node otherwise.
+func (d *Downloader) getFromIpfs(uri string, limit int64) (data []byte, mimetype string, err error) {
+	if len(d.gateways) > 0 {
+		return d.hedged(func(ctx context.Context, gw ipfsGateway) ([]byte, string, error) {
+			return d.getFromIpfsGateway(ctx, gw, uri, limit)
+		})
+	}
+
+	err = d.hosts.do(d.ipfsHost, d.failFast, func() error {
 		reader, err := d.ipfsShell.Cat(uri)
 		if err != nil {
-			return nil, "", err
-		}
-		out, err := io.ReadAll(reader)
-		if err != nil {
-			return nil, "", err
+			return err
 		}
-		return out, "", reader.Close()
-	} else {
-		return d.getFromIpfsGateway(uri)
-	}
-}
-
-// getFromIpfsGateway downloads the file from IPFS HTTP gateway.
-func (d *Downloader) getFromIpfsGateway(uri string) (data []byte, mimetype string, err error) {
-	client := http.Client{}
-	req , err := http.NewRequest("GET", d.gateway + uri, nil)
-	if err != nil {
-		return nil, "", err
-	}
-	if d.gatewayBearer != "" {
-		req.Header.Set("Authorization", "Bearer " + d.gatewayBearer)
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, "", err
-	}
-	if resp.StatusCode != 200 {
-		return nil, "", fmt.Errorf("HTTP gateway returned %s", resp.Status)
-	}
-	reader := resp.Body
-	out, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, "", err
-	}
+		defer reader.Close()
 
-	mimetype = resp.Header.Get("Content-Type")
-	return out, mimetype, reader.Close()
+		data, err = readLimited(reader, limit)
+		return err
+	})
+	return data, "", err
 }
 
 // getFromHttp downloads the file from HTTP.
-func (d *Downloader) getFromHttp(uri string) (data []byte, mimetype string, err error) {
-	resp, err := http.Get(uri)
+func (d *Downloader) getFromHttp(uri string, limit int64) (data []byte, mimetype string, err error) {
+	resp, err := d.httpClient().Get(uri)
 	if err != nil {
 		return nil, "", err
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != 200 {
-		return nil, "", fmt.Errorf("HTTP server returned %s", resp.Status)
+		return nil, "", &StatusError{Code: resp.StatusCode, Message: "HTTP server returned " + resp.Status}
+	}
+	if err := checkContentLength(resp, limit); err != nil {
+		return nil, "", err
 	}
-	reader := resp.Body
-	out, err := io.ReadAll(reader)
+	out, err := readLimited(resp.Body, limit)
 	if err != nil {
 		return nil, "", err
 	}
 
 	mimetype = resp.Header.Get("Content-Type")
-	return out, mimetype, reader.Close()
+	return out, mimetype, nil
 }
 
 // getFromDataUri obtains the file encoded in "data:" URI.
 func (d *Downloader) getFromDataUri(uri string) (data []byte, mimetype string, err error) {
-	splitted := strings.Split(uri, ",")
-	if len(splitted) < 2 {
-		return nil, "", errors.New("Invalid data uri - no comma: " + uri)
-	}
-	mimetype = strings.Split(splitted[0][5:], ";")[0]
-
-	out, err := base64.StdEncoding.DecodeString(splitted[1])
+	du, err := parseDataUri(uri)
 	if err != nil {
 		return nil, "", err
 	}
-	return out, mimetype, nil
+	return du.data, du.mimetype, nil
 }
@@ -1,9 +1,12 @@
 package uri
 
 import (
+	"artion-api-graphql/internal/repository/uri/ipfstest"
 	"artion-api-graphql/internal/types"
 	ipfsapi "github.com/ipfs/go-ipfs-api"
 	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -31,11 +34,15 @@ func TestHttp(t *testing.T) {
 	g.Expect(*data.Description).To(gomega.Equal("Rarity tier 1, non magical, item crafting."))
 }
 
-func TestIpfs(t *testing.T) { // requires locally running IPFS node
+func TestIpfs(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
 
+	node := ipfstest.NewNode()
+	defer node.Close()
+	node.Add("/ipfs/QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi", []byte(`{"name": "item #9292", "description": "Rarity tier 1, non magical, item crafting."}`))
+
 	downloader := Downloader{
-		ipfsShell: ipfsapi.NewShell("localhost:5001"),
+		ipfsShell: ipfsapi.NewShell(node.URL),
 	}
 	data,err := downloader.GetJsonMetadata("/ipfs/QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi")
 	g.Expect(err).To(gomega.BeNil())
@@ -77,4 +84,48 @@ func TestIpfsUriConversion(t *testing.T) { // requires locally running IPFS node
 
 	uri = d.getIpfsUri("https://example.org/test.json")
 	g.Expect(uri).To(gomega.Equal(""))
-}
\ No newline at end of file
+
+	uri = d.getIpfsUri("ipfs://ipfs/QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi//meta/1.json")
+	g.Expect(uri).To(gomega.Equal("/ipfs/QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi/meta/1.json"))
+
+	uri = d.getIpfsUri("ipfs://BAFYBEIGDYRZT5SFP7UDM7HU76UH7Y26NF3EFUYLQABF3OCLGTQY55FBZDI/1.json")
+	g.Expect(uri).To(gomega.Equal("/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/1.json"))
+
+	uri = d.getIpfsUri("https://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi.ipfs.dweb.link/1.json")
+	g.Expect(uri).To(gomega.Equal("/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/1.json"))
+
+	uri = d.getIpfsUri("https://example.org/ipfs/not-a-cid/1.json")
+	g.Expect(uri).To(gomega.Equal(""))
+
+	uri = d.getIpfsUri("ipns://k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8/1.json")
+	g.Expect(uri).To(gomega.Equal("/ipns/k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8/1.json"))
+
+	uri = d.getIpfsUri("/ipns/artion.io/meta.json")
+	g.Expect(uri).To(gomega.Equal("/ipns/artion.io/meta.json"))
+
+	uri = d.getArweaveUrl("ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/1.json")
+	g.Expect(uri).To(gomega.Equal("https://arweave.net/bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/1.json"))
+
+	uri = d.getArweaveUrl("ar://invalid")
+	g.Expect(uri).To(gomega.Equal(""))
+}
+func TestMimetype(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte("glTF\x02\x00\x00\x00"))
+	}))
+	defer srv.Close()
+
+	downloader := Downloader{
+		ipfsShell: nil,
+	}
+	mt, err := downloader.GetMimetype(srv.URL + "/model.glb")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(mt).To(gomega.Equal("model/gltf-binary"))
+
+	mt, err = downloader.GetMimetype("data:audio/mpeg;base64,SUQz")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(mt).To(gomega.Equal("audio/mpeg"))
+}
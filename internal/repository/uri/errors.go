@@ -0,0 +1,59 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/types"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ErrUnknownScheme is returned if the URI scheme is not supported by the downloader.
+var ErrUnknownScheme = errors.New("unexpected URI scheme")
+
+// StatusError is returned if a remote server responds with an unexpected HTTP status.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+// Error returns the text of the error.
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// ErrorCategory provides the category of the error received from the downloader.
+// The category is used to tell the reason of NFT metadata failures apart.
+func ErrorCategory(err error) string {
+	var se *StatusError
+	if errors.As(err, &se) {
+		if se.Code == http.StatusNotFound || se.Code == http.StatusGone {
+			return types.MetaErrorNotFound
+		}
+		return types.MetaErrorHttpStatus
+	}
+
+	switch {
+	case errors.Is(err, types.ErrInvalidJsonMetadata):
+		return types.MetaErrorInvalidJson
+	case errors.Is(err, ErrUnknownScheme):
+		return types.MetaErrorUnknownScheme
+	case errors.Is(err, ErrForbiddenDestination):
+		return types.MetaErrorForbidden
+	case errors.Is(err, ErrContentTooLarge):
+		return types.MetaErrorTooLarge
+	case errors.Is(err, ErrHostUnavailable), errors.Is(err, ErrHostBusy):
+		return types.MetaErrorHostUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return types.MetaErrorTimeout
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) {
+		if ne.Timeout() {
+			return types.MetaErrorTimeout
+		}
+		return types.MetaErrorNetwork
+	}
+	return types.MetaErrorOther
+}
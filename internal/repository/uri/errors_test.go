@@ -0,0 +1,30 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/types"
+	"fmt"
+	"github.com/onsi/gomega"
+	"testing"
+)
+
+func TestErrorCategory(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	err := fmt.Errorf("unable to download json; %w", &StatusError{Code: 404, Message: "HTTP server returned 404 Not Found"})
+	g.Expect(ErrorCategory(err)).To(gomega.Equal(types.MetaErrorNotFound))
+	g.Expect(ErrorCategory(&StatusError{Code: 502})).To(gomega.Equal(types.MetaErrorHttpStatus))
+
+	_, err = types.DecodeJsonMetadata([]byte("<html>"))
+	g.Expect(ErrorCategory(fmt.Errorf("unable to decode json; %w", err))).To(gomega.Equal(types.MetaErrorInvalidJson))
+
+	d := Downloader{}
+	_, err = d.GetJsonMetadata("ftp://example.com/1.json")
+	g.Expect(ErrorCategory(err)).To(gomega.Equal(types.MetaErrorUnknownScheme))
+
+	g.Expect(ErrorCategory(fmt.Errorf("%w; 10 bytes", ErrContentTooLarge))).To(gomega.Equal(types.MetaErrorTooLarge))
+	g.Expect(ErrorCategory(fmt.Errorf("boom"))).To(gomega.Equal(types.MetaErrorOther))
+
+	g.Expect(types.UriHost("https://Api.Example.com/token/1")).To(gomega.Equal("api.example.com"))
+	g.Expect(types.UriHost("ipfs://QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi")).To(gomega.Equal("ipfs"))
+	g.Expect(types.UriHost("/ipns/example.eth/1.json")).To(gomega.Equal("ipns"))
+}
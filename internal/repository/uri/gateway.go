@@ -0,0 +1,157 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/config"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ipfsGateway represents an IPFS HTTP gateway the content can be downloaded from.
+type ipfsGateway struct {
+	url    string
+	bearer string
+}
+
+// newIpfsGateways builds the ordered list of IPFS gateways from the config.
+// The primary gateway goes first with its bearer, public fallback gateways follow.
+func newIpfsGateways(cfg *config.Config) []ipfsGateway {
+	list := make([]ipfsGateway, 0, len(cfg.Ipfs.Gateways)+1)
+	seen := make(map[string]bool)
+
+	add := func(url string, bearer string) {
+		url = strings.TrimRight(strings.TrimSpace(url), "/")
+		if url == "" || seen[url] {
+			return
+		}
+		seen[url] = true
+		list = append(list, ipfsGateway{url: url, bearer: bearer})
+	}
+
+	add(cfg.Ipfs.Gateway, cfg.Ipfs.GatewayBearer)
+	for _, gw := range cfg.Ipfs.Gateways {
+		add(gw, "")
+	}
+	return list
+}
+
+// gatewayResult represents the outcome of a single gateway request.
+type gatewayResult struct {
+	data     []byte
+	mimetype string
+	err      error
+}
+
+// hedged executes the request on the IPFS gateways in the configured order. The next gateway
+// is asked if the previous one fails, or if it does not respond within the hedge delay (hedged request).
+// The first successful response wins and the requests still running are cancelled.
+func (d *Downloader) hedged(fetch func(ctx context.Context, gw ipfsGateway) ([]byte, string, error)) ([]byte, string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := make(chan gatewayResult, len(d.gateways))
+	next, pending := 0, 0
+
+	launch := func() {
+		gw := d.gateways[next]
+		next++
+		pending++
+
+		go func() {
+			var res gatewayResult
+			res.err = d.hosts.do(hostOf(gw.url), d.failFast, func() (e error) {
+				res.data, res.mimetype, e = fetch(ctx, gw)
+
+				// a request cancelled because another gateway won is not a failure of the host
+				if e != nil && ctx.Err() != nil {
+					return nil
+				}
+				return e
+			})
+			results <- res
+		}()
+	}
+
+	var hedge <-chan time.Time
+	var timer *time.Timer
+	if d.hedgeDelay > 0 {
+		timer = time.NewTimer(d.hedgeDelay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+
+	var firstErr error
+	launch()
+	for pending > 0 {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				return res.data, res.mimetype, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if next < len(d.gateways) {
+				launch()
+				resetTimer(timer, d.hedgeDelay)
+			}
+		case <-hedge:
+			if next < len(d.gateways) {
+				launch()
+				timer.Reset(d.hedgeDelay)
+			}
+		}
+	}
+
+	if len(d.gateways) > 1 {
+		return nil, "", fmt.Errorf("all %d gateways failed; %w", len(d.gateways), firstErr)
+	}
+	return nil, "", firstErr
+}
+
+// resetTimer restarts the hedge timer, if any.
+func resetTimer(timer *time.Timer, delay time.Duration) {
+	if timer == nil {
+		return
+	}
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(delay)
+}
+
+// getFromIpfsGateway downloads the file from IPFS HTTP gateway.
+func (d *Downloader) getFromIpfsGateway(ctx context.Context, gw ipfsGateway, uri string, limit int64) (data []byte, mimetype string, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", gw.url+uri, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if gw.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+gw.bearer)
+	}
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, "", &StatusError{Code: resp.StatusCode, Message: fmt.Sprintf("HTTP gateway %s returned %s", gw.url, resp.Status)}
+	}
+	if err := checkContentLength(resp, limit); err != nil {
+		return nil, "", err
+	}
+	out, err := readLimited(resp.Body, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mimetype = resp.Header.Get("Content-Type")
+	return out, mimetype, nil
+}
@@ -0,0 +1,72 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/repository/uri/ipfstest"
+	"github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+const testCid = "QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi"
+
+func TestGatewayFallback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	primary, fallback := ipfstest.NewNode(), ipfstest.NewNode()
+	defer primary.Close()
+	defer fallback.Close()
+
+	primary.SetFailing(true)
+	fallback.Add("/ipfs/"+testCid+"/1.json", []byte(`{"name": "item #1"}`))
+
+	d := Downloader{gateways: []ipfsGateway{{url: primary.URL}, {url: fallback.URL}}}
+	data, err := d.GetJsonMetadata("ipfs://" + testCid + "/1.json")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(data.Name).To(gomega.Equal("item #1"))
+	g.Expect(primary.Requests()).To(gomega.Equal(1))
+
+	_, err = d.GetJsonMetadata("ipfs://" + testCid + "/2.json")
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestGatewayHedging(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	slow, fast := ipfstest.NewNode(), ipfstest.NewNode()
+	defer slow.Close()
+	defer fast.Close()
+
+	slow.SetDelay(5 * time.Second)
+	slow.Add("/ipfs/"+testCid, []byte(`{"name": "slow"}`))
+	fast.Add("/ipfs/"+testCid, []byte(`{"name": "fast"}`))
+
+	d := Downloader{
+		gateways:   []ipfsGateway{{url: slow.URL}, {url: fast.URL}},
+		hedgeDelay: 50 * time.Millisecond,
+	}
+	start := time.Now()
+	data, err := d.GetJsonMetadata("/ipfs/" + testCid)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(data.Name).To(gomega.Equal("fast"))
+	g.Expect(time.Since(start)).To(gomega.BeNumerically("<", 2*time.Second))
+
+	mt, err := d.GetMimetype("/ipfs/" + testCid)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(mt).To(gomega.Equal("text/plain"))
+}
+
+func TestIpfsContentPath(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(IpfsContentPath("ipfs://" + testCid)).To(gomega.Equal("/ipfs/" + testCid))
+	g.Expect(IpfsContentPath("ipfs://ipfs/" + testCid)).To(gomega.Equal("/ipfs/" + testCid))
+	g.Expect(IpfsContentPath("https://ipfs.io/ipfs/" + testCid)).To(gomega.Equal("/ipfs/" + testCid))
+	g.Expect(IpfsContentPath("https://example.com/token/1.json")).To(gomega.Equal(""))
+	g.Expect(IpfsContentPath("ipns://example.com")).To(gomega.Equal(""))
+	g.Expect(IpfsContentPath("ipfs://not-a-cid")).To(gomega.Equal(""))
+
+	g.Expect(ContentCid("ipfs://" + testCid + "/image.png")).To(gomega.Equal(testCid))
+	g.Expect(ContentCid("https://ipfs.io/ipfs/" + testCid + "?filename=1.json")).To(gomega.Equal(testCid))
+	g.Expect(ContentCid(testCid)).To(gomega.Equal(testCid))
+	g.Expect(ContentCid("https://example.com/" + testCid)).To(gomega.Equal(""))
+}
@@ -0,0 +1,176 @@
+package uri
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrHostUnavailable is returned if a request is refused by an open circuit breaker of the host.
+var ErrHostUnavailable = fmt.Errorf("host temporarily unavailable")
+
+// ErrHostBusy is returned if a request is not allowed to wait for the host
+// and the host has no free slot, or its rate limit does not allow another request now.
+var ErrHostBusy = fmt.Errorf("host busy")
+
+// hostGate limits concurrency and rate of requests sent to a single host
+// and backs off the host if it keeps failing (circuit breaker).
+type hostGate struct {
+	mu sync.Mutex
+
+	// slots is the semaphore limiting concurrent requests
+	slots chan struct{}
+
+	// interval is the minimal delay between subsequent requests
+	interval time.Duration
+	nextSlot time.Time
+
+	// circuit breaker state
+	threshold   int
+	cooldown    time.Duration
+	failures    int
+	openedUntil time.Time
+}
+
+// hostGates keeps gates of all the hosts we communicate with.
+type hostGates struct {
+	mu    sync.Mutex
+	gates map[string]*hostGate
+
+	// limits applied to new gates
+	concurrency int
+	rate        float64
+	threshold   int
+	cooldown    time.Duration
+}
+
+// newHostGates creates a new set of host gates with the given limits.
+// Zero or negative limits are not applied.
+func newHostGates(concurrency int, rate float64, threshold int, cooldown time.Duration) *hostGates {
+	return &hostGates{
+		gates:       make(map[string]*hostGate),
+		concurrency: concurrency,
+		rate:        rate,
+		threshold:   threshold,
+		cooldown:    cooldown,
+	}
+}
+
+// gate provides the gate of the given host; a new gate is created if needed.
+func (hg *hostGates) gate(host string) *hostGate {
+	hg.mu.Lock()
+	defer hg.mu.Unlock()
+
+	g, ok := hg.gates[host]
+	if !ok {
+		g = &hostGate{
+			threshold: hg.threshold,
+			cooldown:  hg.cooldown,
+		}
+		if hg.concurrency > 0 {
+			g.slots = make(chan struct{}, hg.concurrency)
+		}
+		if hg.rate > 0 {
+			g.interval = time.Duration(float64(time.Second) / hg.rate)
+		}
+		hg.gates[host] = g
+	}
+	return g
+}
+
+// do executes the given request function on the host respecting the host limits.
+// If failFast is set, the request fails with ErrHostBusy instead of waiting for the host.
+// The result of the request is used to drive the host circuit breaker.
+func (hg *hostGates) do(host string, failFast bool, req func() error) error {
+	// no gates configured at all (i.e. in tests)
+	if hg == nil {
+		return req()
+	}
+
+	g := hg.gate(host)
+	if err := g.enter(failFast); err != nil {
+		return fmt.Errorf("%s; %w", host, err)
+	}
+
+	err := req()
+	g.leave(err == nil)
+	return err
+}
+
+// enter waits for a free slot on the host; it fails immediately if the host circuit is open.
+// If failFast is set, it fails with ErrHostBusy instead of waiting.
+func (g *hostGate) enter(failFast bool) error {
+	if g.isOpen() {
+		return ErrHostUnavailable
+	}
+
+	if g.slots != nil {
+		if !failFast {
+			g.slots <- struct{}{}
+		} else {
+			select {
+			case g.slots <- struct{}{}:
+			default:
+				return ErrHostBusy
+			}
+		}
+	}
+
+	// wait for the rate limiter; the slot is reserved under lock, the wait happens outside
+	g.mu.Lock()
+	now := time.Now()
+	start := g.nextSlot
+	if start.Before(now) {
+		start = now
+	}
+	if failFast && start.After(now) {
+		g.mu.Unlock()
+		if g.slots != nil {
+			<-g.slots
+		}
+		return ErrHostBusy
+	}
+	g.nextSlot = start.Add(g.interval)
+	g.mu.Unlock()
+
+	time.Sleep(time.Until(start))
+	return nil
+}
+
+// leave releases the host slot and updates the circuit breaker state.
+func (g *hostGate) leave(success bool) {
+	if g.slots != nil {
+		<-g.slots
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if success {
+		g.failures = 0
+		return
+	}
+
+	g.failures++
+	if g.threshold > 0 && g.failures >= g.threshold {
+		g.openedUntil = time.Now().Add(g.cooldown)
+		g.failures = 0
+	}
+}
+
+// isOpen checks if the host circuit breaker is open and requests should not be sent.
+func (g *hostGate) isOpen() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return time.Now().Before(g.openedUntil)
+}
+
+// hostOf extracts the host name from the given URL.
+func hostOf(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return uri
+	}
+	return u.Host
+}
@@ -0,0 +1,78 @@
+package uri
+
+import (
+	"errors"
+	"github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestHostGateBreaker(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	hg := newHostGates(2, 0, 3, time.Minute)
+	fail := func() error { return errors.New("failed") }
+
+	// the breaker opens after 3 subsequent failures
+	for i := 0; i < 3; i++ {
+		g.Expect(hg.do("slow.example.org", false, fail)).ToNot(gomega.BeNil())
+	}
+
+	called := false
+	err := hg.do("slow.example.org", false, func() error { called = true; return nil })
+	g.Expect(errors.Is(err, ErrHostUnavailable)).To(gomega.BeTrue())
+	g.Expect(called).To(gomega.BeFalse())
+
+	// other hosts are not affected
+	g.Expect(hg.do("fast.example.org", false, func() error { return nil })).To(gomega.BeNil())
+}
+
+func TestHostGateRate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	hg := newHostGates(0, 20, 0, 0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		g.Expect(hg.do("example.org", false, func() error { return nil })).To(gomega.BeNil())
+	}
+
+	// 3 requests at 20 req/s need at least 2 intervals of 50ms
+	g.Expect(time.Since(start)).To(gomega.BeNumerically(">=", 100*time.Millisecond))
+}
+
+func TestHostGateFailFast(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	hg := newHostGates(1, 0, 0, 0)
+
+	// the only slot of the host is taken by a running request
+	running, done := make(chan bool), make(chan bool)
+	go func() {
+		_ = hg.do("example.org", false, func() error {
+			close(running)
+			<-done
+			return nil
+		})
+	}()
+	<-running
+
+	called := false
+	err := hg.do("example.org", true, func() error { called = true; return nil })
+	g.Expect(errors.Is(err, ErrHostBusy)).To(gomega.BeTrue())
+	g.Expect(called).To(gomega.BeFalse())
+
+	// the slot is available again once the request ends
+	close(done)
+	g.Eventually(func() error {
+		return hg.do("example.org", true, func() error { return nil })
+	}).Should(gomega.BeNil())
+
+	// the rate limit is not waited for either
+	hg = newHostGates(0, 1, 0, 0)
+	g.Expect(hg.do("example.org", true, func() error { return nil })).To(gomega.BeNil())
+	g.Expect(errors.Is(hg.do("example.org", true, func() error { return nil }), ErrHostBusy)).To(gomega.BeTrue())
+}
+
+func TestHostOf(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(hostOf("https://ipfs.io/ipfs/QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi")).To(gomega.Equal("ipfs.io"))
+	g.Expect(hostOf("localhost:5001")).To(gomega.Equal("localhost:5001"))
+}
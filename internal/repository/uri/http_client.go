@@ -0,0 +1,197 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination is returned if a remote request targets a private, loopback
+// or link-local address not explicitly allowed by the configuration.
+var ErrForbiddenDestination = errors.New("forbidden destination address")
+
+// ErrContentTooLarge is returned if a downloaded content exceeds the size limit.
+var ErrContentTooLarge = errors.New("content too large")
+
+// defaultHttpClient is used by downloaders not created by New(), it applies no destination restrictions.
+var defaultHttpClient = &http.Client{Timeout: httpRequestTimeout}
+
+// privateNets lists the private, carrier-grade NAT and unique local address ranges.
+var privateNets = func() []*net.IPNet {
+	var out []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		out = append(out, n)
+	}
+	return out
+}()
+
+// destinationPolicy decides which remote addresses the downloader is allowed to connect to.
+type destinationPolicy struct {
+	hosts map[string]bool
+	nets  []*net.IPNet
+}
+
+// newDestinationPolicy creates the policy from the list of allowed host names and CIDR ranges.
+func newDestinationPolicy(allow []string) (*destinationPolicy, error) {
+	p := destinationPolicy{hosts: make(map[string]bool)}
+	for _, a := range allow {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		if strings.Contains(a, "/") {
+			_, n, err := net.ParseCIDR(a)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowed range %s; %w", a, err)
+			}
+			p.nets = append(p.nets, n)
+			continue
+		}
+		if ip := net.ParseIP(a); ip != nil {
+			p.nets = append(p.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		p.hosts[a] = true
+	}
+	return &p, nil
+}
+
+// allowHost adds the host of the given URL to the allowed hosts.
+func (p *destinationPolicy) allowHost(url string) {
+	if h := hostOf(url); h != "" {
+		if host, _, err := net.SplitHostPort(h); err == nil {
+			h = host
+		}
+		p.hosts[strings.ToLower(h)] = true
+	}
+}
+
+// isHostAllowed checks if the host name is explicitly allowed.
+func (p *destinationPolicy) isHostAllowed(host string) bool {
+	return p.hosts[strings.ToLower(host)]
+}
+
+// isIPAllowed checks if the downloader may connect to the given IP address.
+func (p *destinationPolicy) isIPAllowed(ip net.IP) bool {
+	for _, n := range p.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// control verifies the resolved address right before the connection is made,
+// so it can not be bypassed by DNS rebinding or by redirects.
+func (p *destinationPolicy) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !p.isIPAllowed(ip) {
+		return fmt.Errorf("%w %s", ErrForbiddenDestination, address)
+	}
+	return nil
+}
+
+// userAgentTransport sets the User-Agent header of all outgoing requests.
+type userAgentTransport struct {
+	agent string
+	next  http.RoundTripper
+}
+
+// RoundTrip executes a single HTTP transaction with the User-Agent set.
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.agent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.agent)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// newHttpClient creates the HTTP client used to download remote content.
+// The client refuses to connect to private and link-local addresses unless allowed by the config.
+func newHttpClient(cfg *config.Config) (*http.Client, error) {
+	policy, err := newDestinationPolicy(cfg.Downloader.AllowHosts)
+	if err != nil {
+		return nil, err
+	}
+	for _, gw := range newIpfsGateways(cfg) {
+		policy.allowHost(gw.url)
+	}
+	return newHttpClientWithPolicy(policy, cfg.Downloader.ConnectTimeout, cfg.Downloader.ReadTimeout,
+		cfg.Downloader.MaxRedirects, cfg.Downloader.UserAgent), nil
+}
+
+// newHttpClientWithPolicy creates the HTTP client restricted by the given destination policy.
+func newHttpClientWithPolicy(policy *destinationPolicy, connectTimeout time.Duration, readTimeout time.Duration, maxRedirects int, agent string) *http.Client {
+	open := &net.Dialer{Timeout: connectTimeout}
+	guarded := &net.Dialer{Timeout: connectTimeout, Control: policy.control}
+
+	transport := &http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if host, _, err := net.SplitHostPort(address); err == nil && policy.isHostAllowed(host) {
+				return open.DialContext(ctx, network, address)
+			}
+			return guarded.DialContext(ctx, network, address)
+		},
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: readTimeout,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: &userAgentTransport{agent: agent, next: transport},
+		Timeout:   readTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// readLimited reads the whole content of the reader failing if it exceeds the limit.
+// Zero limit means the size is not limited.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	out, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(out)) > limit {
+		return nil, fmt.Errorf("%w; more than %d bytes", ErrContentTooLarge, limit)
+	}
+	return out, nil
+}
+
+// checkContentLength fails early if the declared size of the response exceeds the limit.
+func checkContentLength(resp *http.Response, limit int64) error {
+	if limit > 0 && resp.ContentLength > limit {
+		return fmt.Errorf("%w; %d bytes declared, %d allowed", ErrContentTooLarge, resp.ContentLength, limit)
+	}
+	return nil
+}
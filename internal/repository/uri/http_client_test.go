@@ -0,0 +1,69 @@
+package uri
+
+import (
+	"errors"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttpClientDestinations(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/agent", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(r.UserAgent()))
+	}))
+	defer srv.Close()
+
+	policy, err := newDestinationPolicy(nil)
+	g.Expect(err).To(gomega.BeNil())
+	d := Downloader{client: newHttpClientWithPolicy(policy, time.Second, 5*time.Second, 5, "test-agent")}
+
+	_, _, err = d.getFromHttp(srv.URL+"/agent", 0)
+	g.Expect(errors.Is(err, ErrForbiddenDestination)).To(gomega.BeTrue())
+
+	policy, err = newDestinationPolicy([]string{"127.0.0.0/8"})
+	g.Expect(err).To(gomega.BeNil())
+	d = Downloader{client: newHttpClientWithPolicy(policy, time.Second, 5*time.Second, 5, "test-agent")}
+
+	data, _, err := d.getFromHttp(srv.URL+"/redirect", 0)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(data)).To(gomega.Equal("test-agent"))
+
+	d = Downloader{client: newHttpClientWithPolicy(policy, time.Second, 5*time.Second, 0, "test-agent")}
+	_, _, err = d.getFromHttp(srv.URL+"/redirect", 0)
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	_, err = newDestinationPolicy([]string{"10.0.0.0/33"})
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestHttpSizeLimit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(strings.Repeat("x", 1000)))
+	}))
+	defer srv.Close()
+
+	d := Downloader{}
+	_, _, err := d.getFromHttp(srv.URL+"/sized", 100)
+	g.Expect(errors.Is(err, ErrContentTooLarge)).To(gomega.BeTrue())
+
+	_, _, err = d.getFromHttp(srv.URL+"/chunked", 100)
+	g.Expect(errors.Is(err, ErrContentTooLarge)).To(gomega.BeTrue())
+
+	data, _, err := d.getFromHttp(srv.URL+"/sized", 1000)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(data)).To(gomega.Equal(1000))
+}
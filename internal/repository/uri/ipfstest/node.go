@@ -0,0 +1,129 @@
+// Package ipfstest provides an in-memory stand-in of an IPFS node to be used in tests.
+// The node serves the content both by the HTTP gateway interface ("/ipfs/{CID}/{path}")
+// and by the node API used by the IPFS shell ("/api/v0/cat"), so it can replace
+// a public gateway as well as a locally running IPFS node.
+package ipfstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Node represents a running IPFS node stand-in.
+type Node struct {
+	// URL of the node in the form "http://ipaddr:port", usable as a gateway and as an IPFS shell address
+	URL string
+
+	srv      *httptest.Server
+	mu       sync.Mutex
+	files    map[string][]byte
+	delay    time.Duration
+	failing  bool
+	requests int
+}
+
+// NewNode starts a new IPFS node stand-in. The caller should call Close when finished.
+func NewNode() *Node {
+	n := &Node{files: make(map[string][]byte)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ipfs/", n.serveGateway)
+	mux.HandleFunc("/ipns/", n.serveGateway)
+	mux.HandleFunc("/api/v0/cat", n.serveCat)
+
+	n.srv = httptest.NewServer(mux)
+	n.URL = n.srv.URL
+	return n
+}
+
+// Close shuts down the node.
+func (n *Node) Close() {
+	n.srv.Close()
+}
+
+// Add makes the data available on the given content path, i.e. "/ipfs/{CID}/meta.json" or "/ipns/{name}".
+func (n *Node) Add(contentPath string, data []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.files[path.Clean(contentPath)] = data
+}
+
+// SetDelay delays all the following responses by the given time.
+func (n *Node) SetDelay(delay time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.delay = delay
+}
+
+// SetFailing makes the node fail all the following requests with 502 Bad Gateway.
+func (n *Node) SetFailing(failing bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failing = failing
+}
+
+// Requests provides the number of requests received by the node.
+func (n *Node) Requests() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.requests
+}
+
+// lookup finds the content of the path applying the configured delay and failure.
+func (n *Node) lookup(req *http.Request, contentPath string) (data []byte, found bool, failed bool) {
+	n.mu.Lock()
+	n.requests++
+	delay, failing := n.delay, n.failing
+	data, found = n.files[path.Clean(contentPath)]
+	n.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, false, true
+		}
+	}
+	return data, found, failing
+}
+
+// serveGateway responds to HTTP gateway requests.
+func (n *Node) serveGateway(w http.ResponseWriter, req *http.Request) {
+	data, found, failed := n.lookup(req, req.URL.Path)
+	if failed {
+		http.Error(w, "gateway failure", http.StatusBadGateway)
+		return
+	}
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+	http.ServeContent(w, req, path.Base(req.URL.Path), time.Time{}, bytes.NewReader(data))
+}
+
+// serveCat responds to the "cat" command of the IPFS node API.
+func (n *Node) serveCat(w http.ResponseWriter, req *http.Request) {
+	arg := req.URL.Query().Get("arg")
+	if !strings.HasPrefix(arg, "/ipfs/") && !strings.HasPrefix(arg, "/ipns/") {
+		arg = "/ipfs/" + arg
+	}
+
+	data, found, failed := n.lookup(req, arg)
+	if failed || !found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"Message": "no link named " + path.Base(arg),
+			"Code":    0,
+			"Type":    "error",
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,106 @@
+package uri
+
+import (
+	"artion-api-graphql/internal/types"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// mimetypeSniffLength is the number of leading bytes needed to sniff the content type.
+const mimetypeSniffLength = 512
+
+// GetMimetype detects the mimetype of the media on given URI.
+// Only the head of the media is downloaded, so it can be used for large video and model files.
+func (d *Downloader) GetMimetype(uri string) (mimetype string, err error) {
+	var head []byte
+	if types.IsDataUri(uri) {
+		_, mimetype, err = d.getFromDataUri(uri)
+	} else if ipfsUri := d.getIpfsUri(uri); ipfsUri != "" {
+		head, mimetype, err = d.getHeadFromIpfs(ipfsUri)
+	} else if src := d.httpSource(uri); src != "" {
+		err = d.hosts.do(hostOf(src), d.failFast, func() (e error) {
+			head, mimetype, e = d.getHeadFromHttp(context.Background(), src, "")
+			return e
+		})
+	} else {
+		return "", fmt.Errorf("%w for %s", ErrUnknownScheme, uri)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to download media head; %w", err)
+	}
+	return detectMimetype(uri, mimetype, head), nil
+}
+
+// detectMimetype decides the mimetype of a media from the mimetype declared by the server,
+// the leading bytes of the media and the extension in the URI, in this order.
+func detectMimetype(uri string, declared string, head []byte) string {
+	if mt := strings.TrimSpace(strings.Split(declared, ";")[0]); !isGenericMimetype(mt) {
+		return mt
+	}
+	if len(head) > 0 {
+		if mt := strings.Split(http.DetectContentType(head), ";")[0]; !isGenericMimetype(mt) {
+			return mt
+		}
+	}
+	if mt := types.MimetypeFromExtension(uri); mt != "" {
+		return mt
+	}
+	return strings.TrimSpace(strings.Split(declared, ";")[0])
+}
+
+// isGenericMimetype checks if the mimetype does not say anything useful about the media.
+func isGenericMimetype(mt string) bool {
+	return mt == "" || mt == "application/octet-stream" || mt == "text/plain" || mt == "binary/octet-stream"
+}
+
+// getHeadFromIpfs downloads the head of the file from IPFS (URI is expected in "/ipfs/{CID}" or "/ipns/{name}" form).
+func (d *Downloader) getHeadFromIpfs(uri string) (head []byte, mimetype string, err error) {
+	if len(d.gateways) > 0 {
+		return d.hedged(func(ctx context.Context, gw ipfsGateway) ([]byte, string, error) {
+			return d.getHeadFromHttp(ctx, gw.url+uri, gw.bearer)
+		})
+	}
+
+	err = d.hosts.do(d.ipfsHost, d.failFast, func() error {
+		reader, err := d.ipfsShell.Cat(uri)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		head, err = io.ReadAll(io.LimitReader(reader, mimetypeSniffLength))
+		return err
+	})
+	return head, "", err
+}
+
+// getHeadFromHttp downloads the head of the file from HTTP using a range request.
+// Servers not supporting ranges send the whole file, we read just the head of it.
+func (d *Downloader) getHeadFromHttp(ctx context.Context, uri string, bearer string) (head []byte, mimetype string, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", mimetypeSniffLength-1))
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, "", &StatusError{Code: resp.StatusCode, Message: "HTTP server returned " + resp.Status}
+	}
+	out, err := io.ReadAll(io.LimitReader(resp.Body, mimetypeSniffLength))
+	if err != nil {
+		return nil, "", err
+	}
+	return out, resp.Header.Get("Content-Type"), nil
+}
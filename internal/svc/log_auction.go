@@ -28,6 +28,7 @@ func auctionCreated(evt *eth.Log, lo *logObserver) {
 
 	// make the listing
 	auction := types.Auction{
+		AuctionHall:   evt.Address,
 		Contract:      common.BytesToAddress(evt.Topics[1].Bytes()),
 		TokenId:       hexutil.Big(*new(big.Int).SetBytes(evt.Topics[2].Bytes())),
 		Owner:         common.Address{},
@@ -125,7 +126,7 @@ func auctionTimeBoundaryUpdated(evt *eth.Log, lo *logObserver, update func(*type
 	tokenID := new(big.Int).SetBytes(evt.Topics[2].Bytes())
 
 	// pull the auction involved
-	auction, err := repo.GetAuction(&contract, tokenID)
+	auction, err := repo.GetAuction(&evt.Address, &contract, tokenID)
 	if err != nil {
 		log.Errorf("updated auction %s/%s not found; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
 		return
@@ -186,7 +187,7 @@ func auctionReserveUpdated(evt *eth.Log, lo *logObserver) {
 	tokenID := new(big.Int).SetBytes(evt.Topics[2].Bytes())
 
 	// pull the auction involved
-	auction, err := repo.GetAuction(&contract, tokenID)
+	auction, err := repo.GetAuction(&evt.Address, &contract, tokenID)
 	if err != nil {
 		log.Errorf("updated auction %s/%s not found; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
 		return
@@ -262,7 +263,7 @@ func auctionCanceled(evt *eth.Log, _ *logObserver) {
 	tokenID := new(big.Int).SetBytes(evt.Topics[2].Bytes())
 
 	// pull the auction involved
-	auction, err := repo.GetAuction(&contract, tokenID)
+	auction, err := repo.GetAuction(&evt.Address, &contract, tokenID)
 	if err != nil {
 		log.Errorf("canceled auction %s/%s not found; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
 		return
@@ -371,7 +372,7 @@ func finishAuction(contract *common.Address, tokenID *big.Int, winner *common.Ad
 	}
 
 	// pull the auction involved
-	auction, err := repo.GetAuction(contract, tokenID)
+	auction, err := repo.GetAuction(&evt.Address, contract, tokenID)
 	if err != nil {
 		log.Errorf("resolved auction %s/%s not found; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
 		return
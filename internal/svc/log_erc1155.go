@@ -4,6 +4,7 @@ package svc
 import (
 	"artion-api-graphql/internal/types"
 	"bytes"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	eth "github.com/ethereum/go-ethereum/core/types"
@@ -16,7 +17,7 @@ import (
 // ERC1155::TransferSingle(address indexed _operator, address indexed _from, address indexed _to, uint256 _id, uint256 _amount)
 func erc1155TokenTransfer(evt *eth.Log, lo *logObserver) {
 	// sanity check: 1 + 3 topics; 2 x uint256 = 2 x 32 bytes of data
-	if len(evt.Data) != 34 || len(evt.Topics) != 4 {
+	if len(evt.Data) != 64 || len(evt.Topics) != 4 {
 		log.Errorf("not ERC1155::TransferSingle() event #%d / #%d; expected 64 bytes of data, %d given; expected 4 topics, %d given",
 			evt.BlockNumber, evt.Index, len(evt.Data), len(evt.Topics))
 		return
@@ -26,36 +27,166 @@ func erc1155TokenTransfer(evt *eth.Log, lo *logObserver) {
 	from := common.BytesToAddress(evt.Topics[2].Bytes())
 	to := common.BytesToAddress(evt.Topics[3].Bytes())
 	tokenId := new(big.Int).SetBytes(evt.Data[:32])
+	qty := new(big.Int).SetBytes(evt.Data[32:])
+
+	// get the block header
+	blk, err := repo.GetHeader(evt.BlockNumber)
+	if err != nil {
+		log.Errorf("can not load event header #%d; %s", evt.BlockNumber, err.Error())
+		return
+	}
 
 	// add recipient ownership record; we can do it first even for new tokens
-	if err := repo.StoreOwnership(&types.Ownership{
-		Contract: evt.Address,
-		TokenId:  hexutil.Big(*tokenId),
-		Owner:    to,
-		Qty:      balanceOf(&evt.Address, tokenId, &to, evt.BlockNumber, lo),
-		Updated:  types.Time(time.Now()),
-	}); err != nil {
-		log.Errorf("failed to update recipient ownership at %s/%d; %s",
-			evt.Address.String(), tokenId.Uint64(), err.Error())
+	// the zero address does not own burned tokens
+	if 0 != bytes.Compare(zeroAddress.Bytes(), to.Bytes()) {
+		updateERC1155Owner(evt, tokenId, to, blk.Time, lo)
 	}
 
 	// make sure we know the token
 	// if this is a mint call (the sender is zero), we just add the NFT record
 	if 0 == bytes.Compare(zeroAddress.Bytes(), from.Bytes()) {
 		addERC1155Token(&evt.Address, tokenId, &to, evt, lo)
+		updateERC1155Supply(&evt.Address, tokenId)
+		storeTransferActivity(evt, types.EvtTokenMinted, hexutil.Big(*tokenId), from, to, qty, blk.Time)
 		return
 	}
 
 	// if it's not a mint, we need to update sender's ownership balance as well
-	if err := repo.StoreOwnership(&types.Ownership{
+	updateERC1155Owner(evt, tokenId, from, blk.Time, lo)
+
+	// is this a token burn?
+	if 0 == bytes.Compare(zeroAddress.Bytes(), to.Bytes()) {
+		updateERC1155Supply(&evt.Address, tokenId)
+		registerERC1155TokenBurn(evt, tokenId, &from, qty, blk.Time)
+		lo.mgr.nftRarity.schedule(&evt.Address)
+		storeTransferActivity(evt, types.EvtTokenBurned, hexutil.Big(*tokenId), from, to, qty, blk.Time)
+		return
+	}
+	updateERC1155Supply(&evt.Address, tokenId)
+	storeTransferActivity(evt, types.EvtTokenTransferred, hexutil.Big(*tokenId), from, to, qty, blk.Time)
+}
+
+// erc1155BatchTransfer handles batch ERC1155 NFT tokens transfer; each of the transferred tokens
+// is processed the same way as a single transfer of the same log record.
+// ERC1155::TransferBatch(address indexed _operator, address indexed _from, address indexed _to, uint256[] _ids, uint256[] _amounts)
+func erc1155BatchTransfer(evt *eth.Log, lo *logObserver) {
+	// sanity check: 1 + 3 topics; 2 x offset + 2 x length = 4 x 32 bytes of data at least
+	if len(evt.Data) < 128 || len(evt.Topics) != 4 {
+		log.Errorf("not ERC1155::TransferBatch() event #%d / #%d; expected at least 128 bytes of data, %d given; expected 4 topics, %d given",
+			evt.BlockNumber, evt.Index, len(evt.Data), len(evt.Topics))
+		return
+	}
+
+	ids, err := unpackUint256Array(evt.Data, 0)
+	if err != nil {
+		log.Errorf("invalid ERC1155::TransferBatch() event #%d / #%d token IDs; %s", evt.BlockNumber, evt.Index, err.Error())
+		return
+	}
+	amounts, err := unpackUint256Array(evt.Data, 1)
+	if err != nil {
+		log.Errorf("invalid ERC1155::TransferBatch() event #%d / #%d amounts; %s", evt.BlockNumber, evt.Index, err.Error())
+		return
+	}
+	if len(ids) != len(amounts) {
+		log.Errorf("invalid ERC1155::TransferBatch() event #%d / #%d; %d token IDs, %d amounts", evt.BlockNumber, evt.Index, len(ids), len(amounts))
+		return
+	}
+
+	for i := range ids {
+		single := *evt
+		single.Data = append(common.LeftPadBytes(ids[i], 32), common.LeftPadBytes(amounts[i], 32)...)
+		erc1155TokenTransfer(&single, lo)
+	}
+}
+
+// unpackUint256Array provides the items of the ABI encoded uint256[] of the given argument position in the data.
+func unpackUint256Array(data []byte, arg int) ([][]byte, error) {
+	word := func(pos uint64) (uint64, error) {
+		if pos+32 > uint64(len(data)) {
+			return 0, fmt.Errorf("data too short, %d bytes, word at %d expected", len(data), pos)
+		}
+		val := new(big.Int).SetBytes(data[pos : pos+32])
+		if !val.IsUint64() {
+			return 0, fmt.Errorf("value at %d out of range", pos)
+		}
+		return val.Uint64(), nil
+	}
+
+	offset, err := word(uint64(arg) * 32)
+	if err != nil {
+		return nil, err
+	}
+	length, err := word(offset)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(data))/32 || offset+32+length*32 > uint64(len(data)) {
+		return nil, fmt.Errorf("array of %d items at %d exceeds %d bytes of data", length, offset, len(data))
+	}
+
+	list := make([][]byte, length)
+	for i := range list {
+		pos := offset + 32 + uint64(i)*32
+		list[i] = data[pos : pos+32]
+	}
+	return list, nil
+}
+
+// updateERC1155Owner updates the ownership balance of the given owner and records the change in the ownership history.
+func updateERC1155Owner(evt *eth.Log, tokenId *big.Int, owner common.Address, ts uint64, lo *logObserver) {
+	ow := types.Ownership{
 		Contract: evt.Address,
 		TokenId:  hexutil.Big(*tokenId),
-		Owner:    to,
-		Qty:      balanceOf(&evt.Address, tokenId, &from, evt.BlockNumber, lo),
+		Owner:    owner,
+		Qty:      balanceOf(&evt.Address, tokenId, &owner, evt.BlockNumber, lo),
 		Updated:  types.Time(time.Now()),
+	}
+	if err := repo.StoreOwnership(&ow); err != nil {
+		log.Errorf("failed to update ownership of %s at %s/%d; %s",
+			owner.String(), evt.Address.String(), tokenId.Uint64(), err.Error())
+		return
+	}
+	storeOwnershipChange(evt, ow.TokenId, owner, ow.Qty, ts)
+}
+
+// updateERC1155Supply refreshes the supply and the number of holders of an ERC-1155 token
+// from the balances of its owners; the owners' balances are loaded from the chain,
+// so processing the same transfer again does not change the result.
+func updateERC1155Supply(contract *common.Address, tokenID *big.Int) {
+	supply, holders, err := repo.TokenOwnershipsSupply(contract, (*hexutil.Big)(tokenID))
+	if err != nil {
+		log.Errorf("supply of %s/%s not known; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+		return
+	}
+
+	if err := repo.TokenUpdateSupply(contract, tokenID, supply, holders); err != nil {
+		log.Errorf("could not update supply of %s/%s; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+	}
+}
+
+// registerERC1155TokenBurn registers burn of the given amount of an ERC-1155 token.
+// The token itself is burned when no owner holds any amount of it anymore.
+func registerERC1155TokenBurn(evt *eth.Log, tokenID *big.Int, owner *common.Address, qty *big.Int, ts uint64) {
+	if err := repo.StoreBurn(&types.NFTBurn{
+		Contract:     evt.Address,
+		TokenId:      hexutil.Big(*tokenID),
+		Owner:        *owner,
+		Qty:          hexutil.Big(*qty),
+		Burned:       types.Time(time.Unix(int64(ts), 0)),
+		OrdinalIndex: types.OrdinalIndex(int64(evt.BlockNumber), int64(evt.Index)),
 	}); err != nil {
-		log.Errorf("failed to update sender ownership at %s/%d; %s",
-			evt.Address.String(), tokenId.Uint64(), err.Error())
+		log.Errorf("could not add ERC-1155 NFT burn; %s", err.Error())
+		return
+	}
+
+	// any owner left?
+	owners, err := repo.ListOwnerships(&evt.Address, (*hexutil.Big)(tokenID), nil, "", 1, false)
+	if err != nil {
+		log.Errorf("could not check ERC-1155 NFT owners; %s", err.Error())
+		return
+	}
+	if owners.TotalCount == 0 {
+		markTokenBurned(&evt.Address, tokenID, owner, ts)
 	}
 }
 
@@ -86,6 +217,18 @@ func balanceOf(con *common.Address, tokenId *big.Int, owner *common.Address, blo
 
 // addERC1155Token adds a new ERC1155 type of token into the repository.
 func addERC1155Token(adr *common.Address, tokenID *big.Int, creator *common.Address, evt *eth.Log, lo *logObserver) {
+	// additional supply of a known token can be minted; we don't want to reset the token
+	// a token burned completely before is alive again
+	if tok, err := repo.Token(adr, (*hexutil.Big)(tokenID)); err == nil && tok != nil {
+		log.Debugf("ERC-1155 token #%s at %s already known", tok.TokenId.String(), tok.Contract.String())
+		if tok.Burned != nil {
+			if err := repo.TokenClearBurned(adr, tokenID); err != nil {
+				log.Errorf("could not clear burn of token %s at %s; %s", tok.TokenId.String(), tok.Contract.String(), err.Error())
+			}
+		}
+		return
+	}
+
 	// extract the token URI from the contract
 	uri, err := repo.Erc1155TokenUri(adr, tokenID)
 	if err != nil {
@@ -108,8 +251,14 @@ func addERC1155Token(adr *common.Address, tokenID *big.Int, creator *common.Addr
 	// write token to the persistent storage
 	if err := repo.StoreToken(tok); err != nil {
 		log.Errorf("could not store token %s at %s; %s", tok.TokenId.String(), tok.Contract.String(), err.Error())
+	} else {
+		// link the token to the draft uploaded by the creator, if any
+		matchTokenDraft(tok)
 	}
 
 	// queue the token for metadata update
 	queueMetadataUpdate(tok, lo)
+
+	// a new token changes trait frequencies of the collection
+	lo.mgr.nftRarity.schedule(&tok.Contract)
 }
@@ -0,0 +1,32 @@
+package svc
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/onsi/gomega"
+	"math/big"
+	"testing"
+)
+
+func TestUnpackUint256Array(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	u256, err := abi.NewType("uint256[]", "", nil)
+	g.Expect(err).To(gomega.BeNil())
+	args := abi.Arguments{{Type: u256}, {Type: u256}}
+	data, err := args.Pack([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(300)}, []*big.Int{big.NewInt(10), big.NewInt(20), big.NewInt(30)})
+	g.Expect(err).To(gomega.BeNil())
+
+	ids, err := unpackUint256Array(data, 0)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ids).To(gomega.HaveLen(3))
+	g.Expect(new(big.Int).SetBytes(ids[2]).Int64()).To(gomega.Equal(int64(300)))
+
+	amounts, err := unpackUint256Array(data, 1)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(amounts).To(gomega.HaveLen(3))
+	g.Expect(new(big.Int).SetBytes(amounts[0]).Int64()).To(gomega.Equal(int64(10)))
+
+	// truncated data is refused
+	_, err = unpackUint256Array(data[:len(data)-32], 1)
+	g.Expect(err).NotTo(gomega.BeNil())
+}
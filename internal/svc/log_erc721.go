@@ -43,8 +43,10 @@ func erc721TokenMinted(evt *eth.Log, lo *logObserver) {
 	tok := types.NewToken(&evt.Address, args[0].(*big.Int), args[2].(string), int64(blk.Time), evt.BlockNumber, evt.Index)
 	log.Infof("ERC-721 token %s found at %s block %d", tok.TokenId.String(), tok.Contract.String(), evt.BlockNumber)
 
-	// store the extra data
+	// store the extra data; ERC-721 token is unique
 	tok.CreatedBy = args[3].(common.Address)
+	tok.Supply = hexutil.Big(*big.NewInt(1))
+	tok.Holders = 1
 
 	// write token to the persistent storage
 	if err := repo.StoreToken(tok); err != nil {
@@ -52,13 +54,19 @@ func erc721TokenMinted(evt *eth.Log, lo *logObserver) {
 		return
 	}
 
+	// link the token to the draft uploaded by the creator, if any
+	matchTokenDraft(tok)
+
 	// schedule metadata update on the token (do not wait for result)
 	queueMetadataUpdate(tok, lo)
+
+	// a new token changes trait frequencies of the collection
+	lo.mgr.nftRarity.schedule(&tok.Contract)
 }
 
 // erc721TokenTransfer handles log event for NFT token ownership transfer on an observed ERC721 contract.
 // ERC721::Transfer(address indexed from, address indexed to, uint256 indexed tokenId)
-func erc721TokenTransfer(evt *eth.Log, _ *logObserver) {
+func erc721TokenTransfer(evt *eth.Log, lo *logObserver) {
 	// sanity check: 1 + 3 extra topics for indexed parties; no additional data = 0 bytes
 	if len(evt.Data) != 0 || len(evt.Topics) != 4 {
 		log.Errorf("not ERC721::Transfer() event #%d/#%d; expected no data, %d given; expected 4 topics, %d given",
@@ -66,12 +74,6 @@ func erc721TokenTransfer(evt *eth.Log, _ *logObserver) {
 		return
 	}
 
-	// this may be a mint; if so, we have that one covered already
-	if 0 == bytes.Compare(zeroAddress.Bytes(), evt.Topics[1].Bytes()) {
-		log.Debug("ERC721::Mint() detected by token transfer")
-		return
-	}
-
 	// extract details
 	from := common.BytesToAddress(evt.Topics[1].Bytes())
 	to := common.BytesToAddress(evt.Topics[2].Bytes())
@@ -84,26 +86,70 @@ func erc721TokenTransfer(evt *eth.Log, _ *logObserver) {
 		return
 	}
 
+	// this may be a mint; if so, we have the token covered already
+	if 0 == bytes.Compare(zeroAddress.Bytes(), from.Bytes()) {
+		log.Debug("ERC721::Mint() detected by token transfer")
+		if err := updateERC721Owner(evt, tokenID, to, 1, blk.Time); err != nil {
+			log.Errorf("could not add ERC-721 NFT ownership; %s", err.Error())
+		}
+		storeTransferActivity(evt, types.EvtTokenMinted, tokenID, from, to, big.NewInt(1), blk.Time)
+
+		// the token may have been stored already by the mint event of the contract
+		if tok, err := repo.Token(&evt.Address, &tokenID); err == nil && tok != nil {
+			matchTokenDraft(tok)
+		}
+		return
+	}
+
 	// ERC-721 tokens don't have quantity; the amount is always 1
 	// we can just clear previous owner here by setting qty to zero
-	if err := updateERC721Owner(evt.Address, tokenID, from, 0, blk.Time); err != nil {
+	if err := updateERC721Owner(evt, tokenID, from, 0, blk.Time); err != nil {
 		log.Errorf("could not clear ERC-721 NFT ownership; %s", err.Error())
 		return
 	}
 
 	// is this a token burn event?
 	if 0 == bytes.Compare(to.Bytes(), zeroAddress.Bytes()) {
-		if err := registerERC721TokenBurn(evt.Address, tokenID, from, blk.Time); err != nil {
+		if err := registerERC721TokenBurn(evt, tokenID, from, blk.Time); err != nil {
 			log.Errorf("could not add ERC-721 NFT burn; %s", err.Error())
 		}
+		lo.mgr.nftRarity.schedule(&evt.Address)
+		storeTransferActivity(evt, types.EvtTokenBurned, tokenID, from, to, big.NewInt(1), blk.Time)
 		return
 	}
 
 	// now we can add the new owner
-	if err := updateERC721Owner(evt.Address, tokenID, to, 1, blk.Time); err != nil {
+	if err := updateERC721Owner(evt, tokenID, to, 1, blk.Time); err != nil {
 		log.Errorf("could not add ERC-721 NFT ownership; %s", err.Error())
 		return
 	}
+	storeTransferActivity(evt, types.EvtTokenTransferred, tokenID, from, to, big.NewInt(1), blk.Time)
+}
+
+// storeTransferActivity logs the given NFT token provenance activity; a mint, a transfer, or a burn.
+func storeTransferActivity(evt *eth.Log, at types.ActivityType, tokenID hexutil.Big, from common.Address, to common.Address, qty *big.Int, ts uint64) {
+	activity := types.Activity{
+		OrdinalIndex: types.OrdinalIndex(int64(evt.BlockNumber), int64(evt.Index)),
+		Time:         types.Time(time.Unix(int64(ts), 0)),
+		ActType:      at,
+		Contract:     evt.Address,
+		TokenId:      tokenID,
+		Quantity:     (*hexutil.Big)(qty),
+		From:         from,
+		To:           &to,
+	}
+	if err := repo.StoreActivity(&activity); err != nil {
+		log.Errorf("could not store transfer activity of %s/%s; %s", evt.Address.String(), tokenID.String(), err.Error())
+	}
+}
+
+// matchTokenDraft links the newly minted token to the draft uploaded by the creator, if any.
+func matchTokenDraft(tok *types.Token) {
+	if draft, err := repo.MatchTokenDraft(tok); err != nil {
+		log.Errorf("could not match draft of token %s at %s; %s", tok.TokenId.String(), tok.Contract.String(), err.Error())
+	} else if draft != nil {
+		log.Infof("token %s at %s minted from draft %s of %s", tok.TokenId.String(), tok.Contract.String(), draft.Uri, draft.Owner.String())
+	}
 }
 
 // queueMetadataUpdate pushes NFT into the metadata processing queue.
@@ -118,10 +164,10 @@ func queueMetadataUpdate(nft *types.Token, lo *logObserver) {
 }
 
 // updateERC721Owner pushes the ownership of ERC-721 token into persistent storage.
-func updateERC721Owner(contract common.Address, tokenID hexutil.Big, owner common.Address, qty uint64, ts uint64) error {
+func updateERC721Owner(evt *eth.Log, tokenID hexutil.Big, owner common.Address, qty uint64, ts uint64) error {
 	// now we can add the new owner
 	if err := repo.StoreOwnership(&types.Ownership{
-		Contract: contract,
+		Contract: evt.Address,
 		TokenId:  tokenID,
 		Owner:    owner,
 		Qty:      hexutil.Big(*new(big.Int).SetUint64(qty)),
@@ -129,22 +175,42 @@ func updateERC721Owner(contract common.Address, tokenID hexutil.Big, owner commo
 	}); err != nil {
 		return err
 	}
+	storeOwnershipChange(evt, tokenID, owner, hexutil.Big(*new(big.Int).SetUint64(qty)), ts)
 	return nil
 }
 
-// registerTokenBurn registers
-func registerERC721TokenBurn(contract common.Address, tokenID hexutil.Big, owner common.Address, ts uint64) error {
-	// now we can add the new owner
+// storeOwnershipChange adds the new balance of the owner into the ownership history.
+func storeOwnershipChange(evt *eth.Log, tokenID hexutil.Big, owner common.Address, qty hexutil.Big, ts uint64) {
+	if err := repo.StoreOwnershipChange(&types.OwnershipChange{
+		Contract:     evt.Address,
+		TokenId:      tokenID,
+		Owner:        owner,
+		Qty:          qty,
+		Block:        evt.BlockNumber,
+		Updated:      types.Time(time.Unix(int64(ts), 0)),
+		OrdinalIndex: types.OrdinalIndex(int64(evt.BlockNumber), int64(evt.Index)),
+	}); err != nil {
+		log.Errorf("could not store ownership change of %s/%s; %s", evt.Address.String(), tokenID.String(), err.Error())
+	}
+}
+
+// registerERC721TokenBurn registers burn of an ERC-721 token and closes the token lifecycle.
+func registerERC721TokenBurn(evt *eth.Log, tokenID hexutil.Big, owner common.Address, ts uint64) error {
+	contract := evt.Address
 	if err := repo.StoreBurn(&types.NFTBurn{
-		Contract: contract,
-		TokenId:  tokenID,
-		Owner:    owner,
-		Qty:      hexutil.Big(*new(big.Int).SetUint64(1)),
-		Burned:   types.Time(time.Unix(int64(ts), 0)),
+		Contract:     contract,
+		TokenId:      tokenID,
+		Owner:        owner,
+		Qty:          hexutil.Big(*new(big.Int).SetUint64(1)),
+		Burned:       types.Time(time.Unix(int64(ts), 0)),
+		OrdinalIndex: types.OrdinalIndex(int64(evt.BlockNumber), int64(evt.Index)),
 	}); err != nil {
 		return err
 	}
 
+	// the ERC-721 token is gone for good
+	markTokenBurned(&contract, (*big.Int)(&tokenID), &owner, ts)
+
 	// notify burn via notifications
 	repo.QueueNotificationForProcessing(&types.Notification{
 		Type:       types.NotifyBurnedNFTToken,
@@ -156,3 +222,25 @@ func registerERC721TokenBurn(contract common.Address, tokenID hexutil.Big, owner
 	})
 	return nil
 }
+
+// markTokenBurned marks the given NFT token as burned and closes all its open listings, offers and auctions.
+func markTokenBurned(contract *common.Address, tokenID *big.Int, by *common.Address, ts uint64) {
+	when := time.Unix(int64(ts), 0)
+	if err := repo.TokenMarkBurned(contract, tokenID, by, &when); err != nil {
+		log.Errorf("could not mark token %s/%s as burned; %s", contract.String(), (*hexutil.Big)(tokenID).String(), err.Error())
+	}
+
+	if err := repo.CloseListings(contract, tokenID, (*types.Time)(&when)); err != nil {
+		log.Errorf("could not close listings of burned token; %s", err.Error())
+	}
+
+	if err := repo.CloseOffers(contract, tokenID, (*types.Time)(&when)); err != nil {
+		log.Errorf("could not close offers of burned token; %s", err.Error())
+	}
+
+	if err := repo.CloseAuctions(contract, tokenID, (*types.Time)(&when)); err != nil {
+		log.Errorf("could not close auctions of burned token; %s", err.Error())
+	}
+
+	log.Infof("token %s/%s burned by %s", contract.String(), (*hexutil.Big)(tokenID).String(), by.String())
+}
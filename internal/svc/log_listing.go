@@ -28,6 +28,7 @@ func marketNFTListed(evt *eth.Log, lo *logObserver) {
 
 	// make the listing
 	lst := types.Listing{
+		Marketplace:  evt.Address,
 		Owner:        common.BytesToAddress(evt.Topics[1].Bytes()),
 		Contract:     common.BytesToAddress(evt.Topics[2].Bytes()),
 		TokenId:      hexutil.Big(*new(big.Int).SetBytes(evt.Data[:32])),
@@ -50,7 +51,7 @@ func marketNFTListed(evt *eth.Log, lo *logObserver) {
 	if err := repo.TokenMarkListed(
 		&lst.Contract,
 		(*big.Int)(&lst.TokenId),
-		repo.GetUnifiedPriceAt(lo.marketplace, &lst.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&lst.UnitPrice)),
+		repo.GetUnifiedPriceAt(&evt.Address, &lst.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&lst.UnitPrice)),
 		(*time.Time)(&lst.Created),
 	); err != nil {
 		log.Errorf("could not mark token as listed; %s", err.Error())
@@ -92,7 +93,7 @@ func marketNFTUpdated(evt *eth.Log, lo *logObserver) {
 	tokenID := new(big.Int).SetBytes(evt.Data[:32])
 
 	// try to get the listing
-	lst, err := repo.GetListing(&contract, tokenID, &owner)
+	lst, err := repo.GetListing(&evt.Address, &contract, tokenID, &owner)
 	if err != nil {
 		log.Errorf("update listing not found; %s", err.Error())
 		return
@@ -119,7 +120,7 @@ func marketNFTUpdated(evt *eth.Log, lo *logObserver) {
 	if err := repo.TokenMarkListed(
 		&lst.Contract,
 		(*big.Int)(&lst.TokenId),
-		repo.GetUnifiedPriceAt(lo.marketplace, &lst.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&lst.UnitPrice)),
+		repo.GetUnifiedPriceAt(&evt.Address, &lst.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&lst.UnitPrice)),
 		(*time.Time)(&lst.Created),
 	); err != nil {
 		log.Errorf("could not mark token as listed; %s", err.Error())
@@ -159,7 +160,7 @@ func marketNFTUnlisted(evt *eth.Log, _ *logObserver) {
 	tokenID := new(big.Int).SetBytes(evt.Data[:])
 
 	// try to get the listing
-	lst, err := repo.GetListing(&contract, tokenID, &owner)
+	lst, err := repo.GetListing(&evt.Address, &contract, tokenID, &owner)
 	if err != nil {
 		log.Errorf("listing not found; %s", err.Error())
 		return
@@ -222,14 +223,14 @@ func marketItemSold(evt *eth.Log, lo *logObserver) {
 	}
 
 	// try to get a listing
-	lst, err := repo.GetListing(&contract, tokenID, &owner)
+	lst, err := repo.GetListing(&evt.Address, &contract, tokenID, &owner)
 	if err == nil {
 		marketCloseListingWithSale(evt, lst, blk, lo, &buyer)
 		return
 	}
 
 	// try to get an offer
-	offer, err := repo.GetOffer(&contract, tokenID, &buyer)
+	offer, err := repo.GetOffer(&evt.Address, &contract, tokenID, &buyer)
 	if err == nil {
 		marketCloseOfferWithSale(evt, offer, blk, lo, &owner)
 		return
@@ -254,7 +255,7 @@ func marketCloseListingWithSale(evt *eth.Log, lst *types.Listing, blk *eth.Heade
 	if err := repo.TokenMarkSold(
 		&lst.Contract,
 		(*big.Int)(&lst.TokenId),
-		repo.GetUnifiedPriceAt(lo.marketplace, &lst.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&lst.UnitPrice)),
+		repo.GetUnifiedPriceAt(&evt.Address, &lst.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&lst.UnitPrice)),
 		&up,
 	); err != nil {
 		log.Errorf("could not mark token as sold; %s", err.Error())
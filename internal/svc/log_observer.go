@@ -50,7 +50,8 @@ type logObserver struct {
 	// nftTypes represents a map of types of observed NFT contracts.
 	nftTypes map[common.Address]string
 
-	// marketplace is the address of the Marketplace contract.
+	// marketplace is the address of the most recent Marketplace contract;
+	// it's used to convert pay token prices of events not emitted by a Marketplace.
 	marketplace *common.Address
 }
 
@@ -74,7 +75,7 @@ func newLogObserver(mgr *Manager) *logObserver {
 			common.HexToHash("0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62"): erc1155TokenTransfer,
 
 			/* erc1155::event TransferBatch(address indexed _operator, address indexed _from, address indexed _to, uint256[] _ids, uint256[] _amounts) */
-			// common.HexToHash("0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb"): erc1155BatchTransfer,
+			common.HexToHash("0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb"): erc1155BatchTransfer,
 
 			/* erc1155::event URI(string _uri, uint256 indexed _id) */
 			// common.HexToHash("0x6bb7ff708619ba0610cba295a58592e0451dee2622938c8755667688daf3529b"): erc1155UriChanged,
@@ -143,7 +144,7 @@ func (lo *logObserver) init() {
 	// get needed data sets
 	lo.contracts = repo.ObservedContractsAddressList()
 	lo.nftTypes = repo.NFTContractsTypeMap()
-	lo.marketplace = repo.ObservedContractAddressByType("market")
+	lo.marketplace = repo.ObservedContractAddressByType(types.ContractTypeMarketplace)
 
 	// make sure we have what we need
 	if lo.marketplace == nil {
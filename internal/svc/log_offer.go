@@ -28,6 +28,7 @@ func marketOfferCreated(evt *eth.Log, lo *logObserver) {
 
 	// create the offer record
 	offer := types.Offer{
+		Marketplace:  evt.Address,
 		Contract:     common.BytesToAddress(evt.Topics[2].Bytes()),
 		TokenId:      hexutil.Big(*new(big.Int).SetBytes(evt.Data[:32])),
 		ProposedBy:   common.BytesToAddress(evt.Topics[1].Bytes()),
@@ -49,7 +50,7 @@ func marketOfferCreated(evt *eth.Log, lo *logObserver) {
 	if err := repo.TokenMarkOffered(
 		&offer.Contract,
 		(*big.Int)(&offer.TokenId),
-		repo.GetUnifiedPriceAt(lo.marketplace, &offer.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&offer.UnitPrice)),
+		repo.GetUnifiedPriceAt(&evt.Address, &offer.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&offer.UnitPrice)),
 		(*time.Time)(&offer.Created),
 	); err != nil {
 		log.Errorf("could not mark token as having offer; %s", err.Error())
@@ -103,7 +104,7 @@ func marketOfferCanceled(evt *eth.Log, _ *logObserver) {
 	tokenID := new(big.Int).SetBytes(evt.Data[:])
 
 	// try to get the offer being canceled
-	offer, err := repo.GetOffer(&contract, tokenID, &proposer)
+	offer, err := repo.GetOffer(&evt.Address, &contract, tokenID, &proposer)
 	if err != nil {
 		log.Errorf("offer not found; %s", err.Error())
 		return
@@ -161,7 +162,7 @@ func marketCloseOfferWithSale(evt *eth.Log, offer *types.Offer, blk *eth.Header,
 	if err := repo.TokenMarkSold(
 		&offer.Contract,
 		(*big.Int)(&offer.TokenId),
-		repo.GetUnifiedPriceAt(lo.marketplace, &offer.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&offer.UnitPrice)),
+		repo.GetUnifiedPriceAt(&evt.Address, &offer.PayToken, new(big.Int).SetUint64(evt.BlockNumber), (*big.Int)(&offer.UnitPrice)),
 		&up,
 	); err != nil {
 		log.Errorf("could not mark token as sold; %s", err.Error())
@@ -18,7 +18,11 @@ type Manager struct {
 	logObserver     *logObserver
 	nftMetaUpdater  *nftMetadataUpdater
 	nftMetaWorker   *nftMetadataWorker
+	nftSupplyCheck  *nftSupplyChecker
+	nftRarity       *nftRarityCalculator
+	nftMedia        *nftMediaProcessor
 	notifyProcessor *notificationProcessor
+	uploadJanitor   *uploadJanitor
 }
 
 // newManager creates a new instance of the svc Manager.
@@ -39,7 +43,11 @@ func newManager() *Manager {
 	mgr.logObserver = newLogObserver(&mgr)
 	mgr.nftMetaUpdater = newNFTMetadataUpdater(&mgr)
 	mgr.nftMetaWorker = newNFTMetadataWorker(&mgr)
+	mgr.nftSupplyCheck = newNFTSupplyChecker(&mgr)
+	mgr.nftRarity = newNFTRarityCalculator(&mgr)
+	mgr.nftMedia = newNFTMediaProcessor(&mgr)
 	mgr.notifyProcessor = newNotificationProcessor(&mgr)
+	mgr.uploadJanitor = newUploadJanitor(&mgr)
 
 	// init and run
 	mgr.init()
@@ -53,9 +61,13 @@ func (mgr *Manager) init() {
 	mgr.blkScanner.init()
 	mgr.blkObserver.init()
 	mgr.logObserver.init()
+	mgr.nftMedia.init()
 	mgr.nftMetaWorker.init()
 	mgr.nftMetaUpdater.init()
+	mgr.nftSupplyCheck.init()
+	mgr.nftRarity.init()
 	mgr.notifyProcessor.init()
+	mgr.uploadJanitor.init()
 }
 
 // add managed service instance to the Manager and run it.
@@ -0,0 +1,164 @@
+// Package svc implements monitoring and scanning services of the API server.
+package svc
+
+import (
+	"artion-api-graphql/internal/types"
+	"math/big"
+	"time"
+)
+
+// nftMediaQueueCapacity is the capacity of the queue of tokens waiting for thumbnail generation.
+const nftMediaQueueCapacity = 500
+
+// nftMediaSweepTick is the tick used to pull tokens with thumbnails never generated, or lost while pending.
+const nftMediaSweepTick = 5 * time.Minute
+
+// nftMediaPurgeTick is the tick used to remove expired originals and media stored under outdated keys.
+const nftMediaPurgeTick = time.Hour
+
+// nftMediaProcessor represents a service responsible for pre-generation
+// of NFT image thumbnails after the token metadata is refreshed.
+type nftMediaProcessor struct {
+	// mgr represents the Manager instance
+	mgr *Manager
+
+	// sigStop represents the signal for closing the processor
+	sigStop chan bool
+
+	// queue is the queue of tokens waiting for thumbnail generation
+	queue chan *types.Token
+}
+
+// newNFTMediaProcessor creates a new instance of the NFT media processor service.
+func newNFTMediaProcessor(mgr *Manager) *nftMediaProcessor {
+	return &nftMediaProcessor{
+		mgr:     mgr,
+		sigStop: make(chan bool, 1),
+		queue:   make(chan *types.Token, nftMediaQueueCapacity),
+	}
+}
+
+// name provides the name of the service.
+func (mp *nftMediaProcessor) name() string {
+	return "nft media processor"
+}
+
+// init initializes the media processor and registers it with the manager.
+func (mp *nftMediaProcessor) init() {
+	mp.mgr.add(mp)
+}
+
+// close signals the media processor to terminate
+func (mp *nftMediaProcessor) close() {
+	mp.sigStop <- true
+}
+
+// schedule queues the image of the given token for thumbnail generation.
+// The token is skipped if the queue is full; it will be picked up by the sweep later.
+// Returns false if the token has not been queued.
+func (mp *nftMediaProcessor) schedule(tok *types.Token) bool {
+	// the status is set before queueing so it can not overwrite the result of the processing;
+	// the previous status is restored if the token does not make it to the queue
+	prev := tok.ThumbStatus
+	mp.setStatus(tok, types.ThumbnailPending)
+
+	select {
+	case mp.queue <- tok:
+		return true
+	default:
+		log.Warningf("thumbnail queue full, %s/%s skipped", tok.Contract.String(), tok.TokenId.String())
+		mp.setStatus(tok, prev)
+		return false
+	}
+}
+
+// run generates thumbnails of the queued tokens.
+func (mp *nftMediaProcessor) run() {
+	sweepTick := time.NewTicker(nftMediaSweepTick)
+	purgeTick := time.NewTicker(nftMediaPurgeTick)
+
+	defer func() {
+		sweepTick.Stop()
+		purgeTick.Stop()
+		mp.mgr.closed(mp)
+	}()
+
+	for {
+		select {
+		case <-mp.sigStop:
+			return
+		case tok := <-mp.queue:
+			mp.process(tok)
+		case <-sweepTick.C:
+			mp.sweep()
+		case <-purgeTick.C:
+			mp.purge()
+		}
+	}
+}
+
+// sweep queues tokens with thumbnails never generated (i.e. tokens known before the thumbnails
+// have been introduced) and tokens pending for too long, because they were lost on a restart.
+func (mp *nftMediaProcessor) sweep() {
+	free := int64(cap(mp.queue) - len(mp.queue))
+	if free <= 0 {
+		return
+	}
+
+	list, err := repo.TokenThumbnailSweepSet(time.Now().Add(-types.ThumbnailPendingTimeout), free)
+	if err != nil {
+		log.Errorf("thumbnail sweep set not available; %s", err.Error())
+		return
+	}
+
+	var count int
+	for _, tok := range list {
+		if !mp.schedule(tok) {
+			break
+		}
+		count++
+	}
+	if count > 0 {
+		log.Infof("%d tokens queued for thumbnail generation by sweep", count)
+	}
+}
+
+// purge removes original media kept longer than configured and files left over from previous media key versions.
+func (mp *nftMediaProcessor) purge() {
+	count, err := repo.PurgeDerivedMedia(time.Now().Add(-cfg.Media.OriginalExpiry))
+	if err != nil {
+		log.Errorf("derived media purge failed; %s", err.Error())
+		return
+	}
+	if count > 0 {
+		log.Infof("%d derived media files purged", count)
+	}
+}
+
+// process generates the thumbnail of the given token image and records the result.
+func (mp *nftMediaProcessor) process(tok *types.Token) {
+	// the repository keeps the generated thumbnail in the derived media store
+	if _, err := repo.GetImageThumbnail(tok.ImageURI); err != nil {
+		log.Errorf("thumbnail of %s/%s [%s] failed; %s", tok.Contract.String(), tok.TokenId.String(), tok.ImageURI, err.Error())
+		mp.setStatus(tok, types.ThumbnailFailed)
+		return
+	}
+
+	log.Debugf("thumbnail of %s/%s ready", tok.Contract.String(), tok.TokenId.String())
+	mp.setStatus(tok, types.ThumbnailReady)
+
+	// video preview clip is optional; it's generated on the first request if it fails here
+	if src := tok.PreviewSource(); src != "" {
+		if _, err := repo.GetImageThumbnailVariant(src, types.PreviewVariant); err != nil {
+			log.Errorf("preview of %s/%s [%s] failed; %s", tok.Contract.String(), tok.TokenId.String(), src, err.Error())
+		}
+	}
+}
+
+// setStatus updates the thumbnail status of the given token.
+func (mp *nftMediaProcessor) setStatus(tok *types.Token, status types.ThumbnailStatus) {
+	tok.ThumbStatus = status
+	if err := repo.TokenUpdateThumbnailStatus(&tok.Contract, (*big.Int)(&tok.TokenId), status); err != nil {
+		log.Errorf("thumbnail status of %s/%s not updated; %s", tok.Contract.String(), tok.TokenId.String(), err.Error())
+	}
+}
@@ -0,0 +1,154 @@
+// Package svc implements monitoring and scanning services of the API server.
+package svc
+
+import (
+	"artion-api-graphql/internal/types"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"sync"
+	"time"
+)
+
+// nftMetadataPriorityQueueCapacity is the capacity of the on-demand metadata refresh queue.
+const nftMetadataPriorityQueueCapacity = 1000
+
+// ErrRefreshRateLimited is returned if the user requests the same refresh again too soon.
+var ErrRefreshRateLimited = errors.New("metadata refresh requested too often, try again later")
+
+// ErrRefreshQueueFull is returned if the on-demand refresh queue is full.
+var ErrRefreshQueueFull = errors.New("metadata refresh queue is full, try again later")
+
+// metadataRefreshRequest represents an on-demand request of a token metadata refresh.
+// The outcome is reported to the user requesting the refresh.
+type metadataRefreshRequest struct {
+	token *types.Token
+	user  common.Address
+
+	// limitKey identifies the request in the token refresh limiter, empty for bulk requests
+	limitKey string
+}
+
+// refreshLimiterPurgeSize is the number of requests kept by a refresh limiter above which expired requests are purged.
+const refreshLimiterPurgeSize = 10000
+
+// refreshLimiter keeps the time until which a repeated refresh request of a user is refused.
+type refreshLimiter struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// newRefreshLimiter creates a new empty refresh limiter.
+func newRefreshLimiter() *refreshLimiter {
+	return &refreshLimiter{until: make(map[string]time.Time)}
+}
+
+// tokenRefreshLimits and collectionRefreshLimits hold the on-demand refresh rate limits of the service manager.
+var (
+	tokenRefreshLimits      = newRefreshLimiter()
+	collectionRefreshLimits = newRefreshLimiter()
+)
+
+// allow checks if the request identified by the key can be done now, and if so,
+// records it so it's refused for the given interval.
+func (rl *refreshLimiter) allow(key string, interval time.Duration) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if until, ok := rl.until[key]; ok && now.Before(until) {
+		return false
+	}
+
+	// forget requests no longer limiting anybody so the map does not grow forever
+	if len(rl.until) >= refreshLimiterPurgeSize {
+		for k, until := range rl.until {
+			if !now.Before(until) {
+				delete(rl.until, k)
+			}
+		}
+	}
+
+	rl.until[key] = now.Add(interval)
+	return true
+}
+
+// forget removes the request identified by the key, so it can be repeated immediately.
+func (rl *refreshLimiter) forget(key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	delete(rl.until, key)
+}
+
+// RefreshTokenMetadata puts the token to the front of the metadata update queue on behalf of the user.
+// The outcome of the refresh is published to the user events subscription.
+func (mgr *Manager) RefreshTokenMetadata(user common.Address, contract common.Address, tokenID hexutil.Big) error {
+	key := fmt.Sprintf("%s/%s/%s", user.String(), contract.String(), tokenID.String())
+	if !tokenRefreshLimits.allow(key, cfg.Downloader.RefreshInterval) {
+		return ErrRefreshRateLimited
+	}
+
+	tok, err := repo.Token(&contract, &tokenID)
+	if err != nil || tok == nil {
+		tokenRefreshLimits.forget(key)
+		if err == nil {
+			err = fmt.Errorf("token %s/%s not found", contract.String(), tokenID.String())
+		}
+		return err
+	}
+
+	// the worker updates the token in place, make sure it does not share it with anybody
+	cp := *tok
+	if !mgr.nftMetaWorker.prioritize(&metadataRefreshRequest{token: &cp, user: user, limitKey: key}) {
+		tokenRefreshLimits.forget(key)
+		return ErrRefreshQueueFull
+	}
+	log.Infof("metadata refresh of %s/%s requested by %s", contract.String(), tokenID.String(), user.String())
+	return nil
+}
+
+// RefreshCollectionMetadata puts tokens of the collection to the front of the metadata update queue
+// on behalf of the user. The number of tokens scheduled for the refresh is returned.
+func (mgr *Manager) RefreshCollectionMetadata(user common.Address, contract common.Address) (int32, error) {
+	key := fmt.Sprintf("%s/%s", user.String(), contract.String())
+	if !collectionRefreshLimits.allow(key, cfg.Downloader.CollectionRefreshInterval) {
+		return 0, ErrRefreshRateLimited
+	}
+
+	list, err := repo.CollectionMetadataRefreshSet(&contract, cfg.Downloader.CollectionRefreshLimit)
+	if err != nil {
+		collectionRefreshLimits.forget(key)
+		return 0, err
+	}
+
+	var count int32
+	for _, tok := range list {
+		if !mgr.nftMetaWorker.prioritizeBulk(&metadataRefreshRequest{token: tok, user: user}) {
+			break
+		}
+		count++
+	}
+	log.Infof("metadata refresh of %d tokens of %s requested by %s", count, contract.String(), user.String())
+
+	if count == 0 && len(list) > 0 {
+		collectionRefreshLimits.forget(key)
+		return 0, ErrRefreshQueueFull
+	}
+	return count, nil
+}
+
+// publishRefreshOutcome reports the outcome of an on-demand metadata refresh to the requesting user.
+// A failed refresh of a single token can be requested again immediately.
+func publishRefreshOutcome(req *metadataRefreshRequest, err error) {
+	event := types.Event{Type: "METADATA_REFRESHED", Token: req.token}
+	if err != nil {
+		event.Type = "METADATA_REFRESH_FAILED"
+		event.Message = err.Error()
+
+		if req.limitKey != "" {
+			tokenRefreshLimits.forget(req.limitKey)
+		}
+	}
+	GetSubscriptionsManager().PublishUserEvent(req.user, event)
+}
@@ -0,0 +1,68 @@
+package svc
+
+import (
+	"github.com/onsi/gomega"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRefreshLimiter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	rl := newRefreshLimiter()
+
+	// repeated request is refused within the interval
+	g.Expect(rl.allow("user/token", time.Minute)).To(gomega.BeTrue())
+	g.Expect(rl.allow("user/token", time.Minute)).To(gomega.BeFalse())
+
+	// other requests are not affected
+	g.Expect(rl.allow("user/other", time.Minute)).To(gomega.BeTrue())
+
+	// forgotten request can be repeated immediately
+	rl.forget("user/token")
+	g.Expect(rl.allow("user/token", time.Minute)).To(gomega.BeTrue())
+
+	// the request is allowed again once the interval passes
+	g.Expect(rl.allow("user/short", 10*time.Millisecond)).To(gomega.BeTrue())
+	time.Sleep(20 * time.Millisecond)
+	g.Expect(rl.allow("user/short", 10*time.Millisecond)).To(gomega.BeTrue())
+}
+
+func TestRefreshLimiterPurge(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	rl := newRefreshLimiter()
+
+	// a long limit survives the purge of expired short ones
+	g.Expect(rl.allow("user/collection", time.Hour)).To(gomega.BeTrue())
+	for i := 0; i < refreshLimiterPurgeSize; i++ {
+		rl.allow("user/token/"+strconv.Itoa(i), time.Nanosecond)
+	}
+	time.Sleep(time.Millisecond)
+	g.Expect(rl.allow("user/token/new", time.Nanosecond)).To(gomega.BeTrue())
+
+	g.Expect(len(rl.until)).To(gomega.BeNumerically("<", 10))
+	g.Expect(rl.allow("user/collection", time.Hour)).To(gomega.BeFalse())
+}
+
+func TestTokenLocks(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	tl := newTokenLocks()
+
+	// a token being processed is refused, other tokens are not affected
+	g.Expect(tl.tryLock("contract/1")).To(gomega.BeTrue())
+	g.Expect(tl.tryLock("contract/1")).To(gomega.BeFalse())
+	g.Expect(tl.lock("contract/2")).To(gomega.BeFalse())
+
+	// the waiting lock gets the token once the processing ends
+	acquired := make(chan bool)
+	go func() {
+		acquired <- tl.lock("contract/1")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	tl.unlock("contract/1")
+	g.Eventually(acquired).Should(gomega.Receive(gomega.BeTrue()))
+
+	g.Expect(tl.tryLock("contract/1")).To(gomega.BeFalse())
+	tl.unlock("contract/1")
+	g.Expect(tl.tryLock("contract/1")).To(gomega.BeTrue())
+}
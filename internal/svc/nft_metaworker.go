@@ -3,12 +3,18 @@ package svc
 
 import (
 	"artion-api-graphql/internal/types"
+	"errors"
 	"strings"
+	"sync"
 	"time"
 )
 
+// errEmptyMetadata is the failure recorded with tokens having metadata with no name, description and image.
+var errEmptyMetadata = errors.New("metadata have no name, description and image")
+
 // nftMetadataWorker represents a service responsible for processing NFT token metadata
-// update queue from the metadata updater service.
+// update queue from the metadata updater service. The queue is processed by a pool
+// of workers, so a slow metadata host does not block tokens hosted elsewhere.
 type nftMetadataWorker struct {
 	// mgr represents the Manager instance
 	mgr *Manager
@@ -18,14 +24,110 @@ type nftMetadataWorker struct {
 
 	// inTokens represents the channel receiving tokens to be updated.
 	inTokens chan *types.Token
+
+	// priority represents the queue of on-demand refresh requests processed before the regular updates.
+	priority chan *metadataRefreshRequest
+
+	// workers is the number of workers processing the queue in parallel
+	workers int
+
+	// inProgress keeps tokens being updated, so the regular and the priority queue do not update a token at once
+	inProgress *tokenLocks
+}
+
+// tokenLocks keeps tokens being processed; each of them has a channel closed when the processing ends.
+type tokenLocks struct {
+	mu   sync.Mutex
+	busy map[string]chan struct{}
+}
+
+// newTokenLocks creates a new empty set of token locks.
+func newTokenLocks() *tokenLocks {
+	return &tokenLocks{busy: make(map[string]chan struct{})}
+}
+
+// tokenLockKey provides the key of the given token in the token locks.
+func tokenLockKey(tok *types.Token) string {
+	return tok.Contract.String() + "/" + tok.TokenId.String()
+}
+
+// tryLock marks the token as being processed, if it's not processed already.
+// Returns false if the token is being processed.
+func (tl *tokenLocks) tryLock(key string) bool {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if _, ok := tl.busy[key]; ok {
+		return false
+	}
+	tl.busy[key] = make(chan struct{})
+	return true
+}
+
+// lock marks the token as being processed; it waits for the current processing of the token to end, if any.
+// Returns true if the caller had to wait.
+func (tl *tokenLocks) lock(key string) (waited bool) {
+	for {
+		tl.mu.Lock()
+		done, ok := tl.busy[key]
+		if !ok {
+			tl.busy[key] = make(chan struct{})
+			tl.mu.Unlock()
+			return waited
+		}
+		tl.mu.Unlock()
+
+		<-done
+		waited = true
+	}
+}
+
+// unlock ends the processing of the token and releases anybody waiting for it.
+func (tl *tokenLocks) unlock(key string) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if done, ok := tl.busy[key]; ok {
+		close(done)
+		delete(tl.busy, key)
+	}
 }
 
 // newNFTMetadataUpdater creates a new instance of the NFT metadata worker service.
 func newNFTMetadataWorker(mgr *Manager) *nftMetadataWorker {
+	workers := cfg.Downloader.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
 	return &nftMetadataWorker{
-		mgr:     mgr,
-		sigStop: make(chan bool, 1),
+		mgr:        mgr,
+		sigStop:    make(chan bool, 1),
+		priority:   make(chan *metadataRefreshRequest, nftMetadataPriorityQueueCapacity),
+		workers:    workers,
+		inProgress: newTokenLocks(),
+	}
+}
+
+// prioritize adds the on-demand refresh request into the priority queue without waiting.
+// Returns false if the queue is full.
+func (mw *nftMetadataWorker) prioritize(req *metadataRefreshRequest) bool {
+	select {
+	case mw.priority <- req:
+		return true
+	default:
+		return false
+	}
+}
+
+// prioritizeBulk adds the refresh request of a bulk (collection) refresh into the priority queue without waiting.
+// Bulk requests may fill just a half of the queue, so refreshes of single tokens still get in.
+// Returns false if the bulk part of the queue is full.
+func (mw *nftMetadataWorker) prioritizeBulk(req *metadataRefreshRequest) bool {
+	if len(mw.priority) >= cap(mw.priority)/2 {
+		return false
 	}
+	return mw.prioritize(req)
 }
 
 // name provides the name of the service.
@@ -47,33 +149,106 @@ func (mw *nftMetadataWorker) close() {
 	mw.sigStop <- true
 }
 
-// run processes incoming NFT metadata update requests from the
-// incoming queue.
+// run starts the pool of workers processing incoming NFT metadata update requests
+// and waits for the termination signal.
 func (mw *nftMetadataWorker) run() {
+	var wg sync.WaitGroup
+	quit := make(chan bool)
+
 	defer func() {
+		close(quit)
+		wg.Wait()
 		mw.mgr.closed(mw)
 	}()
 
+	for i := 0; i < mw.workers; i++ {
+		wg.Add(1)
+		go mw.work(quit, &wg)
+	}
+	log.Noticef("%d metadata workers started", mw.workers)
+
+	// all the workers end if the incoming queue is closed
+	drained := make(chan bool)
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-mw.sigStop:
+	case <-drained:
+	}
+}
+
+// work processes incoming NFT metadata update requests from the
+// incoming queue until the quit signal is received.
+func (mw *nftMetadataWorker) work(quit chan bool, wg *sync.WaitGroup) {
+	defer wg.Done()
+
 	for {
+		// on-demand refresh requests go first
+		select {
+		case req := <-mw.priority:
+			mw.refresh(req)
+			continue
+		default:
+		}
+
 		// pull next token
 		select {
-		case <-mw.sigStop:
+		case <-quit:
 			return
+		case req := <-mw.priority:
+			mw.refresh(req)
 		case tok, ok := <-mw.inTokens:
 			if !ok {
 				return
 			}
 
 			// process the token metadata update
-			if err := mw.update(tok); err != nil {
-				log.Errorf("NFT update failed; %s", err.Error())
-			}
+			mw.process(tok)
+		}
+	}
+}
+
+// process updates the token from the regular queue. A token being refreshed on demand
+// is skipped, the refresh updates it and schedules its next regular update.
+func (mw *nftMetadataWorker) process(tok *types.Token) {
+	key := tokenLockKey(tok)
+	if !mw.inProgress.tryLock(key) {
+		log.Debugf("NFT %s/%s skipped, update in progress", tok.Contract.String(), tok.TokenId.String())
+		return
+	}
+	defer mw.inProgress.unlock(key)
+
+	if err := mw.update(tok, false); err != nil {
+		log.Errorf("NFT update failed; %s", err.Error())
+	}
+}
+
+// refresh processes the on-demand refresh request and reports the outcome to the requesting user.
+// A regular update of the token in progress is waited for; the token is reloaded afterwards,
+// so the refresh does not overwrite the result of the update with a stale state.
+func (mw *nftMetadataWorker) refresh(req *metadataRefreshRequest) {
+	key := tokenLockKey(req.token)
+	if mw.inProgress.lock(key) {
+		if tok, err := repo.Token(&req.token.Contract, &req.token.TokenId); err == nil && tok != nil {
+			cp := *tok
+			req.token = &cp
 		}
 	}
+	defer mw.inProgress.unlock(key)
+
+	err := mw.update(req.token, true)
+	if err != nil {
+		log.Errorf("NFT refresh failed; %s", err.Error())
+	}
+	publishRefreshOutcome(req, err)
 }
 
 // update the given NFT metadata from external metadata source.
-func (mw *nftMetadataWorker) update(tok *types.Token) error {
+// On-demand refreshes wait for busy hosts, regular updates are deferred instead.
+func (mw *nftMetadataWorker) update(tok *types.Token, onDemand bool) error {
 	// get metadata
 	if tok.Uri == "" {
 		log.Infof("token %s/%s metadata URI not available", tok.Contract.String(), tok.TokenId.String())
@@ -82,25 +257,37 @@ func (mw *nftMetadataWorker) update(tok *types.Token) error {
 
 	// get the metadata
 	log.Debugf("loading metadata for %s/%s", tok.Contract.String(), tok.TokenId.String())
-	md, err := repo.GetTokenJsonMetadata(tok.Uri)
+	md, data, err := downloadTokenJsonMetadata(tok.Uri, onDemand)
 	if err != nil {
 		log.Errorf("NFT metadata [%s] failed on %s/%s; %s", tok.Uri, tok.Contract.String(), tok.TokenId.String(), err.Error())
-		handleTokenMetaUpdateFailure(tok)
+		handleTokenMetaUpdateFailure(tok, err, tok.Uri)
 		return err
 	}
 
 	// get image type (skip if the image URI has not changed)
-	if md.Image != nil && *md.Image != tok.ImageURI {
-		img, err := repo.GetImage(*md.Image)
+	imageChanged := md.Image != nil && *md.Image != tok.ImageURI
+	if imageChanged {
+		img, err := downloadTokenImage(*md.Image, onDemand)
 		if err != nil {
 			log.Errorf("NFT image [%s] failed on %s/%s; %s", md.Image, tok.Contract.String(), tok.TokenId.String(), err.Error())
-			handleTokenMetaUpdateFailure(tok)
+			handleTokenMetaUpdateFailure(tok, err, *md.Image)
 			return err
 		}
 		log.Debugf("NFT image [%s] has type %d", *md.Image, img.Type)
 		tok.ImageType = img.Type
 	}
 
+	// get animation media type (skip if the animation URI has not changed)
+	if md.AnimationUrl != nil && strings.TrimSpace(*md.AnimationUrl) != tok.AnimationURI {
+		if err := updateTokenAnimation(tok, strings.TrimSpace(*md.AnimationUrl), onDemand); err != nil {
+			handleTokenMetaUpdateFailure(tok, err, *md.AnimationUrl)
+			return err
+		}
+	}
+
+	// keep the previous state to detect changes
+	prevName, prevImage := tok.Name, tok.ImageURI
+
 	// update the data
 	tok.ScheduleMetaUpdateOnSuccess()
 	tok.Name = strings.TrimSpace(md.Name)
@@ -110,30 +297,176 @@ func (mw *nftMetadataWorker) update(tok *types.Token) error {
 	if md.Image != nil {
 		tok.ImageURI = strings.TrimSpace(*md.Image)
 	}
+	if md.AnimationUrl == nil || strings.TrimSpace(*md.AnimationUrl) == "" {
+		tok.AnimationURI, tok.AnimationType, tok.AnimationMedia = "", "", types.MediaKindUnknown
+	}
+	tok.ExternalLink = md.ExternalLink()
+	tok.BgColor = md.BgColor()
+	tok.Attributes = md.TokenAttributes()
 
 	updateTokenCategoriesFromCollection(tok)
 
 	// does this token make a sense?
 	tok.IsActive = tok.Name != "" || tok.Description != "" || tok.ImageURI != ""
 
+	// empty metadata are usually not revealed yet; they are kept as a failure, so they are retried and can be found
+	if !tok.IsActive {
+		tok.ScheduleMetaUpdateOnFailure()
+		tok.MetaError = types.NewTokenMetaError(types.MetaErrorEmptyMetadata, errEmptyMetadata, tok.Uri)
+	}
+
 	// update the token in persistent storage
 	if err := repo.UpdateTokenMetadata(tok); err != nil {
 		log.Errorf("failed metadata update on %s/%s; %s", tok.Contract.String(), tok.TokenId.String(), err.Error())
 		return err
 	}
 	log.Infof("NFT %s/%s metadata updated [%s]", tok.Contract.String(), tok.TokenId.String(), tok.Name)
+
+	// keep the version of the metadata, so we know what the token looked like before any change
+	recordTokenMetadataVersion(tok, data, prevName, prevImage)
+
+	// traits of the token may have changed, the collection rarity needs to be recalculated
+	mw.mgr.nftRarity.schedule(&tok.Contract)
+
+	// pre-generate the image thumbnail so the first visitor does not wait for it
+	if tok.ImageURI != "" && (imageChanged || tok.ThumbStatus != types.ThumbnailReady) {
+		mw.mgr.nftMedia.schedule(tok)
+	}
+	return nil
+}
+
+// updateTokenAnimation sets the animation of the token and detects its media type.
+// The animation is optional, so a failed detection does not fail the metadata update;
+// we fall back to the file extension in the URI. Only a busy or backed off host
+// of the animation is reported, so the update is deferred.
+func updateTokenAnimation(tok *types.Token, uri string, onDemand bool) error {
+	if uri == "" {
+		tok.AnimationURI, tok.AnimationType, tok.AnimationMedia = "", "", types.MediaKindUnknown
+		return nil
+	}
+
+	mt, err := detectTokenMimetype(uri, onDemand)
+	if err != nil && repo.MetadataErrorCategory(err) == types.MetaErrorHostUnavailable {
+		return err
+	}
+	if err != nil {
+		log.Warningf("NFT animation [%s] type not detected on %s/%s; %s", uri, tok.Contract.String(), tok.TokenId.String(), err.Error())
+		mt = types.MimetypeFromExtension(uri)
+	}
+	tok.AnimationURI = uri
+	tok.AnimationType = mt
+	tok.AnimationMedia = types.MediaKindFromMimetype(mt)
+	log.Debugf("NFT animation [%s] has type %s", uri, mt)
 	return nil
 }
 
-func handleTokenMetaUpdateFailure(tok *types.Token) {
+// downloadTokenJsonMetadata downloads the token metadata; on-demand requests wait for a busy host.
+func downloadTokenJsonMetadata(uri string, onDemand bool) (*types.JsonMetadata, []byte, error) {
+	if onDemand {
+		return repo.GetTokenJsonMetadataData(uri)
+	}
+	return repo.DownloadTokenJsonMetadata(uri)
+}
+
+// downloadTokenImage downloads the token image; on-demand requests wait for a busy host.
+func downloadTokenImage(uri string, onDemand bool) (*types.Image, error) {
+	if onDemand {
+		return repo.GetImage(uri)
+	}
+	return repo.DownloadImage(uri)
+}
+
+// detectTokenMimetype detects the mimetype of the token media; on-demand requests wait for a busy host.
+func detectTokenMimetype(uri string, onDemand bool) (string, error) {
+	if onDemand {
+		return repo.GetMimetype(uri)
+	}
+	return repo.DetectMimetype(uri)
+}
+
+// recordTokenMetadataVersion stores the fetched metadata document as a version of the token metadata.
+// If the name or the image of the token changed, the change is recorded as a token activity;
+// a return to a version seen before (A -> B -> A) is a change too.
+// The history is informative, a failure here does not fail the metadata update.
+func recordTokenMetadataVersion(tok *types.Token, data []byte, prevName string, prevImage string) {
+	mv := types.NewTokenMetadataVersion(tok, data)
+	if _, err := repo.StoreTokenMetadataVersion(mv); err != nil {
+		log.Errorf("NFT %s/%s metadata version not stored; %s", tok.Contract.String(), tok.TokenId.String(), err.Error())
+		return
+	}
+
+	// the first fetch of the metadata is not a change
+	if (prevName == "" && prevImage == "") || (prevName == tok.Name && prevImage == tok.ImageURI) {
+		return
+	}
+
+	idx, seq, err := nextMetadataChangeOrdinalIndex()
+	if err != nil {
+		log.Errorf("metadata change of %s/%s not ordered; %s", tok.Contract.String(), tok.TokenId.String(), err.Error())
+		return
+	}
+
+	activity := types.Activity{
+		OrdinalIndex: idx,
+		Seq:          seq,
+		Time:         mv.LastSeen,
+		ActType:      types.EvtMetadataChanged,
+		Contract:     tok.Contract,
+		TokenId:      tok.TokenId,
+		From:         tok.CreatedBy,
+	}
+	if err := repo.StoreActivity(&activity); err != nil {
+		log.Errorf("could not store metadata change activity of %s/%s; %s", tok.Contract.String(), tok.TokenId.String(), err.Error())
+		return
+	}
+	log.Noticef("NFT %s/%s metadata changed [%s]", tok.Contract.String(), tok.TokenId.String(), tok.Name)
+}
+
+// nextMetadataChangeOrdinalIndex provides the ordinal index and the activity sequence of a new metadata change.
+// Changes are placed at the last seen block, after its log records; the sequence keeps them unique.
+func nextMetadataChangeOrdinalIndex() (int64, int64, error) {
+	blk, err := repo.LastSeenBlockNumber()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	seq, err := repo.NextMetadataChangeSequence(blk)
+	if err != nil {
+		return 0, 0, err
+	}
+	return types.MetadataChangeOrdinalIndex(blk), seq + 1, nil
+}
+
+// handleTokenMetaUpdateFailure reschedules the metadata update of the token after a failure
+// and keeps the reason of the failure on the given URL with the token for diagnostics.
+// If the host is busy or backed off, the update has not been attempted at all; it's deferred
+// without counting the failure.
+func handleTokenMetaUpdateFailure(tok *types.Token, err error, url string) {
+	category := repo.MetadataErrorCategory(err)
+	if category == types.MetaErrorHostUnavailable {
+		deferTokenMetaUpdate(tok)
+		return
+	}
+
 	tok.ScheduleMetaUpdateOnFailure()
+	tok.MetaError = types.NewTokenMetaError(category, err, url)
 	if e := repo.UpdateTokenMetadataRefreshSchedule(tok); e != nil {
-		log.Errorf("token schedule update failed;", e.Error())
+		log.Errorf("token schedule update failed; %s", e.Error())
 	}
 
-	log.Infof("next update #%d of %s/%s at %s",
+	log.Infof("next update #%d of %s/%s at %s after %s failure",
 		tok.MetaFailures, tok.Contract.String(), tok.TokenId.String(),
-		time.Time(tok.MetaUpdate).Format(time.Stamp))
+		time.Time(tok.MetaUpdate).Format(time.Stamp), tok.MetaError.Category)
+}
+
+// deferTokenMetaUpdate postpones the metadata update of the token which could not be attempted now.
+func deferTokenMetaUpdate(tok *types.Token) {
+	tok.ScheduleMetaUpdateDeferred()
+	if e := repo.UpdateTokenMetadataRefreshSchedule(tok); e != nil {
+		log.Errorf("token schedule update failed; %s", e.Error())
+	}
+	log.Debugf("update of %s/%s deferred to %s, the host is not available",
+		tok.Contract.String(), tok.TokenId.String(), time.Time(tok.MetaUpdate).Format(time.Stamp))
 }
 
 func updateTokenCategoriesFromCollection(tok *types.Token) {
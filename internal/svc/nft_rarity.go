@@ -0,0 +1,124 @@
+// Package svc implements monitoring and scanning services of the API server.
+package svc
+
+import (
+	"artion-api-graphql/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"sync"
+	"time"
+)
+
+// nftRarityTick is the tick used to recalculate rarity of collections changed since the last tick.
+// Changes are collected for a while, so a collection being minted is not recalculated on each token.
+const nftRarityTick = 2 * time.Minute
+
+// nftRarityCalculator represents a service responsible for calculation of rarity
+// scores and ranks of NFT tokens from trait frequencies within their collections.
+type nftRarityCalculator struct {
+	// mgr represents the Manager instance
+	mgr *Manager
+
+	// sigStop represents the signal for closing the calculator
+	sigStop chan bool
+
+	// dirty is the set of collections scheduled for recalculation
+	dirtyMux sync.Mutex
+	dirty    map[common.Address]bool
+}
+
+// newNFTRarityCalculator creates a new instance of the NFT rarity calculator service.
+func newNFTRarityCalculator(mgr *Manager) *nftRarityCalculator {
+	return &nftRarityCalculator{
+		mgr:     mgr,
+		sigStop: make(chan bool, 1),
+		dirty:   make(map[common.Address]bool),
+	}
+}
+
+// name provides the name of the service.
+func (rc *nftRarityCalculator) name() string {
+	return "nft rarity calculator"
+}
+
+// init initializes the rarity calculator and registers it with the manager.
+func (rc *nftRarityCalculator) init() {
+	rc.mgr.add(rc)
+}
+
+// close signals the rarity calculator to terminate
+func (rc *nftRarityCalculator) close() {
+	rc.sigStop <- true
+}
+
+// schedule marks the given collection for rarity recalculation.
+func (rc *nftRarityCalculator) schedule(contract *common.Address) {
+	rc.dirtyMux.Lock()
+	defer rc.dirtyMux.Unlock()
+	rc.dirty[*contract] = true
+}
+
+// run recalculates rarity of the changed collections periodically.
+func (rc *nftRarityCalculator) run() {
+	tick := time.NewTicker(nftRarityTick)
+
+	defer func() {
+		tick.Stop()
+		rc.mgr.closed(rc)
+	}()
+
+	// the schedule is not persisted; collections with traits are recalculated after start
+	rc.seed()
+
+	for {
+		select {
+		case <-rc.sigStop:
+			return
+		case <-tick.C:
+			for _, adr := range rc.pull() {
+				rc.calculate(adr)
+			}
+		}
+	}
+}
+
+// seed schedules all the collections with traits for recalculation.
+func (rc *nftRarityCalculator) seed() {
+	list, err := repo.CollectionsWithTraits()
+	if err != nil {
+		log.Errorf("collections with traits not available; %s", err.Error())
+		return
+	}
+	for i := range list {
+		rc.schedule(&list[i])
+	}
+	log.Infof("%d collections scheduled for rarity calculation", len(list))
+}
+
+// pull takes the list of collections scheduled for recalculation and resets the schedule.
+func (rc *nftRarityCalculator) pull() []common.Address {
+	rc.dirtyMux.Lock()
+	defer rc.dirtyMux.Unlock()
+
+	list := make([]common.Address, 0, len(rc.dirty))
+	for adr := range rc.dirty {
+		list = append(list, adr)
+	}
+	rc.dirty = make(map[common.Address]bool)
+	return list
+}
+
+// calculate recalculates rarity of all the tokens of the given collection.
+func (rc *nftRarityCalculator) calculate(contract common.Address) {
+	tokens, err := repo.CollectionTokensTraits(&contract)
+	if err != nil {
+		log.Errorf("traits of %s not available; %s", contract.String(), err.Error())
+		return
+	}
+
+	list := types.ComputeRarity(tokens)
+	if err := repo.TokensUpdateRarity(list); err != nil {
+		log.Errorf("rarity of %s not updated; %s", contract.String(), err.Error())
+		return
+	}
+	log.Infof("rarity of %d tokens of %s updated", len(list), contract.String())
+}
@@ -0,0 +1,123 @@
+// Package svc implements monitoring and scanning services of the API server.
+package svc
+
+import (
+	"artion-api-graphql/internal/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"time"
+)
+
+// nftSupplyCheckTick is the tick used to pull NFT supply check candidates.
+const nftSupplyCheckTick = 10 * time.Minute
+
+// nftSupplyChecker represents a service responsible for periodic verification
+// of NFT tokens supply and holders count maintained from transfer events.
+type nftSupplyChecker struct {
+	// mgr represents the Manager instance
+	mgr *Manager
+
+	// sigStop represents the signal for closing the checker
+	sigStop chan bool
+}
+
+// newNFTSupplyChecker creates a new instance of the NFT supply checker service.
+func newNFTSupplyChecker(mgr *Manager) *nftSupplyChecker {
+	return &nftSupplyChecker{
+		mgr:     mgr,
+		sigStop: make(chan bool, 1),
+	}
+}
+
+// name provides the name of the service.
+func (sc *nftSupplyChecker) name() string {
+	return "nft supply checker"
+}
+
+// init initializes the supply checker and registers it with the manager.
+func (sc *nftSupplyChecker) init() {
+	sc.mgr.add(sc)
+}
+
+// close signals the supply checker to terminate
+func (sc *nftSupplyChecker) close() {
+	sc.sigStop <- true
+}
+
+// run checks supply of tokens scheduled for the check periodically.
+func (sc *nftSupplyChecker) run() {
+	checkTick := time.NewTicker(nftSupplyCheckTick)
+
+	defer func() {
+		checkTick.Stop()
+		sc.mgr.closed(sc)
+	}()
+
+	for {
+		select {
+		case <-sc.sigStop:
+			return
+		case <-checkTick.C:
+			sc.checkSupplySet()
+		}
+	}
+}
+
+// checkSupplySet pulls the set of tokens scheduled for supply check and verifies them on chain.
+func (sc *nftSupplyChecker) checkSupplySet() {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Errorf("could not check supply set; %v", p)
+		}
+	}()
+
+	rs, err := repo.TokenSupplyCheckSet()
+	if err != nil {
+		log.Errorf("supply check set not available; %s", err.Error())
+		return
+	}
+	log.Infof("loaded %d tokens in supply check set", len(rs))
+
+	nftTypes := repo.NFTContractsTypeMap()
+	for _, nft := range rs {
+		select {
+		case <-sc.sigStop:
+			// re-send the signal so the run loop terminates
+			sc.sigStop <- true
+			return
+		default:
+		}
+		sc.checkSupply(nft, nftTypes[nft.Contract])
+	}
+}
+
+// checkSupply verifies supply and holders count of the given token.
+func (sc *nftSupplyChecker) checkSupply(nft *types.Token, tt string) {
+	held, holders, err := repo.TokenOwnershipsSupply(&nft.Contract, &nft.TokenId)
+	if err != nil {
+		log.Errorf("holders of %s/%s not known; %s", nft.Contract.String(), nft.TokenId.String(), err.Error())
+		return
+	}
+
+	supply := nft.Supply.ToInt()
+	switch tt {
+	case types.ContractTypeERC721:
+		supply = big.NewInt(1)
+	case types.ContractTypeERC1155:
+		// not all ERC-1155 contracts provide the total supply; the sum of balances of owners is used if so
+		ts, err := repo.Erc1155TotalSupply(&nft.Contract, (*big.Int)(&nft.TokenId))
+		if err != nil {
+			log.Debugf("total supply of %s/%s not available; %s", nft.Contract.String(), nft.TokenId.String(), err.Error())
+			ts = held
+		}
+		if ts.Cmp(supply) != 0 {
+			log.Warningf("supply of %s/%s corrected from %s to %s",
+				nft.Contract.String(), nft.TokenId.String(), nft.Supply.String(), (*hexutil.Big)(ts).String())
+		}
+		supply = ts
+	}
+
+	if err := repo.TokenMarkSupplyChecked(&nft.Contract, (*big.Int)(&nft.TokenId), supply, holders); err != nil {
+		log.Errorf("could not update supply of %s/%s; %s", nft.Contract.String(), nft.TokenId.String(), err.Error())
+	}
+}
@@ -0,0 +1,88 @@
+package svc
+
+import "time"
+
+// uploadJanitorTick is the tick used to purge abandoned resumable uploads and token drafts.
+const uploadJanitorTick = 10 * time.Minute
+
+// uploadJanitor represents a service responsible for removing expired
+// partial resumable uploads from the upload storage and for unpinning
+// the content of token drafts never minted.
+type uploadJanitor struct {
+	// mgr represents the Manager instance
+	mgr *Manager
+
+	// sigStop represents the signal for closing the janitor
+	sigStop chan bool
+}
+
+// newUploadJanitor creates a new instance of the upload janitor service.
+func newUploadJanitor(mgr *Manager) *uploadJanitor {
+	return &uploadJanitor{
+		mgr:     mgr,
+		sigStop: make(chan bool, 1),
+	}
+}
+
+// name provides the name of the service.
+func (uj *uploadJanitor) name() string {
+	return "upload janitor"
+}
+
+// init initializes the upload janitor and registers it with the manager.
+func (uj *uploadJanitor) init() {
+	uj.mgr.add(uj)
+}
+
+// close signals the upload janitor to terminate
+func (uj *uploadJanitor) close() {
+	uj.sigStop <- true
+}
+
+// run purges expired uploads and token drafts periodically.
+func (uj *uploadJanitor) run() {
+	purgeTick := time.NewTicker(uploadJanitorTick)
+
+	defer func() {
+		purgeTick.Stop()
+		uj.mgr.closed(uj)
+	}()
+
+	for {
+		select {
+		case <-uj.sigStop:
+			return
+		case <-purgeTick.C:
+			uj.purge()
+			uj.purgeDrafts()
+		}
+	}
+}
+
+// purge removes the expired uploads.
+func (uj *uploadJanitor) purge() {
+	count, err := repo.PurgeExpiredUploadSessions()
+	if err != nil {
+		log.Errorf("could not purge expired uploads; %s", err.Error())
+		return
+	}
+	if count > 0 {
+		log.Infof("purged %d expired uploads", count)
+	}
+}
+
+// purgeDrafts removes the token drafts not minted in the configured time and unpins their content.
+func (uj *uploadJanitor) purgeDrafts() {
+	if cfg.Upload.DraftExpiry <= 0 {
+		return
+	}
+
+	count, err := repo.PurgeExpiredTokenDrafts(time.Now().Add(-cfg.Upload.DraftExpiry))
+	if err != nil {
+		log.Errorf("could not purge expired token drafts; %s", err.Error())
+		return
+	}
+	if count > 0 {
+		log.Infof("purged %d expired token drafts", count)
+	}
+}
@@ -22,11 +22,20 @@ const (
 	EvtAuctionCancelled
 	EvtAuctionResolved
 	EvtAuctionUpdated
+	EvtTokenMinted
+	EvtTokenTransferred
+	EvtTokenBurned
+	EvtMetadataChanged
 )
 
-// Activity represents marketplace related events on tokens - when they are sold etc.
+// Activity represents marketplace related events on tokens - when they are sold etc.,
+// and the provenance of tokens - when they are minted, transferred, or burned.
 type Activity struct {
 	OrdinalIndex int64           `bson:"index"`
+
+	// Seq orders activities sharing the ordinal index; activities of log records have zero,
+	// metadata changes are placed after the log records of their block by a positive sequence.
+	Seq          int64           `bson:"seq"`
 	Time         Time            `bson:"time"`
 	ActType      ActivityType    `bson:"type"`
 
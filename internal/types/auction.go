@@ -11,6 +11,7 @@ import (
 
 // Auction represents an auction being conducted on a given ERC-721 token.
 type Auction struct {
+	AuctionHall   common.Address  `bson:"auction"`
 	Contract      common.Address  `bson:"contract"`
 	TokenId       hexutil.Big     `bson:"token"`
 	Owner         common.Address  `bson:"owner"`
@@ -31,9 +32,10 @@ type Auction struct {
 	OrdinalIndex  int64           `bson:"index"`
 }
 
-// AuctionID generates unique auction ID for the given contract, token, and owner.
-func AuctionID(contract *common.Address, tokenID *big.Int) primitive.ObjectID {
+// AuctionID generates unique auction ID for the given auction contract, NFT contract, and token.
+func AuctionID(auctionHall *common.Address, contract *common.Address, tokenID *big.Int) primitive.ObjectID {
 	hash := sha256.New()
+	hash.Write(auctionHall.Bytes())
 	hash.Write(contract.Bytes())
 	hash.Write(tokenID.Bytes())
 
@@ -44,5 +46,5 @@ func AuctionID(contract *common.Address, tokenID *big.Int) primitive.ObjectID {
 
 // ID generates a unique identifier of the Artion auction.
 func (au *Auction) ID() primitive.ObjectID {
-	return AuctionID(&au.Contract, (*big.Int)(&au.TokenId))
+	return AuctionID(&au.AuctionHall, &au.Contract, (*big.Int)(&au.TokenId))
 }
@@ -4,6 +4,10 @@ type Event struct {
 	Type string
 	Auction *Auction
 	Offer *Offer
+
+	// Token and Message describe the outcome of an on-demand token metadata refresh
+	Token   *Token
+	Message string
 }
 
 type EventListener struct {
@@ -1,11 +1,32 @@
 package types
 
-import "strings"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
 
 // Image represents image of NFT downloaded from specified URI
 type Image struct {
 	Data []byte
 	Type ImageType
+
+	// Modified is the time the image was last modified, zero if not known
+	Modified time.Time
+
+	// ETag identifies the content of the image, empty if not known yet
+	ETag string
+}
+
+// ContentTag provides the identifier of the image content used as HTTP entity tag;
+// the stored tag is used if known, so the data do not need to be hashed for each request.
+func (i *Image) ContentTag() string {
+	if i.ETag == "" {
+		hash := sha256.Sum256(i.Data)
+		i.ETag = hex.EncodeToString(hash[:16])
+	}
+	return i.ETag
 }
 
 type ImageType int8
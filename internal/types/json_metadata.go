@@ -1,6 +1,15 @@
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidJsonMetadata is returned if the downloaded NFT metadata document can not be decoded.
+var ErrInvalidJsonMetadata = errors.New("invalid metadata json")
 
 // JsonMetadata describes a token as defined in ERC-721/ERC-1155 Metadata JSON Schema.
 type JsonMetadata struct {
@@ -9,6 +18,94 @@ type JsonMetadata struct {
 	Image       *string `json:"image"`       // A URI pointing to a resource with mime type image/* representing the asset to which this token represents.
 	Decimals    *int    `json:"decimals"`    // ERC-1155 only: The number of decimal places that the token amount should display
 	Properties  JsonMetadataProps `json:"properties"`
+	Attributes  JsonMetadataAttributes `json:"attributes"` // OpenSea style traits of the asset
+
+	// OpenSea style rich media and presentation of the asset
+	AnimationUrl    *string `json:"animation_url"`    // A URI of a multi-media attachment of the item (video, audio, 3D model, HTML page)
+	ExternalUrl     *string `json:"external_url"`     // A URL of the item on the creator site
+	BackgroundColor *string `json:"background_color"` // Background color of the item as six-character hexadecimal without a pre-pended #
+}
+
+// JsonMetadataAttribute represents a single trait of the asset.
+// The value can be a string, a number, or a boolean.
+type JsonMetadataAttribute struct {
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	DisplayType *string     `json:"display_type"`
+}
+
+// JsonMetadataAttributes represents the list of traits of the asset.
+type JsonMetadataAttributes []JsonMetadataAttribute
+
+// UnmarshalJSON decodes the list of traits. Malformed attributes are common
+// in the wild and they should not fail decoding of the whole metadata.
+func (ja *JsonMetadataAttributes) UnmarshalJSON(data []byte) error {
+	var list []JsonMetadataAttribute
+	if err := json.Unmarshal(data, &list); err != nil {
+		*ja = nil
+		return nil
+	}
+	*ja = list
+	return nil
+}
+
+// ExternalLink provides the external URL of the item; empty if not set or not an absolute HTTP(S) URL.
+func (jm *JsonMetadata) ExternalLink() string {
+	if jm.ExternalUrl == nil {
+		return ""
+	}
+	s := strings.TrimSpace(*jm.ExternalUrl)
+	if !isHttpUrl(s) {
+		return ""
+	}
+	return s
+}
+
+// BgColor provides the background color of the item as six lower case hexadecimal digits;
+// empty if not set or invalid. The leading # is tolerated in downloaded metadata.
+func (jm *JsonMetadata) BgColor() string {
+	if jm.BackgroundColor == nil {
+		return ""
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(*jm.BackgroundColor), "#")
+	if !jsonMetadataColorPattern.MatchString(s) {
+		return ""
+	}
+	return strings.ToLower(s)
+}
+
+// TokenAttributes converts the metadata traits into token attributes.
+// Traits without a value are skipped, values are stored as strings.
+// A repeated trait type keeps its first value only.
+func (jm *JsonMetadata) TokenAttributes() []TokenAttribute {
+	list := make([]TokenAttribute, 0, len(jm.Attributes))
+	seen := make(map[string]bool, len(jm.Attributes))
+	for _, at := range jm.Attributes {
+		var value string
+		switch v := at.Value.(type) {
+		case string:
+			value = strings.TrimSpace(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			continue
+		}
+
+		trait := strings.TrimSpace(at.TraitType)
+		if seen[trait] {
+			continue
+		}
+		seen[trait] = true
+
+		list = append(list, TokenAttribute{
+			TraitType:   trait,
+			Value:       value,
+			DisplayType: at.DisplayType,
+		})
+	}
+	return list
 }
 
 type JsonMetadataProps struct {
@@ -26,7 +123,7 @@ func DecodeJsonMetadata(data []byte) (*JsonMetadata, error) {
 	var out JsonMetadata
 	err := json.Unmarshal(data, &out)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w; %s", ErrInvalidJsonMetadata, err.Error())
 	}
 	return &out, nil
 }
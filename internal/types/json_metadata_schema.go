@@ -0,0 +1,203 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// limits of the metadata JSON documents submitted by users
+const (
+	jsonMetadataMaxNameLength        = 200
+	jsonMetadataMaxDescriptionLength = 10000
+	jsonMetadataMaxAttributes        = 200
+	jsonMetadataMaxTraitLength       = 200
+	jsonMetadataMaxDecimals          = 18
+)
+
+// jsonMetadataColorPattern is the expected format of the background color - six hex digits without the "#".
+var jsonMetadataColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// jsonMetadataDisplayTypes lists the display types of traits known to marketplaces;
+// all of them except the "date" are numeric, "date" expects a unix timestamp.
+var jsonMetadataDisplayTypes = map[string]bool{
+	"number":           true,
+	"boost_number":     true,
+	"boost_percentage": true,
+	"date":             true,
+}
+
+// MetadataFieldError represents a violation of the metadata JSON schema by a field of the document.
+type MetadataFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidateJsonMetadata checks the NFT token metadata JSON document against the ERC-721/ERC-1155
+// metadata JSON schema extended by OpenSea style fields. Unknown fields are allowed.
+// The list of all the violations found is returned; an empty list means the document is valid.
+func ValidateJsonMetadata(data []byte) []MetadataFieldError {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil || doc == nil {
+		return []MetadataFieldError{{Field: "", Message: "the document must be a JSON object"}}
+	}
+
+	v := jsonMetadataValidator{}
+	v.text(doc, "", "name", jsonMetadataMaxNameLength, true)
+	v.text(doc, "", "description", jsonMetadataMaxDescriptionLength, false)
+	v.text(doc, "", "image", 0, false)
+	v.text(doc, "", "animation_url", 0, false)
+	v.externalUrl(doc)
+	v.backgroundColor(doc)
+	v.decimals(doc)
+	v.attributes(doc)
+	v.properties(doc)
+	return v.errors
+}
+
+// jsonMetadataValidator collects violations of the metadata JSON schema.
+type jsonMetadataValidator struct {
+	errors []MetadataFieldError
+}
+
+// fail records a violation on the given field.
+func (v *jsonMetadataValidator) fail(field string, format string, args ...interface{}) {
+	v.errors = append(v.errors, MetadataFieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// text checks the field is a string of up to the given number of characters; zero length means no limit.
+// The prefix identifies the object containing the field in errors. The value is returned if it's a valid string.
+func (v *jsonMetadataValidator) text(doc map[string]interface{}, prefix string, key string, length int, required bool) (string, bool) {
+	field := prefix + key
+	value, ok := doc[key]
+	if !ok || value == nil {
+		if required {
+			v.fail(field, "the field is required")
+		}
+		return "", false
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		v.fail(field, "the field must be a string")
+		return "", false
+	}
+	if required && strings.TrimSpace(s) == "" {
+		v.fail(field, "the field must not be empty")
+		return "", false
+	}
+	if length > 0 && utf8.RuneCountInString(s) > length {
+		v.fail(field, "the field must not be longer than %d characters", length)
+		return "", false
+	}
+	return s, true
+}
+
+// externalUrl checks the external URL is an absolute HTTP(S) URL.
+func (v *jsonMetadataValidator) externalUrl(doc map[string]interface{}) {
+	s, ok := v.text(doc, "", "external_url", 0, false)
+	if !ok || s == "" {
+		return
+	}
+	if !isHttpUrl(s) {
+		v.fail("external_url", "the field must be an absolute http or https URL")
+	}
+}
+
+// isHttpUrl checks the given string is an absolute HTTP(S) URL.
+func isHttpUrl(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// backgroundColor checks the background color is six hex digits.
+func (v *jsonMetadataValidator) backgroundColor(doc map[string]interface{}) {
+	s, ok := v.text(doc, "", "background_color", 0, false)
+	if ok && s != "" && !jsonMetadataColorPattern.MatchString(s) {
+		v.fail("background_color", "the field must be six hexadecimal digits without a leading #")
+	}
+}
+
+// decimals checks the number of decimals is a small non-negative integer.
+func (v *jsonMetadataValidator) decimals(doc map[string]interface{}) {
+	value, ok := doc["decimals"]
+	if !ok || value == nil {
+		return
+	}
+	n, ok := value.(float64)
+	if !ok || n != math.Trunc(n) || n < 0 || n > jsonMetadataMaxDecimals {
+		v.fail("decimals", "the field must be an integer between 0 and %d", jsonMetadataMaxDecimals)
+	}
+}
+
+// attributes checks the list of traits.
+func (v *jsonMetadataValidator) attributes(doc map[string]interface{}) {
+	value, ok := doc["attributes"]
+	if !ok || value == nil {
+		return
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		v.fail("attributes", "the field must be an array")
+		return
+	}
+	if len(list) > jsonMetadataMaxAttributes {
+		v.fail("attributes", "the field must not have more than %d items", jsonMetadataMaxAttributes)
+		return
+	}
+
+	for i, item := range list {
+		field := fmt.Sprintf("attributes[%d]", i)
+		at, ok := item.(map[string]interface{})
+		if !ok {
+			v.fail(field, "the item must be an object")
+			continue
+		}
+
+		v.text(at, field+".", "trait_type", jsonMetadataMaxTraitLength, false)
+
+		display, _ := at["display_type"].(string)
+		if at["display_type"] != nil && !jsonMetadataDisplayTypes[display] {
+			v.fail(field+".display_type", "unknown display type")
+		}
+
+		switch val := at["value"].(type) {
+		case string:
+			if display != "" {
+				v.fail(field+".value", "the value must be a number for display type %s", display)
+			} else if utf8.RuneCountInString(val) > jsonMetadataMaxTraitLength {
+				v.fail(field+".value", "the value must not be longer than %d characters", jsonMetadataMaxTraitLength)
+			}
+		case float64:
+		case bool:
+			if display != "" {
+				v.fail(field+".value", "the value must be a number for display type %s", display)
+			}
+		case nil:
+			v.fail(field+".value", "the value is required")
+		default:
+			v.fail(field+".value", "the value must be a string, a number or a boolean")
+		}
+	}
+}
+
+// properties checks the Artion specific properties of the token.
+func (v *jsonMetadataValidator) properties(doc map[string]interface{}) {
+	value, ok := doc["properties"]
+	if !ok || value == nil {
+		return
+	}
+	props, ok := value.(map[string]interface{})
+	if !ok {
+		v.fail("properties", "the field must be an object")
+		return
+	}
+
+	for _, key := range []string{"symbol", "address", "royalty", "recipient", "IP_Rights", "createdAt", "collection"} {
+		v.text(props, "properties.", key, 0, false)
+	}
+}
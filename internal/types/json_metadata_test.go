@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"github.com/onsi/gomega"
 	"testing"
 )
@@ -31,3 +32,71 @@ func TestJsonDecode(t *testing.T) {
 	g.Expect(meta.Name).To(gomega.Equal("tst"))
 	g.Expect(*meta.Properties.Collection).To(gomega.Equal("Collection"))
 }
+
+func TestJsonDecodeAttributes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	data := "{\"name\":\"tst\",\"attributes\":[{\"trait_type\":\"Background\",\"value\":\"Blue \"},{\"trait_type\":\"Level\",\"value\":5,\"display_type\":\"number\"},{\"trait_type\":\"Shiny\",\"value\":true},{\"trait_type\":\"Empty\"}]}"
+	meta, err := DecodeJsonMetadata([]byte(data))
+	g.Expect(err).To(gomega.BeNil())
+
+	attrs := meta.TokenAttributes()
+	g.Expect(attrs).To(gomega.HaveLen(3))
+	g.Expect(attrs[0].TraitType).To(gomega.Equal("Background"))
+	g.Expect(attrs[0].Value).To(gomega.Equal("Blue"))
+	g.Expect(attrs[1].Value).To(gomega.Equal("5"))
+	g.Expect(*attrs[1].DisplayType).To(gomega.Equal("number"))
+	g.Expect(attrs[2].Value).To(gomega.Equal("true"))
+
+	// malformed attributes do not break the metadata
+	data = "{\"name\":\"tst\",\"attributes\":{\"Background\":\"Blue\"}}"
+	meta, err = DecodeJsonMetadata([]byte(data))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(meta.Name).To(gomega.Equal("tst"))
+	g.Expect(meta.TokenAttributes()).To(gomega.BeEmpty())
+}
+
+func TestJsonMetadataSchema(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	valid := `{"name":"tst","description":"aaa","external_url":"https://example.com/1","background_color":"00ff00",
+		"attributes":[{"trait_type":"Eyes","value":"Blue"},{"trait_type":"Level","value":5,"display_type":"number"}],
+		"properties":{"symbol":"TST","royalty":"2.5"}}`
+	g.Expect(ValidateJsonMetadata([]byte(valid))).To(gomega.BeEmpty())
+
+	g.Expect(ValidateJsonMetadata([]byte(`[1,2]`))).To(gomega.HaveLen(1))
+
+	fields := func(errs []MetadataFieldError) []string {
+		out := make([]string, len(errs))
+		for i, e := range errs {
+			out[i] = e.Field
+		}
+		return out
+	}
+
+	invalid := `{"name":" ","description":7,"external_url":"javascript:alert(1)","background_color":"#00ff00","decimals":1.5,
+		"attributes":[{"trait_type":"Level","value":"high","display_type":"number"},{"trait_type":"Eyes"},"x"],
+		"properties":{"royalty":2.5}}`
+	g.Expect(fields(ValidateJsonMetadata([]byte(invalid)))).To(gomega.ConsistOf(
+		"name", "description", "external_url", "background_color", "decimals",
+		"attributes[0].value", "attributes[1].value", "attributes[2]", "properties.royalty",
+	))
+}
+
+func TestJsonMetadataLinkAndColor(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var meta JsonMetadata
+	g.Expect(json.Unmarshal([]byte(`{"name":"tst","external_url":" https://example.com/1 ","background_color":"#00FF00"}`), &meta)).To(gomega.BeNil())
+	g.Expect(meta.ExternalLink()).To(gomega.Equal("https://example.com/1"))
+	g.Expect(meta.BgColor()).To(gomega.Equal("00ff00"))
+
+	meta = JsonMetadata{}
+	g.Expect(json.Unmarshal([]byte(`{"name":"tst","external_url":"javascript:alert(1)","background_color":"red"}`), &meta)).To(gomega.BeNil())
+	g.Expect(meta.ExternalLink()).To(gomega.BeEmpty())
+	g.Expect(meta.BgColor()).To(gomega.BeEmpty())
+
+	meta = JsonMetadata{}
+	g.Expect(meta.ExternalLink()).To(gomega.BeEmpty())
+	g.Expect(meta.BgColor()).To(gomega.BeEmpty())
+}
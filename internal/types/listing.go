@@ -11,6 +11,7 @@ import (
 
 // Listing represents offer for anybody to buy given token from the owner.
 type Listing struct {
+	Marketplace  common.Address `bson:"market"`
 	Owner        common.Address `bson:"owner"`
 	Contract     common.Address `bson:"contract"`
 	TokenId      hexutil.Big    `bson:"token"`
@@ -24,9 +25,10 @@ type Listing struct {
 	OrdinalIndex int64          `bson:"index"`
 }
 
-// ListingID generates unique listing ID for the given contract, token, and owner.
-func ListingID(contract *common.Address, tokenID *big.Int, owner *common.Address) primitive.ObjectID {
+// ListingID generates unique listing ID for the given marketplace, contract, token, and owner.
+func ListingID(marketplace *common.Address, contract *common.Address, tokenID *big.Int, owner *common.Address) primitive.ObjectID {
 	hash := sha256.New()
+	hash.Write(marketplace.Bytes())
 	hash.Write(contract.Bytes())
 	hash.Write(tokenID.Bytes())
 	hash.Write(owner.Bytes())
@@ -38,5 +40,5 @@ func ListingID(contract *common.Address, tokenID *big.Int, owner *common.Address
 
 // ID generates a unique identifier of the Artion Marketplace listing.
 func (l *Listing) ID() primitive.ObjectID {
-	return ListingID(&l.Contract, (*big.Int)(&l.TokenId), &l.Owner)
+	return ListingID(&l.Marketplace, &l.Contract, (*big.Int)(&l.TokenId), &l.Owner)
 }
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// MediaKind represents the kind of rich media of an NFT, i.e. the content of its animation URL.
+type MediaKind int8
+
+const (
+	MediaKindUnknown MediaKind = iota
+	MediaKindImage
+	MediaKindVideo
+	MediaKindAudio
+	MediaKindModel
+	MediaKindHtml
+)
+
+// MediaInfo represents properties of a media file detected by decoding it.
+// Dimensions are zero for audio, the duration is zero for still images.
+type MediaInfo struct {
+	Width    int
+	Height   int
+	Duration time.Duration
+}
+
+// mediaMimetypeByExtension maps known file extensions of rich media to their mimetype.
+var mediaMimetypeByExtension = map[string]string{
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".webm": "video/webm",
+	".mov":  "video/quicktime",
+	".ogv":  "video/ogg",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".oga":  "audio/ogg",
+	".ogg":  "audio/ogg",
+	".flac": "audio/flac",
+	".m4a":  "audio/mp4",
+	".glb":  "model/gltf-binary",
+	".gltf": "model/gltf+json",
+	".html": "text/html",
+	".htm":  "text/html",
+}
+
+// String provides the GraphQL name of the media kind.
+func (mk MediaKind) String() string {
+	switch mk {
+	case MediaKindImage:
+		return "IMAGE"
+	case MediaKindVideo:
+		return "VIDEO"
+	case MediaKindAudio:
+		return "AUDIO"
+	case MediaKindModel:
+		return "MODEL"
+	case MediaKindHtml:
+		return "HTML"
+	}
+	return "UNKNOWN"
+}
+
+// MediaKindFromMimetype detects the kind of media from its mimetype.
+func MediaKindFromMimetype(mimetype string) MediaKind {
+	mimetype = strings.ToLower(strings.TrimSpace(strings.Split(mimetype, ";")[0]))
+	switch {
+	case strings.HasPrefix(mimetype, "image/"):
+		return MediaKindImage
+	case strings.HasPrefix(mimetype, "video/"):
+		return MediaKindVideo
+	case strings.HasPrefix(mimetype, "audio/"):
+		return MediaKindAudio
+	case strings.HasPrefix(mimetype, "model/"):
+		return MediaKindModel
+	case mimetype == "text/html" || mimetype == "application/xhtml+xml":
+		return MediaKindHtml
+	}
+	return MediaKindUnknown
+}
+
+// MimetypeFromExtension detects mimetype of a media from the file extension in the URI.
+// Empty string is returned if the extension is not known.
+func MimetypeFromExtension(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Path != "" {
+		uri = u.Path
+	}
+
+	ext := strings.ToLower(path.Ext(uri))
+	if mt, ok := mediaMimetypeByExtension[ext]; ok {
+		return mt
+	}
+	return ImageTypeFromExtension(ext).Mimetype()
+}
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"github.com/onsi/gomega"
+	"testing"
+)
+
+func TestMediaKind(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(MimetypeFromExtension("ipfs://QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi/model.GLB")).To(gomega.Equal("model/gltf-binary"))
+	g.Expect(MimetypeFromExtension("https://example.org/clip.webm?v=2")).To(gomega.Equal("video/webm"))
+	g.Expect(MimetypeFromExtension("https://example.org/image.png")).To(gomega.Equal("image/png"))
+	g.Expect(MimetypeFromExtension("https://example.org/metadata")).To(gomega.Equal(""))
+
+	g.Expect(MediaKindFromMimetype("video/mp4")).To(gomega.Equal(MediaKindVideo))
+	g.Expect(MediaKindFromMimetype("audio/mpeg")).To(gomega.Equal(MediaKindAudio))
+	g.Expect(MediaKindFromMimetype("model/gltf+json")).To(gomega.Equal(MediaKindModel))
+	g.Expect(MediaKindFromMimetype("text/html; charset=utf-8")).To(gomega.Equal(MediaKindHtml))
+	g.Expect(MediaKindFromMimetype("application/octet-stream")).To(gomega.Equal(MediaKindUnknown))
+}
@@ -11,15 +11,32 @@ const (
 
 	// ContractTypeERC1155 represents an NFT contract type ERC-1155
 	ContractTypeERC1155 = "erc1155"
+
+	// ContractTypeMarketplace represents the Artion Marketplace contract type
+	ContractTypeMarketplace = "market"
+
+	// ContractTypeAuction represents the Artion Auction contract type
+	ContractTypeAuction = "auction"
+)
+
+const (
+	// AuctionVersionV1 represents the Auction contract deployed with the first version of the ABI
+	AuctionVersionV1 = 1
+
+	// AuctionVersionV2 represents the Auction contract deployed with the current version of the ABI
+	AuctionVersionV2 = 2
 )
 
 // ObservedContract represents a contract observed by the API server.
+// Several contracts of the same type may be observed at once; the Version
+// distinguishes ABI revisions of the contract deployed at the address.
 type ObservedContract struct {
-	Address     common.Address  `bson:"_id"`
-	Name        string          `bson:"name"`
-	Type        string          `bson:"type"`
-	Created     Time            `bson:"created"`
-	Creator     common.Address  `bson:"creator"`
-	BlockNumber uint64          `bson:"block"`
-	DeployedBy  common.Hash     `bson:"trx"`
+	Address     common.Address `bson:"_id"`
+	Name        string         `bson:"name"`
+	Type        string         `bson:"type"`
+	Version     int            `bson:"ver"`
+	Created     Time           `bson:"created"`
+	Creator     common.Address `bson:"creator"`
+	BlockNumber uint64         `bson:"block"`
+	DeployedBy  common.Hash    `bson:"trx"`
 }
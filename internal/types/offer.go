@@ -11,6 +11,7 @@ import (
 
 // Offer represents offer to buy given token from any current owner.
 type Offer struct {
+	Marketplace  common.Address `bson:"market"`
 	Contract     common.Address `bson:"contract"`
 	TokenId      hexutil.Big    `bson:"token"`
 	ProposedBy   common.Address `bson:"proposer"`
@@ -23,9 +24,10 @@ type Offer struct {
 	OrdinalIndex int64          `bson:"index"`
 }
 
-// OfferID generates unique offer ID for the given contract, token, and owner.
-func OfferID(contract *common.Address, tokenID *big.Int, offeredBy *common.Address) primitive.ObjectID {
+// OfferID generates unique offer ID for the given marketplace, contract, token, and owner.
+func OfferID(marketplace *common.Address, contract *common.Address, tokenID *big.Int, offeredBy *common.Address) primitive.ObjectID {
 	hash := sha256.New()
+	hash.Write(marketplace.Bytes())
 	hash.Write(contract.Bytes())
 	hash.Write(tokenID.Bytes())
 	hash.Write(offeredBy.Bytes())
@@ -37,5 +39,5 @@ func OfferID(contract *common.Address, tokenID *big.Int, offeredBy *common.Addre
 
 // ID generates a unique identifier of the Artion Marketplace direct offer.
 func (o *Offer) ID() primitive.ObjectID {
-	return OfferID(&o.Contract, (*big.Int)(&o.TokenId), &o.ProposedBy)
+	return OfferID(&o.Marketplace, &o.Contract, (*big.Int)(&o.TokenId), &o.ProposedBy)
 }
@@ -0,0 +1,85 @@
+// Package types provides high level structures for the API server.
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// HoldersSnapshotMaxBalances is the max number of token balances collected for a holders snapshot
+// requested over the API; bigger collections are exported from the command line.
+const HoldersSnapshotMaxBalances = 100000
+
+// ErrHoldersSnapshotTooLarge is returned if the holders snapshot exceeds the requested max number of token balances.
+var ErrHoldersSnapshotTooLarge = errors.New("holders snapshot too large")
+
+// OwnershipChange represents a change of NFT balance of an owner caused by a transfer event.
+// The history of changes allows us to rebuild ownership at any block in the past.
+type OwnershipChange struct {
+	Contract common.Address `bson:"contract"`
+	TokenId  hexutil.Big    `bson:"token"`
+	Owner    common.Address `bson:"owner"`
+	Qty      hexutil.Big    `bson:"qty"` // balance of the owner after the change
+	Block    uint64         `bson:"block"`
+	Updated  Time           `bson:"updated"`
+
+	// OrdinalIndex is the ordinal index of the transfer event log record.
+	OrdinalIndex int64 `bson:"index"`
+}
+
+// ID generates unique identifier for the NFT ownership change record.
+func (oc *OwnershipChange) ID() primitive.ObjectID {
+	hash := sha256.New()
+	hash.Write(oc.Contract.Bytes())
+	hash.Write(oc.TokenId.ToInt().Bytes())
+	hash.Write(oc.Owner.Bytes())
+	hash.Write(big.NewInt(oc.OrdinalIndex).Bytes())
+
+	var id [12]byte
+	copy(id[:], hash.Sum(nil))
+	return id
+}
+
+// HolderBalance represents the amount of NFT tokens of a collection held by an owner.
+type HolderBalance struct {
+	Owner  common.Address
+	Qty    hexutil.Big
+	Tokens int32
+}
+
+// HoldersSnapshot represents holders of NFT tokens of a collection at the given block.
+// The ownership history is recorded from transfers observed by the API server and seeded once
+// with balances held at that time, which are attributed to the mint of the token.
+type HoldersSnapshot struct {
+	Contract common.Address
+	Block    uint64
+	Holders  []*HolderBalance
+}
+
+// WriteCSV exports the snapshot as CSV with owner address, decimal balance and number of tokens held.
+func (hs *HoldersSnapshot) WriteCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"owner", "balance", "tokens"}); err != nil {
+		return err
+	}
+
+	for _, h := range hs.Holders {
+		if err := w.Write([]string{
+			h.Owner.String(),
+			h.Qty.ToInt().String(),
+			strconv.FormatInt(int64(h.Tokens), 10),
+		}); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,84 @@
+package types
+
+import "sort"
+
+// TokenRarity represents the statistical rarity of an NFT token within its collection.
+type TokenRarity struct {
+	Token *Token
+	Score float64
+	Rank  int32
+}
+
+// ComputeRarity calculates rarity scores and ranks of the given tokens of a single collection.
+// The score is the sum of inverted frequencies of the token trait values; a token missing
+// a trait known in the collection is scored for the absence of the trait, so the rarely
+// missing traits count as well. The rarest token is ranked #1, equal scores share the rank.
+func ComputeRarity(tokens []*Token) []*TokenRarity {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// count the values of all the traits; the empty value stands for a missing trait
+	// a trait repeated in a token counts once with its first value
+	traits := make([]map[string]string, len(tokens))
+	counts := make(map[string]map[string]int)
+	for i, t := range tokens {
+		traits[i] = tokenTraits(t)
+		for trait, value := range traits[i] {
+			if _, ok := counts[trait]; !ok {
+				counts[trait] = make(map[string]int)
+			}
+			counts[trait][value]++
+		}
+	}
+	for trait, values := range counts {
+		var present int
+		for _, c := range values {
+			present += c
+		}
+		if present < len(tokens) {
+			counts[trait][""] += len(tokens) - present
+		}
+	}
+
+	// score the tokens
+	total := float64(len(tokens))
+	list := make([]*TokenRarity, len(tokens))
+	for i, t := range tokens {
+		var score float64
+		for trait, vc := range counts {
+			// the count can not be zero, the guard keeps the score finite anyway
+			if c := vc[traits[i][trait]]; c > 0 {
+				score += total / float64(c)
+			}
+		}
+		list[i] = &TokenRarity{Token: t, Score: score}
+	}
+
+	// rank the tokens; the older token goes first on equal score to keep the order stable
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].Token.OrdinalIndex < list[j].Token.OrdinalIndex
+	})
+	for i, r := range list {
+		if i > 0 && r.Score == list[i-1].Score {
+			r.Rank = list[i-1].Rank
+		} else {
+			r.Rank = int32(i + 1)
+		}
+	}
+	return list
+}
+
+// tokenTraits provides the values of the token traits by the trait type; the first value of a repeated trait is used.
+func tokenTraits(t *Token) map[string]string {
+	values := make(map[string]string, len(t.Attributes))
+	for _, at := range t.Attributes {
+		if _, ok := values[at.TraitType]; !ok {
+			values[at.TraitType] = at.Value
+		}
+	}
+	return values
+}
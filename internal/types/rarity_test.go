@@ -0,0 +1,51 @@
+package types
+
+import (
+	"github.com/onsi/gomega"
+	"math"
+	"testing"
+)
+
+func TestComputeRarity(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	tokens := []*Token{
+		{OrdinalIndex: 1, Attributes: []TokenAttribute{{TraitType: "Background", Value: "Blue"}}},
+		{OrdinalIndex: 2, Attributes: []TokenAttribute{{TraitType: "Background", Value: "Blue"}}},
+		{OrdinalIndex: 3, Attributes: []TokenAttribute{{TraitType: "Background", Value: "Red"}, {TraitType: "Hat", Value: "Crown"}}},
+		{OrdinalIndex: 4, Attributes: []TokenAttribute{{TraitType: "Background", Value: "Blue"}}},
+	}
+
+	list := ComputeRarity(tokens)
+	g.Expect(list).To(gomega.HaveLen(4))
+
+	// the red background with a crown is the rarest
+	g.Expect(list[0].Token.OrdinalIndex).To(gomega.Equal(int64(3)))
+	g.Expect(list[0].Rank).To(gomega.Equal(int32(1)))
+	g.Expect(list[0].Score).To(gomega.BeNumerically("~", 4.0/1+4.0/1))
+
+	// the rest share the same score and rank
+	for _, r := range list[1:] {
+		g.Expect(r.Rank).To(gomega.Equal(int32(2)))
+		g.Expect(r.Score).To(gomega.BeNumerically("~", 4.0/3+4.0/3))
+	}
+
+	g.Expect(ComputeRarity(nil)).To(gomega.BeEmpty())
+}
+
+func TestComputeRarityRepeatedTrait(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	tokens := []*Token{
+		{OrdinalIndex: 1, Attributes: []TokenAttribute{{TraitType: "Hat", Value: "Crown"}, {TraitType: "Hat", Value: "Cap"}}},
+		{OrdinalIndex: 2, Attributes: []TokenAttribute{{TraitType: "Hat", Value: "Cap"}}},
+		{OrdinalIndex: 3, Attributes: []TokenAttribute{}},
+	}
+
+	list := ComputeRarity(tokens)
+	g.Expect(list).To(gomega.HaveLen(3))
+	for _, r := range list {
+		g.Expect(math.IsInf(r.Score, 0)).To(gomega.BeFalse())
+		g.Expect(r.Score).To(gomega.BeNumerically("~", 3.0))
+	}
+}
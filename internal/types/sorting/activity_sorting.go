@@ -9,15 +9,17 @@ const (
 )
 
 func (ts ActivitySorting) SortedFieldBson() string {
-	return ""
+	return "index"
 }
 
+// OrdinalFieldBson provides the sequence of activities sharing the ordinal index (see types.Activity.Seq).
 func (ts ActivitySorting) OrdinalFieldBson() string {
-	return "index"
+	return "seq"
 }
 
 func (ts ActivitySorting) GetCursor(activity *types.Activity) (types.Cursor, error) {
 	params := make(map[string]interface{})
 	params["index"] = activity.OrdinalIndex
+	params["seq"] = activity.Seq
 	return CursorFromParams(params)
 }
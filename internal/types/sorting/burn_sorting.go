@@ -0,0 +1,23 @@
+package sorting
+
+import "artion-api-graphql/internal/types"
+
+type BurnSorting int8
+
+const (
+	BurnSortingNone BurnSorting = iota
+)
+
+func (ts BurnSorting) SortedFieldBson() string {
+	return ""
+}
+
+func (ts BurnSorting) OrdinalFieldBson() string {
+	return "index"
+}
+
+func (ts BurnSorting) GetCursor(burn *types.NFTBurn) (types.Cursor, error) {
+	params := make(map[string]interface{})
+	params["index"] = burn.OrdinalIndex
+	return CursorFromParams(params)
+}
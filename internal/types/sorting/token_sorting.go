@@ -12,6 +12,7 @@ const (
 	TokenSortingAuctionUntil    // Ending Soon
 	TokenSortingPrice           // Most Expensive / Cheapest
 	TokenSortingLastTradeAmount // Highest Last Sale
+	TokenSortingRarity          // Rarest / Most Common
 	// Mostly Viewed
 )
 
@@ -24,6 +25,7 @@ func (ts TokenSorting) SortedFieldBson() string {
 	case TokenSortingAuctionUntil: return "auction_until"
 	case TokenSortingPrice: return "price"
 	case TokenSortingLastTradeAmount: return "amo_trade"
+	case TokenSortingRarity: return "rarity"
 	}
 	return ""
 }
@@ -53,5 +55,8 @@ func (ts TokenSorting) GetCursor(token *types.Token) (types.Cursor, error) {
 	if ts == TokenSortingLastTradeAmount {
 		params["amo_trade"] = token.AmountLastTrade
 	}
+	if ts == TokenSortingRarity {
+		params["rarity"] = token.RarityScore
+	}
 	return CursorFromParams(params)
 }
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultThumbnailSize is the size of the image thumbnail served if no size is requested.
+const DefaultThumbnailSize = 500
+
+// ThumbnailSizes is the list of the thumbnail sizes we generate; other sizes are refused
+// so the store is not flooded by arbitrary variants.
+var ThumbnailSizes = []int{64, 256, 500, 1000}
+
+// thumbnailKeyVersion is the version of the derived media store keys. It's bumped whenever
+// the way variants are generated changes, so the variants generated before are not served anymore;
+// i.e. version 2 rasterizes SVG sources of raster variants, version 1 kept the raw SVG.
+const thumbnailKeyVersion = 2
+
+// ThumbnailPendingTimeout is the time after which a thumbnail still pending is considered lost
+// (i.e. by a restart of the server) and its generation is retried.
+const ThumbnailPendingTimeout = time.Hour
+
+// ThumbnailStatus represents the state of the pre-generated thumbnail of a token image.
+type ThumbnailStatus int8
+
+const (
+	// ThumbnailNone means the thumbnail has not been requested yet.
+	ThumbnailNone ThumbnailStatus = iota
+
+	// ThumbnailPending means the thumbnail is queued for generation.
+	ThumbnailPending
+
+	// ThumbnailReady means the thumbnail exists in the derived media store.
+	ThumbnailReady
+
+	// ThumbnailFailed means the thumbnail could not be generated;
+	// it will be retried on the next metadata refresh.
+	ThumbnailFailed
+)
+
+// ThumbnailVariant identifies a variant of an image thumbnail derived from a source image.
+type ThumbnailVariant struct {
+	// Size is the max width and height of the thumbnail in pixels
+	Size int
+
+	// Format is the output format of the thumbnail;
+	// ImageTypeUnknown keeps JPEG sources in JPEG, animated GIFs in GIF and encodes anything else into PNG;
+	// ImageTypeMp4 is a short preview clip of a video
+	Format ImageType
+}
+
+// DefaultPreviewSize is the max width and height of video preview clips.
+const DefaultPreviewSize = 320
+
+// PreviewVariant is the variant of short looping low-bitrate preview clips of videos.
+var PreviewVariant = ThumbnailVariant{Size: DefaultPreviewSize, Format: ImageTypeMp4}
+
+// DefaultThumbnailVariant is the thumbnail variant served if no specific variant is requested.
+var DefaultThumbnailVariant = ThumbnailVariant{Size: DefaultThumbnailSize, Format: ImageTypeUnknown}
+
+// OriginalVariant is the variant of the original media kept in the derived media store as downloaded,
+// so the original does not need to be downloaded again for every request.
+var OriginalVariant = ThumbnailVariant{Size: 0, Format: ImageTypeUnknown}
+
+// ParseThumbnailVariant decodes thumbnail variant from the given size and format names.
+// Empty values fall back to the default variant.
+func ParseThumbnailVariant(size string, format string) (ThumbnailVariant, error) {
+	v := DefaultThumbnailVariant
+
+	if size != "" {
+		sz, err := strconv.Atoi(size)
+		if err != nil {
+			return v, fmt.Errorf("invalid thumbnail size %s; %s", size, err)
+		}
+		if !isThumbnailSize(sz) {
+			return v, fmt.Errorf("thumbnail size %d not supported", sz)
+		}
+		v.Size = sz
+	}
+
+	switch strings.ToLower(format) {
+	case "":
+	case "jpeg", "jpg":
+		v.Format = ImageTypeJpeg
+	case "png":
+		v.Format = ImageTypePng
+	case "webp":
+		v.Format = ImageTypeWebp
+	case "gif":
+		v.Format = ImageTypeGif
+	default:
+		return v, fmt.Errorf("thumbnail format %s not supported", format)
+	}
+	return v, nil
+}
+
+// isThumbnailSize checks if the given size is one of the supported thumbnail sizes.
+func isThumbnailSize(size int) bool {
+	for _, sz := range ThumbnailSizes {
+		if sz == size {
+			return true
+		}
+	}
+	return false
+}
+
+// ThumbnailKeyPrefix provides the prefix of the current derived media store keys;
+// the keys of other prefixes are left over from previous versions.
+func ThumbnailKeyPrefix() string {
+	return fmt.Sprintf("v%d/", thumbnailKeyVersion)
+}
+
+// Key provides the unique key of the variant of the given source URI in the derived media store.
+func (v ThumbnailVariant) Key(uri string) string {
+	hash := sha256.Sum256([]byte(uri))
+
+	ext := v.Format.Extension()
+	if ext == "" {
+		ext = ".auto"
+	}
+	return fmt.Sprintf("%s%s/%d%s", ThumbnailKeyPrefix(), hex.EncodeToString(hash[:]), v.Size, ext)
+}
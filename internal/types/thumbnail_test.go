@@ -0,0 +1,29 @@
+package types
+
+import (
+	"github.com/onsi/gomega"
+	"testing"
+)
+
+func TestParseThumbnailVariant(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	v, err := ParseThumbnailVariant("", "")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(v).To(gomega.Equal(DefaultThumbnailVariant))
+
+	v, err = ParseThumbnailVariant("64", "WebP")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(v).To(gomega.Equal(ThumbnailVariant{Size: 64, Format: ImageTypeWebp}))
+
+	_, err = ParseThumbnailVariant("65", "png")
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	_, err = ParseThumbnailVariant("256", "tiff")
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	uri := "ipfs://QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi"
+	g.Expect(v.Key(uri)).ToNot(gomega.Equal(DefaultThumbnailVariant.Key(uri)))
+	g.Expect(v.Key(uri)).To(gomega.HaveSuffix("/64.webp"))
+	g.Expect(v.Key(uri)).To(gomega.HavePrefix("v2/"))
+}
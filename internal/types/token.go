@@ -7,6 +7,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"math/big"
+	"strings"
 	"time"
 )
 
@@ -19,8 +20,19 @@ const (
 	// a new metadata update attempt after a successful update.
 	TokenSuccessMetadataUpdateDelay = 7 * 24 * time.Hour
 
+	// TokenDeferredMetadataUpdateDelay is the time delay of a metadata update
+	// which could not be attempted, because the metadata host is busy or backed off.
+	TokenDeferredMetadataUpdateDelay = time.Minute
+
 	// MetadataRefreshSetSize is the max size of metadata refresh set pulled at once.
 	MetadataRefreshSetSize = 50
+
+	// TokenSupplyCheckDelay is the minimal time delay between subsequent
+	// on-chain checks of the token supply and holders count.
+	TokenSupplyCheckDelay = 24 * time.Hour
+
+	// SupplyCheckSetSize is the max size of supply check set pulled at once.
+	SupplyCheckSetSize = 50
 )
 
 // Token represents item list-able in the marketplace.
@@ -33,6 +45,11 @@ type Token struct {
 	Description     string         `bson:"desc"`
 	ImageURI        string         `bson:"image"`
 	ImageType       ImageType      `bson:"image_type"`
+	AnimationURI    string         `bson:"animation"`
+	AnimationType   string         `bson:"animation_type"` // mimetype of the animation
+	AnimationMedia  MediaKind      `bson:"animation_kind"`
+	ExternalLink    string         `bson:"external_url"`
+	BgColor         string         `bson:"bg_color"`
 	OrdinalIndex    int64          `bson:"index"`
 	Created         Time           `bson:"created"`
 	CreatedBy       common.Address `bson:"created_by"`
@@ -53,9 +70,31 @@ type Token struct {
 	Price           int64          `bson:"price"`
 	Categories      []int32        `bson:"categories"`
 
+	// traits of the token from its metadata and the rarity calculated from them
+	Attributes  []TokenAttribute `bson:"attributes"`
+	RarityScore float64          `bson:"rarity"`
+	RarityRank  int32            `bson:"rarity_rank"`
+
+	// burned tokens are excluded from default token lists
+	Burned   *Time           `bson:"burned"`
+	BurnedBy *common.Address `bson:"burned_by"`
+
+	// supply and holders are updated from transfer events
+	// and periodically checked against the chain
+	Supply      hexutil.Big `bson:"supply"`
+	Holders     int64       `bson:"holders"`
+	SupplyCheck Time        `bson:"supply_check"`
+
+	// state of the pre-generated image thumbnail
+	ThumbStatus ThumbnailStatus `bson:"thumb_status"`
+
+	// root CIDs of IPFS content referenced by the metadata, the image and the animation URI
+	Cids []string `bson:"cids"`
+
 	// metadata refresh helpers
-	MetaUpdate   Time  `bson:"meta_update"`
-	MetaFailures int32 `bson:"meta_failures"`
+	MetaUpdate   Time            `bson:"meta_update"`
+	MetaFailures int32           `bson:"meta_failures"`
+	MetaError    *TokenMetaError `bson:"meta_error"`
 }
 
 // OrdinalIndex generates numeric ordinal index from block number and log record index.
@@ -73,6 +112,7 @@ func NewToken(con *common.Address, tokenId *big.Int, uri string, ts int64, block
 		Created:      Time(time.Unix(ts, 0)),
 		OrdinalIndex: OrdinalIndex(int64(block), int64(index)),
 		MetaUpdate:   Time(time.Now().Add(TokenDefaultMetadataUpdateDelay)),
+		SupplyCheck:  Time(time.Now().Add(TokenSupplyCheckDelay)),
 	}
 }
 
@@ -101,8 +141,37 @@ func (t *Token) ScheduleMetaUpdateOnFailure() {
 	t.MetaFailures++
 }
 
+// ScheduleMetaUpdateDeferred sets new metadata update time if the update could not be attempted now.
+// The update did not fail, so the failures counter and the backoff are not changed.
+func (t *Token) ScheduleMetaUpdateDeferred() {
+	t.MetaUpdate = Time(time.Now().Add(TokenDeferredMetadataUpdateDelay))
+}
+
 // ScheduleMetaUpdateOnSuccess sets new metadata update time successful metadata update.
 func (t *Token) ScheduleMetaUpdateOnSuccess() {
 	t.MetaUpdate = Time(time.Now().Add(TokenSuccessMetadataUpdateDelay))
 	t.MetaFailures = 0
+	t.MetaError = nil
+}
+
+// PreviewSource provides URI of the video the preview clip of the token is made from.
+// Empty string is returned if the token has no video.
+func (t *Token) PreviewSource() string {
+	if t.ImageURI != "" && t.ImageType == ImageTypeMp4 {
+		return t.ImageURI
+	}
+	if t.AnimationURI != "" && t.AnimationType == ImageTypeMp4.Mimetype() {
+		return t.AnimationURI
+	}
+	return ""
+}
+
+// HasOnChainMetadata checks if the token metadata are stored on chain in the token URI itself.
+func (t *Token) HasOnChainMetadata() bool {
+	return IsDataUri(t.Uri)
+}
+
+// IsDataUri checks if the URI carries the content itself (RFC 2397 data URI).
+func IsDataUri(uri string) bool {
+	return len(uri) >= 5 && strings.EqualFold(uri[:5], "data:")
 }
@@ -0,0 +1,21 @@
+package types
+
+// TokenAttribute represents a trait of an NFT token defined by its metadata.
+type TokenAttribute struct {
+	TraitType   string  `bson:"trait"`
+	Value       string  `bson:"value"`
+	DisplayType *string `bson:"display,omitempty"`
+}
+
+// TraitValueCount represents the number of tokens of a collection having the trait value.
+type TraitValueCount struct {
+	TraitType string `bson:"trait"`
+	Value     string `bson:"value"`
+	Count     int32  `bson:"count"`
+}
+
+// TraitFilter represents a filter of tokens having any of the values of the trait.
+type TraitFilter struct {
+	TraitType string
+	Values    []string
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math/big"
 )
 
 // NFTBurn represents an NFT burn.
@@ -15,15 +16,20 @@ type NFTBurn struct {
 	Owner    common.Address `bson:"owner"`
 	Qty      hexutil.Big    `bson:"qty"`
 	Burned   Time           `bson:"burned"`
+
+	// OrdinalIndex is the ordinal index of the burning event log record.
+	OrdinalIndex int64 `bson:"index"`
 }
 
 // ID generates unique identifier for the NFT burn record.
+// An ERC-1155 token may be burned by the same owner several times, the ordinal index makes the difference.
 // Collision approx for p(n)=1e-12: n = sqrt(2 x 2^96 x 2^-39) = 536.870.912 documents
 func (bu *NFTBurn) ID() primitive.ObjectID {
 	hash := sha256.New()
 	hash.Write(bu.Contract.Bytes())
 	hash.Write(bu.TokenId.ToInt().Bytes())
 	hash.Write(bu.Owner.Bytes())
+	hash.Write(big.NewInt(bu.OrdinalIndex).Bytes())
 
 	var id [12]byte
 	copy(id[:], hash.Sum(nil))
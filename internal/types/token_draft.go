@@ -0,0 +1,26 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// TokenDraft represents token data uploaded to IPFS by a user, which has not been minted yet.
+// The draft is identified by the canonical IPFS content path of the metadata ("/ipfs/{CID}"),
+// so the mint is matched regardless of the form of the URI used on chain.
+// Drafts never minted are removed and their content unpinned after the configured time.
+type TokenDraft struct {
+	Path         string         `bson:"_id"`
+	Uri          string         `bson:"uri"`
+	Owner        common.Address `bson:"owner"`
+	Name         string         `bson:"name"`
+	ImageURI     string         `bson:"image"`
+	AnimationURI string         `bson:"animation"`
+	Cids         []string       `bson:"cids"` // pinned content of the draft; metadata, image and rich media
+	Created      Time           `bson:"created"`
+
+	// the minted token, if the mint of the draft has been observed
+	Contract *common.Address `bson:"contract"`
+	TokenId  *hexutil.Big    `bson:"token"`
+	Minted   *Time           `bson:"minted"`
+}
@@ -13,4 +13,6 @@ type TokenFilter struct {
 	Collections *[]common.Address
 	Categories *[]int32
 	CreatedBy  *common.Address
+	IncludeBurned *bool
+	Traits     *[]TraitFilter
 }
@@ -0,0 +1,86 @@
+// Package types provides high level structures for the API server.
+package types
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Categories of NFT metadata update failures.
+const (
+	MetaErrorNotFound        = "NOT_FOUND"
+	MetaErrorHttpStatus      = "HTTP_STATUS"
+	MetaErrorTimeout         = "TIMEOUT"
+	MetaErrorHostUnavailable = "HOST_UNAVAILABLE"
+	MetaErrorNetwork         = "NETWORK"
+	MetaErrorForbidden       = "FORBIDDEN_DESTINATION"
+	MetaErrorTooLarge        = "TOO_LARGE"
+	MetaErrorUnknownScheme   = "UNKNOWN_SCHEME"
+	MetaErrorInvalidJson     = "INVALID_JSON"
+	MetaErrorEmptyMetadata   = "EMPTY_METADATA"
+	MetaErrorOther           = "OTHER"
+)
+
+// metaErrorMessageLength is the max length of the error message stored with the token.
+const metaErrorMessageLength = 512
+
+// TokenMetaError represents the last failure of the NFT metadata update.
+// It's kept with the token until the metadata are updated successfully.
+type TokenMetaError struct {
+	Category string `bson:"category"`
+	Message  string `bson:"msg"`
+	Url      string `bson:"url"`
+	Host     string `bson:"host"`
+	Time     Time   `bson:"time"`
+}
+
+// NewTokenMetaError creates the metadata failure record of the given category
+// for the error received when downloading the given URL.
+func NewTokenMetaError(category string, err error, uri string) *TokenMetaError {
+	msg := err.Error()
+	if len(msg) > metaErrorMessageLength {
+		msg = msg[:metaErrorMessageLength]
+	}
+	return &TokenMetaError{
+		Category: category,
+		Message:  msg,
+		Url:      uri,
+		Host:     UriHost(uri),
+		Time:     Time(time.Now().UTC()),
+	}
+}
+
+// UriHost provides the host the content of the URI is served by. Content addressed
+// URIs (IPFS, IPNS, Arweave) and on-chain data URIs are reported by their scheme.
+func UriHost(uri string) string {
+	switch {
+	case IsDataUri(uri):
+		return "data"
+	case strings.HasPrefix(uri, "/ipfs/"):
+		return "ipfs"
+	case strings.HasPrefix(uri, "/ipns/"):
+		return "ipns"
+	}
+
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return strings.ToLower(u.Hostname())
+	case "ipfs", "ipns", "ar":
+		return strings.ToLower(u.Scheme)
+	}
+	return ""
+}
+
+// MetadataFailureGroup represents the number of tokens failing on the metadata update
+// for the same reason on the same host.
+type MetadataFailureGroup struct {
+	Category    string `bson:"category"`
+	Host        string `bson:"host"`
+	Count       int32  `bson:"count"`
+	LastFailure Time   `bson:"last"`
+}
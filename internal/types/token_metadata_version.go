@@ -0,0 +1,69 @@
+// Package types provides high level structures for the API server.
+package types
+
+import (
+	"crypto/sha256"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// TokenMetadataVersion represents a version of the NFT token metadata document
+// identified by the hash of its content. A new version is recorded every time
+// a fetched document differs from all the previous ones of the token.
+type TokenMetadataVersion struct {
+	Contract     common.Address `bson:"contract"`
+	TokenId      hexutil.Big    `bson:"token"`
+	Hash         common.Hash    `bson:"hash"` // sha256 of the raw JSON document
+	Uri          string         `bson:"uri"`
+	Name         string         `bson:"name"`
+	Description  string         `bson:"desc"`
+	ImageURI     string         `bson:"image"`
+	AnimationURI string         `bson:"animation"`
+	Data         []byte         `bson:"data"` // raw JSON document
+	FirstSeen    Time           `bson:"first_seen"`
+	LastSeen     Time           `bson:"last_seen"`
+}
+
+// NewTokenMetadataVersion creates the metadata version of the given token from the raw JSON document.
+// The token is expected to be already updated from the document.
+func NewTokenMetadataVersion(tok *Token, data []byte) *TokenMetadataVersion {
+	now := Time(time.Now().UTC())
+	return &TokenMetadataVersion{
+		Contract:     tok.Contract,
+		TokenId:      tok.TokenId,
+		Hash:         sha256.Sum256(data),
+		Uri:          tok.Uri,
+		Name:         tok.Name,
+		Description:  tok.Description,
+		ImageURI:     tok.ImageURI,
+		AnimationURI: tok.AnimationURI,
+		Data:         data,
+		FirstSeen:    now,
+		LastSeen:     now,
+	}
+}
+
+// ID generates unique identifier of the metadata version of the token.
+func (mv *TokenMetadataVersion) ID() primitive.ObjectID {
+	hash := sha256.New()
+	hash.Write(mv.Contract.Bytes())
+	hash.Write(mv.TokenId.ToInt().Bytes())
+	hash.Write(mv.Hash.Bytes())
+
+	var id [12]byte
+	copy(id[:], hash.Sum(nil))
+	return id
+}
+
+// metadataChangeLogIndex is the log index used for ordinal indexes of metadata changes;
+// it's the last one available in a block.
+const metadataChangeLogIndex = 0xfff
+
+// MetadataChangeOrdinalIndex generates the ordinal index of metadata change activities of the given block.
+// Metadata changes do not come from log records, they share the last ordinal index of the block
+// and the activity sequence (see Activity.Seq) orders them after the log records.
+func MetadataChangeOrdinalIndex(blk uint64) int64 {
+	return OrdinalIndex(int64(blk), metadataChangeLogIndex)
+}
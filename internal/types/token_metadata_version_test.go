@@ -0,0 +1,25 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/onsi/gomega"
+	"math/big"
+	"testing"
+)
+
+func TestTokenMetadataVersion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	con := common.HexToAddress("0x61af4d29f672e27a097291f72fc571304bc93521")
+	tok := NewToken(&con, big.NewInt(1), "ipfs://QmTetVgMNVGj88s9NQuANyVmjMtZqhZDp8T21huiVGbfAi", 0, 1000, 3)
+
+	v1 := NewTokenMetadataVersion(tok, []byte(`{"name":"a"}`))
+	v2 := NewTokenMetadataVersion(tok, []byte(`{"name":"a"}`))
+	v3 := NewTokenMetadataVersion(tok, []byte(`{"name":"b"}`))
+	g.Expect(v1.ID()).To(gomega.Equal(v2.ID()))
+	g.Expect(v1.ID()).ToNot(gomega.Equal(v3.ID()))
+
+	// metadata changes are ordered after the log records of the block, the sequence orders them among each other
+	g.Expect(MetadataChangeOrdinalIndex(1000)).To(gomega.BeNumerically(">=", OrdinalIndex(1000, 0xfff)))
+	g.Expect(MetadataChangeOrdinalIndex(1000)).To(gomega.BeNumerically("<", OrdinalIndex(1001, 0)))
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"time"
+)
+
+// UploadSession represents a resumable upload of a large media file sent in chunks.
+// The Offset is the number of bytes received so far; the upload is complete if it reaches the Size.
+type UploadSession struct {
+	ID      string         `json:"id"`
+	Owner   common.Address `json:"owner"`
+	Size    int64          `json:"size"`
+	Offset  int64          `json:"offset"`
+	Created time.Time      `json:"created"`
+	Expires time.Time      `json:"expires"`
+}
+
+// IsComplete checks if all the bytes of the upload have been received.
+func (us *UploadSession) IsComplete() bool {
+	return us.Offset == us.Size
+}